@@ -7,8 +7,10 @@
 package permissionv1
 
 import (
+	v1 "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -22,6 +24,88 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// DecisionSource identifies who made a permission decision.
+type DecisionSource int32
+
+const (
+	DecisionSource_DECISION_SOURCE_UNSPECIFIED DecisionSource = 0
+	// A human answered the prompt through a front-end without a source of
+	// its own (e.g. the plain text prompt).
+	DecisionSource_DECISION_SOURCE_HUMAN DecisionSource = 1
+	// A policy rule matched.
+	DecisionSource_DECISION_SOURCE_POLICY DecisionSource = 2
+	// A remembered grant matched.
+	DecisionSource_DECISION_SOURCE_GRANT DecisionSource = 3
+	// The workspace path guard decided.
+	DecisionSource_DECISION_SOURCE_PATH_GUARD DecisionSource = 4
+	// Nobody answered before the request deadline; a default decision was applied.
+	DecisionSource_DECISION_SOURCE_TIMEOUT DecisionSource = 5
+	// The server could not decide (unreachable or failed); the client's
+	// fallback decision was applied.
+	DecisionSource_DECISION_SOURCE_FALLBACK DecisionSource = 6
+	// A human answered in the TUI.
+	DecisionSource_DECISION_SOURCE_TUI DecisionSource = 7
+	// A human answered in the web UI.
+	DecisionSource_DECISION_SOURCE_WEB DecisionSource = 8
+	// A human followed an approve/deny link sent to the webhook.
+	DecisionSource_DECISION_SOURCE_WEBHOOK DecisionSource = 9
+)
+
+// Enum value maps for DecisionSource.
+var (
+	DecisionSource_name = map[int32]string{
+		0: "DECISION_SOURCE_UNSPECIFIED",
+		1: "DECISION_SOURCE_HUMAN",
+		2: "DECISION_SOURCE_POLICY",
+		3: "DECISION_SOURCE_GRANT",
+		4: "DECISION_SOURCE_PATH_GUARD",
+		5: "DECISION_SOURCE_TIMEOUT",
+		6: "DECISION_SOURCE_FALLBACK",
+		7: "DECISION_SOURCE_TUI",
+		8: "DECISION_SOURCE_WEB",
+		9: "DECISION_SOURCE_WEBHOOK",
+	}
+	DecisionSource_value = map[string]int32{
+		"DECISION_SOURCE_UNSPECIFIED": 0,
+		"DECISION_SOURCE_HUMAN":       1,
+		"DECISION_SOURCE_POLICY":      2,
+		"DECISION_SOURCE_GRANT":       3,
+		"DECISION_SOURCE_PATH_GUARD":  4,
+		"DECISION_SOURCE_TIMEOUT":     5,
+		"DECISION_SOURCE_FALLBACK":    6,
+		"DECISION_SOURCE_TUI":         7,
+		"DECISION_SOURCE_WEB":         8,
+		"DECISION_SOURCE_WEBHOOK":     9,
+	}
+)
+
+func (x DecisionSource) Enum() *DecisionSource {
+	p := new(DecisionSource)
+	*p = x
+	return p
+}
+
+func (x DecisionSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DecisionSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_permission_v1_permission_proto_enumTypes[0].Descriptor()
+}
+
+func (DecisionSource) Type() protoreflect.EnumType {
+	return &file_permission_v1_permission_proto_enumTypes[0]
+}
+
+func (x DecisionSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DecisionSource.Descriptor instead.
+func (DecisionSource) EnumDescriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{0}
+}
+
 // PermissionDecision represents the possible permission decisions.
 type PermissionDecision int32
 
@@ -62,11 +146,11 @@ func (x PermissionDecision) String() string {
 }
 
 func (PermissionDecision) Descriptor() protoreflect.EnumDescriptor {
-	return file_permission_v1_permission_proto_enumTypes[0].Descriptor()
+	return file_permission_v1_permission_proto_enumTypes[1].Descriptor()
 }
 
 func (PermissionDecision) Type() protoreflect.EnumType {
-	return &file_permission_v1_permission_proto_enumTypes[0]
+	return &file_permission_v1_permission_proto_enumTypes[1]
 }
 
 func (x PermissionDecision) Number() protoreflect.EnumNumber {
@@ -75,7 +159,63 @@ func (x PermissionDecision) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use PermissionDecision.Descriptor instead.
 func (PermissionDecision) EnumDescriptor() ([]byte, []int) {
-	return file_permission_v1_permission_proto_rawDescGZIP(), []int{0}
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{1}
+}
+
+// GrantScope is the scope of a remembered allow decision.
+type GrantScope int32
+
+const (
+	GrantScope_GRANT_SCOPE_UNSPECIFIED GrantScope = 0
+	// Allow the tool for the rest of the session.
+	GrantScope_GRANT_SCOPE_SESSION GrantScope = 1
+	// Allow the exact same tool input in the project (cwd), across sessions.
+	GrantScope_GRANT_SCOPE_PROJECT_COMMAND GrantScope = 2
+	// Allow the tool in any session until the grant expires.
+	GrantScope_GRANT_SCOPE_TOOL GrantScope = 3
+)
+
+// Enum value maps for GrantScope.
+var (
+	GrantScope_name = map[int32]string{
+		0: "GRANT_SCOPE_UNSPECIFIED",
+		1: "GRANT_SCOPE_SESSION",
+		2: "GRANT_SCOPE_PROJECT_COMMAND",
+		3: "GRANT_SCOPE_TOOL",
+	}
+	GrantScope_value = map[string]int32{
+		"GRANT_SCOPE_UNSPECIFIED":     0,
+		"GRANT_SCOPE_SESSION":         1,
+		"GRANT_SCOPE_PROJECT_COMMAND": 2,
+		"GRANT_SCOPE_TOOL":            3,
+	}
+)
+
+func (x GrantScope) Enum() *GrantScope {
+	p := new(GrantScope)
+	*p = x
+	return p
+}
+
+func (x GrantScope) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GrantScope) Descriptor() protoreflect.EnumDescriptor {
+	return file_permission_v1_permission_proto_enumTypes[2].Descriptor()
+}
+
+func (GrantScope) Type() protoreflect.EnumType {
+	return &file_permission_v1_permission_proto_enumTypes[2]
+}
+
+func (x GrantScope) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GrantScope.Descriptor instead.
+func (GrantScope) EnumDescriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{2}
 }
 
 // PermissionRequest contains the hook input from Claude Code.
@@ -95,8 +235,17 @@ type PermissionRequest struct {
 	Cwd string `protobuf:"bytes,6,opt,name=cwd,proto3" json:"cwd,omitempty"`
 	// Path to conversation transcript.
 	TranscriptPath string `protobuf:"bytes,7,opt,name=transcript_path,json=transcriptPath,proto3" json:"transcript_path,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// The complete hook input as received from Claude Code (JSON).
+	HookInputJson string `protobuf:"bytes,8,opt,name=hook_input_json,json=hookInputJson,proto3" json:"hook_input_json,omitempty"`
+	// The tmux pane the hook client runs in, if any.
+	Tmux *TmuxPane `protobuf:"bytes,9,opt,name=tmux,proto3" json:"tmux,omitempty"`
+	// The permission mode of the session (e.g. "default", "plan").
+	PermissionMode string `protobuf:"bytes,10,opt,name=permission_mode,json=permissionMode,proto3" json:"permission_mode,omitempty"`
+	// The typed tool input, if the request arrived through permission.v2.
+	// tool_input_json is always set as well.
+	ToolInput     *v1.ToolInput `protobuf:"bytes,11,opt,name=tool_input,json=toolInput,proto3" json:"tool_input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *PermissionRequest) Reset() {
@@ -178,6 +327,230 @@ func (x *PermissionRequest) GetTranscriptPath() string {
 	return ""
 }
 
+func (x *PermissionRequest) GetHookInputJson() string {
+	if x != nil {
+		return x.HookInputJson
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetTmux() *TmuxPane {
+	if x != nil {
+		return x.Tmux
+	}
+	return nil
+}
+
+func (x *PermissionRequest) GetPermissionMode() string {
+	if x != nil {
+		return x.PermissionMode
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetToolInput() *v1.ToolInput {
+	if x != nil {
+		return x.ToolInput
+	}
+	return nil
+}
+
+// TmuxPane locates the tmux pane of the Claude Code instance that sent a
+// request, so the server can map it back to its terminal.
+type TmuxPane struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The tmux server socket path (from $TMUX).
+	SocketPath string `protobuf:"bytes,1,opt,name=socket_path,json=socketPath,proto3" json:"socket_path,omitempty"`
+	// The pane ID, e.g. "%3" (from $TMUX_PANE).
+	PaneId string `protobuf:"bytes,2,opt,name=pane_id,json=paneId,proto3" json:"pane_id,omitempty"`
+	// The swarm member name if the pane was created by 'crabswarm spawn'.
+	Member        string `protobuf:"bytes,3,opt,name=member,proto3" json:"member,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TmuxPane) Reset() {
+	*x = TmuxPane{}
+	mi := &file_permission_v1_permission_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TmuxPane) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TmuxPane) ProtoMessage() {}
+
+func (x *TmuxPane) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TmuxPane.ProtoReflect.Descriptor instead.
+func (*TmuxPane) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TmuxPane) GetSocketPath() string {
+	if x != nil {
+		return x.SocketPath
+	}
+	return ""
+}
+
+func (x *TmuxPane) GetPaneId() string {
+	if x != nil {
+		return x.PaneId
+	}
+	return ""
+}
+
+func (x *TmuxPane) GetMember() string {
+	if x != nil {
+		return x.Member
+	}
+	return ""
+}
+
+// HookRequest carries a complete hook input from Claude Code.
+type HookRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the hook being invoked (e.g., "PostToolUse", "Stop").
+	HookEventName string `protobuf:"bytes,1,opt,name=hook_event_name,json=hookEventName,proto3" json:"hook_event_name,omitempty"`
+	// Session ID from Claude Code.
+	SessionId string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Current working directory.
+	Cwd string `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	// The complete hook input as received from Claude Code (JSON). Kept as
+	// JSON so that every field reaches the server, including ones not
+	// modeled yet.
+	HookInputJson string `protobuf:"bytes,4,opt,name=hook_input_json,json=hookInputJson,proto3" json:"hook_input_json,omitempty"`
+	// The tmux pane the hook client runs in, if any.
+	Tmux          *TmuxPane `protobuf:"bytes,5,opt,name=tmux,proto3" json:"tmux,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HookRequest) Reset() {
+	*x = HookRequest{}
+	mi := &file_permission_v1_permission_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HookRequest) ProtoMessage() {}
+
+func (x *HookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HookRequest.ProtoReflect.Descriptor instead.
+func (*HookRequest) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HookRequest) GetHookEventName() string {
+	if x != nil {
+		return x.HookEventName
+	}
+	return ""
+}
+
+func (x *HookRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *HookRequest) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *HookRequest) GetHookInputJson() string {
+	if x != nil {
+		return x.HookInputJson
+	}
+	return ""
+}
+
+func (x *HookRequest) GetTmux() *TmuxPane {
+	if x != nil {
+		return x.Tmux
+	}
+	return nil
+}
+
+// HookResponse contains the output of a non-permission hook event.
+type HookResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The hook output written to Claude Code. Its decision and reason block
+	// PostToolUse, UserPromptSubmit, Stop and SubagentStop.
+	Output        *v1.SyncHookJSONOutput `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HookResponse) Reset() {
+	*x = HookResponse{}
+	mi := &file_permission_v1_permission_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HookResponse) ProtoMessage() {}
+
+func (x *HookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HookResponse.ProtoReflect.Descriptor instead.
+func (*HookResponse) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HookResponse) GetOutput() *v1.SyncHookJSONOutput {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
 // PermissionResponse contains the decision from the interactive server.
 type PermissionResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -191,13 +564,15 @@ type PermissionResponse struct {
 	SystemMessage string `protobuf:"bytes,4,opt,name=system_message,json=systemMessage,proto3" json:"system_message,omitempty"`
 	// The hook-specific output.
 	HookSpecificOutput *HookSpecificOutput `protobuf:"bytes,5,opt,name=hook_specific_output,json=hookSpecificOutput,proto3" json:"hook_specific_output,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	// Who made the decision.
+	DecisionSource DecisionSource `protobuf:"varint,6,opt,name=decision_source,json=decisionSource,proto3,enum=permission.v1.DecisionSource" json:"decision_source,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *PermissionResponse) Reset() {
 	*x = PermissionResponse{}
-	mi := &file_permission_v1_permission_proto_msgTypes[1]
+	mi := &file_permission_v1_permission_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -209,7 +584,7 @@ func (x *PermissionResponse) String() string {
 func (*PermissionResponse) ProtoMessage() {}
 
 func (x *PermissionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_permission_v1_permission_proto_msgTypes[1]
+	mi := &file_permission_v1_permission_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -222,7 +597,7 @@ func (x *PermissionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PermissionResponse.ProtoReflect.Descriptor instead.
 func (*PermissionResponse) Descriptor() ([]byte, []int) {
-	return file_permission_v1_permission_proto_rawDescGZIP(), []int{1}
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *PermissionResponse) GetShouldContinue() bool {
@@ -260,6 +635,13 @@ func (x *PermissionResponse) GetHookSpecificOutput() *HookSpecificOutput {
 	return nil
 }
 
+func (x *PermissionResponse) GetDecisionSource() DecisionSource {
+	if x != nil {
+		return x.DecisionSource
+	}
+	return DecisionSource_DECISION_SOURCE_UNSPECIFIED
+}
+
 // HookSpecificOutput contains hook-specific output data.
 type HookSpecificOutput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -279,7 +661,7 @@ type HookSpecificOutput struct {
 
 func (x *HookSpecificOutput) Reset() {
 	*x = HookSpecificOutput{}
-	mi := &file_permission_v1_permission_proto_msgTypes[2]
+	mi := &file_permission_v1_permission_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -291,7 +673,7 @@ func (x *HookSpecificOutput) String() string {
 func (*HookSpecificOutput) ProtoMessage() {}
 
 func (x *HookSpecificOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_permission_v1_permission_proto_msgTypes[2]
+	mi := &file_permission_v1_permission_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -304,7 +686,7 @@ func (x *HookSpecificOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HookSpecificOutput.ProtoReflect.Descriptor instead.
 func (*HookSpecificOutput) Descriptor() ([]byte, []int) {
-	return file_permission_v1_permission_proto_rawDescGZIP(), []int{2}
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *HookSpecificOutput) GetHookEventName() string {
@@ -348,14 +730,26 @@ type AuditEvent struct {
 	// The permission request data from the hook invocation.
 	Request *PermissionRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
 	// The time the hook was invoked.
-	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// The response returned to the hook. Unset if the request failed.
+	Response *PermissionResponse `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	// The round-trip time of the permission request.
+	Latency *durationpb.Duration `protobuf:"bytes,4,opt,name=latency,proto3" json:"latency,omitempty"`
+	// The error of the permission request, if it failed.
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// Credentials of the client process, filled in by the server for
+	// connections over a Unix domain socket.
+	Peer *PeerCredentials `protobuf:"bytes,6,opt,name=peer,proto3" json:"peer,omitempty"`
+	// The hook output returned for a non-permission event. Unset if the
+	// request failed.
+	HookOutput    *v1.SyncHookJSONOutput `protobuf:"bytes,7,opt,name=hook_output,json=hookOutput,proto3" json:"hook_output,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AuditEvent) Reset() {
 	*x = AuditEvent{}
-	mi := &file_permission_v1_permission_proto_msgTypes[3]
+	mi := &file_permission_v1_permission_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -367,7 +761,7 @@ func (x *AuditEvent) String() string {
 func (*AuditEvent) ProtoMessage() {}
 
 func (x *AuditEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_permission_v1_permission_proto_msgTypes[3]
+	mi := &file_permission_v1_permission_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -380,7 +774,7 @@ func (x *AuditEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
 func (*AuditEvent) Descriptor() ([]byte, []int) {
-	return file_permission_v1_permission_proto_rawDescGZIP(), []int{3}
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *AuditEvent) GetRequest() *PermissionRequest {
@@ -397,24 +791,120 @@ func (x *AuditEvent) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
-// AuditResponse is returned after processing all audit events in the stream.
-type AuditResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// The number of events received in the stream.
-	EventsReceived int32 `protobuf:"varint,1,opt,name=events_received,json=eventsReceived,proto3" json:"events_received,omitempty"`
-	// Whether all events were processed successfully.
-	Success bool `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	// Optional message with details about the processing result.
-	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *AuditResponse) Reset() {
-	*x = AuditResponse{}
-	mi := &file_permission_v1_permission_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *AuditEvent) GetResponse() *PermissionResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetLatency() *durationpb.Duration {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetPeer() *PeerCredentials {
+	if x != nil {
+		return x.Peer
+	}
+	return nil
+}
+
+func (x *AuditEvent) GetHookOutput() *v1.SyncHookJSONOutput {
+	if x != nil {
+		return x.HookOutput
+	}
+	return nil
+}
+
+// PeerCredentials identifies the process on the other end of a Unix socket (SO_PEERCRED).
+type PeerCredentials struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pid           int32                  `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Uid           uint32                 `protobuf:"varint,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	Gid           uint32                 `protobuf:"varint,3,opt,name=gid,proto3" json:"gid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerCredentials) Reset() {
+	*x = PeerCredentials{}
+	mi := &file_permission_v1_permission_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerCredentials) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerCredentials) ProtoMessage() {}
+
+func (x *PeerCredentials) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerCredentials.ProtoReflect.Descriptor instead.
+func (*PeerCredentials) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PeerCredentials) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *PeerCredentials) GetUid() uint32 {
+	if x != nil {
+		return x.Uid
+	}
+	return 0
+}
+
+func (x *PeerCredentials) GetGid() uint32 {
+	if x != nil {
+		return x.Gid
+	}
+	return 0
+}
+
+// AuditResponse is returned after processing all audit events in the stream.
+type AuditResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The number of events received in the stream.
+	EventsReceived int32 `protobuf:"varint,1,opt,name=events_received,json=eventsReceived,proto3" json:"events_received,omitempty"`
+	// Whether all events were processed successfully.
+	Success bool `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	// Optional message with details about the processing result.
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditResponse) Reset() {
+	*x = AuditResponse{}
+	mi := &file_permission_v1_permission_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *AuditResponse) String() string {
@@ -424,7 +914,7 @@ func (x *AuditResponse) String() string {
 func (*AuditResponse) ProtoMessage() {}
 
 func (x *AuditResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_permission_v1_permission_proto_msgTypes[4]
+	mi := &file_permission_v1_permission_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -437,7 +927,7 @@ func (x *AuditResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuditResponse.ProtoReflect.Descriptor instead.
 func (*AuditResponse) Descriptor() ([]byte, []int) {
-	return file_permission_v1_permission_proto_rawDescGZIP(), []int{4}
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *AuditResponse) GetEventsReceived() int32 {
@@ -461,11 +951,431 @@ func (x *AuditResponse) GetMessage() string {
 	return ""
 }
 
+// Grant is a remembered allow decision consulted before prompting.
+type Grant struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique grant ID.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The scope of the grant.
+	Scope GrantScope `protobuf:"varint,2,opt,name=scope,proto3,enum=permission.v1.GrantScope" json:"scope,omitempty"`
+	// The tool the grant applies to.
+	ToolName string `protobuf:"bytes,3,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// Session ID (GRANT_SCOPE_SESSION only).
+	SessionId string `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Project directory (GRANT_SCOPE_PROJECT_COMMAND only).
+	Cwd string `protobuf:"bytes,5,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	// Exact tool input: the command for Bash, otherwise the tool input JSON
+	// (GRANT_SCOPE_PROJECT_COMMAND only).
+	Input string `protobuf:"bytes,6,opt,name=input,proto3" json:"input,omitempty"`
+	// The time the grant was created.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// The time the grant expires. Unset if it never expires.
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Grant) Reset() {
+	*x = Grant{}
+	mi := &file_permission_v1_permission_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Grant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grant) ProtoMessage() {}
+
+func (x *Grant) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grant.ProtoReflect.Descriptor instead.
+func (*Grant) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Grant) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Grant) GetScope() GrantScope {
+	if x != nil {
+		return x.Scope
+	}
+	return GrantScope_GRANT_SCOPE_UNSPECIFIED
+}
+
+func (x *Grant) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *Grant) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Grant) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *Grant) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *Grant) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Grant) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+// ListGrantsRequest is the request for ListGrants.
+type ListGrantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGrantsRequest) Reset() {
+	*x = ListGrantsRequest{}
+	mi := &file_permission_v1_permission_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGrantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGrantsRequest) ProtoMessage() {}
+
+func (x *ListGrantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGrantsRequest.ProtoReflect.Descriptor instead.
+func (*ListGrantsRequest) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{10}
+}
+
+// ListGrantsResponse contains the active grants.
+type ListGrantsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Grants        []*Grant               `protobuf:"bytes,1,rep,name=grants,proto3" json:"grants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGrantsResponse) Reset() {
+	*x = ListGrantsResponse{}
+	mi := &file_permission_v1_permission_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGrantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGrantsResponse) ProtoMessage() {}
+
+func (x *ListGrantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGrantsResponse.ProtoReflect.Descriptor instead.
+func (*ListGrantsResponse) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListGrantsResponse) GetGrants() []*Grant {
+	if x != nil {
+		return x.Grants
+	}
+	return nil
+}
+
+// RevokeGrantRequest is the request for RevokeGrant.
+type RevokeGrantRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the grant to revoke.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeGrantRequest) Reset() {
+	*x = RevokeGrantRequest{}
+	mi := &file_permission_v1_permission_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeGrantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeGrantRequest) ProtoMessage() {}
+
+func (x *RevokeGrantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeGrantRequest.ProtoReflect.Descriptor instead.
+func (*RevokeGrantRequest) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RevokeGrantRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// RevokeGrantResponse is the response for RevokeGrant.
+type RevokeGrantResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether a grant with the ID existed and was removed.
+	Revoked       bool `protobuf:"varint,1,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeGrantResponse) Reset() {
+	*x = RevokeGrantResponse{}
+	mi := &file_permission_v1_permission_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeGrantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeGrantResponse) ProtoMessage() {}
+
+func (x *RevokeGrantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeGrantResponse.ProtoReflect.Descriptor instead.
+func (*RevokeGrantResponse) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RevokeGrantResponse) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+// QueryHistoryRequest filters recorded audit events. Empty fields match everything.
+type QueryHistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only events of this session.
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Only events of this tool.
+	ToolName string `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// Only events at or after this time.
+	Since *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	// Only events before this time.
+	Until *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
+	// Only events with this decision.
+	Decision PermissionDecision `protobuf:"varint,5,opt,name=decision,proto3,enum=permission.v1.PermissionDecision" json:"decision,omitempty"`
+	// Maximum number of (most recent) events to return. 0 means no limit.
+	Limit         int32 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryHistoryRequest) Reset() {
+	*x = QueryHistoryRequest{}
+	mi := &file_permission_v1_permission_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryHistoryRequest) ProtoMessage() {}
+
+func (x *QueryHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryHistoryRequest.ProtoReflect.Descriptor instead.
+func (*QueryHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *QueryHistoryRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *QueryHistoryRequest) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *QueryHistoryRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *QueryHistoryRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *QueryHistoryRequest) GetDecision() PermissionDecision {
+	if x != nil {
+		return x.Decision
+	}
+	return PermissionDecision_PERMISSION_DECISION_UNSPECIFIED
+}
+
+func (x *QueryHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// QueryHistoryResponse contains the matching events in chronological order.
+type QueryHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*AuditEvent          `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryHistoryResponse) Reset() {
+	*x = QueryHistoryResponse{}
+	mi := &file_permission_v1_permission_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryHistoryResponse) ProtoMessage() {}
+
+func (x *QueryHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v1_permission_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryHistoryResponse.ProtoReflect.Descriptor instead.
+func (*QueryHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_permission_v1_permission_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *QueryHistoryResponse) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 var File_permission_v1_permission_proto protoreflect.FileDescriptor
 
 const file_permission_v1_permission_proto_rawDesc = "" +
 	"\n" +
-	"\x1epermission/v1/permission.proto\x12\rpermission.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf9\x01\n" +
+	"\x1epermission/v1/permission.proto\x12\rpermission.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x17sdk_types/v1/hook.proto\x1a\x1dsdk_types/v1/tool_input.proto\"\xaf\x03\n" +
 	"\x11PermissionRequest\x12&\n" +
 	"\x0fhook_event_name\x18\x01 \x01(\tR\rhookEventName\x12\x1b\n" +
 	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12&\n" +
@@ -475,36 +1385,119 @@ const file_permission_v1_permission_proto_rawDesc = "" +
 	"\n" +
 	"message_id\x18\x05 \x01(\tR\tmessageId\x12\x10\n" +
 	"\x03cwd\x18\x06 \x01(\tR\x03cwd\x12'\n" +
-	"\x0ftranscript_path\x18\a \x01(\tR\x0etranscriptPath\"\x83\x02\n" +
+	"\x0ftranscript_path\x18\a \x01(\tR\x0etranscriptPath\x12&\n" +
+	"\x0fhook_input_json\x18\b \x01(\tR\rhookInputJson\x12+\n" +
+	"\x04tmux\x18\t \x01(\v2\x17.permission.v1.TmuxPaneR\x04tmux\x12'\n" +
+	"\x0fpermission_mode\x18\n" +
+	" \x01(\tR\x0epermissionMode\x126\n" +
+	"\n" +
+	"tool_input\x18\v \x01(\v2\x17.sdk_types.v1.ToolInputR\ttoolInput\"\\\n" +
+	"\bTmuxPane\x12\x1f\n" +
+	"\vsocket_path\x18\x01 \x01(\tR\n" +
+	"socketPath\x12\x17\n" +
+	"\apane_id\x18\x02 \x01(\tR\x06paneId\x12\x16\n" +
+	"\x06member\x18\x03 \x01(\tR\x06member\"\xbb\x01\n" +
+	"\vHookRequest\x12&\n" +
+	"\x0fhook_event_name\x18\x01 \x01(\tR\rhookEventName\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x10\n" +
+	"\x03cwd\x18\x03 \x01(\tR\x03cwd\x12&\n" +
+	"\x0fhook_input_json\x18\x04 \x01(\tR\rhookInputJson\x12+\n" +
+	"\x04tmux\x18\x05 \x01(\v2\x17.permission.v1.TmuxPaneR\x04tmux\"H\n" +
+	"\fHookResponse\x128\n" +
+	"\x06output\x18\x01 \x01(\v2 .sdk_types.v1.SyncHookJSONOutputR\x06output\"\xcb\x02\n" +
 	"\x12PermissionResponse\x12'\n" +
 	"\x0fshould_continue\x18\x01 \x01(\bR\x0eshouldContinue\x12\x1f\n" +
 	"\vstop_reason\x18\x02 \x01(\tR\n" +
 	"stopReason\x12'\n" +
 	"\x0fsuppress_output\x18\x03 \x01(\bR\x0esuppressOutput\x12%\n" +
 	"\x0esystem_message\x18\x04 \x01(\tR\rsystemMessage\x12S\n" +
-	"\x14hook_specific_output\x18\x05 \x01(\v2!.permission.v1.HookSpecificOutputR\x12hookSpecificOutput\"\xab\x02\n" +
+	"\x14hook_specific_output\x18\x05 \x01(\v2!.permission.v1.HookSpecificOutputR\x12hookSpecificOutput\x12F\n" +
+	"\x0fdecision_source\x18\x06 \x01(\x0e2\x1d.permission.v1.DecisionSourceR\x0edecisionSource\"\xab\x02\n" +
 	"\x12HookSpecificOutput\x12&\n" +
 	"\x0fhook_event_name\x18\x01 \x01(\tR\rhookEventName\x12R\n" +
 	"\x13permission_decision\x18\x02 \x01(\x0e2!.permission.v1.PermissionDecisionR\x12permissionDecision\x12<\n" +
 	"\x1apermission_decision_reason\x18\x03 \x01(\tR\x18permissionDecisionReason\x12,\n" +
 	"\x12updated_input_json\x18\x04 \x01(\tR\x10updatedInputJson\x12-\n" +
-	"\x12additional_context\x18\x05 \x01(\tR\x11additionalContext\"\x82\x01\n" +
+	"\x12additional_context\x18\x05 \x01(\tR\x11additionalContext\"\x83\x03\n" +
 	"\n" +
 	"AuditEvent\x12:\n" +
 	"\arequest\x18\x01 \x01(\v2 .permission.v1.PermissionRequestR\arequest\x128\n" +
-	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"l\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12=\n" +
+	"\bresponse\x18\x03 \x01(\v2!.permission.v1.PermissionResponseR\bresponse\x123\n" +
+	"\alatency\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\alatency\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x122\n" +
+	"\x04peer\x18\x06 \x01(\v2\x1e.permission.v1.PeerCredentialsR\x04peer\x12A\n" +
+	"\vhook_output\x18\a \x01(\v2 .sdk_types.v1.SyncHookJSONOutputR\n" +
+	"hookOutput\"G\n" +
+	"\x0fPeerCredentials\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x05R\x03pid\x12\x10\n" +
+	"\x03uid\x18\x02 \x01(\rR\x03uid\x12\x10\n" +
+	"\x03gid\x18\x03 \x01(\rR\x03gid\"l\n" +
 	"\rAuditResponse\x12'\n" +
 	"\x0fevents_received\x18\x01 \x01(\x05R\x0eeventsReceived\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage*\x93\x01\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"\xa2\x02\n" +
+	"\x05Grant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\x05scope\x18\x02 \x01(\x0e2\x19.permission.v1.GrantScopeR\x05scope\x12\x1b\n" +
+	"\ttool_name\x18\x03 \x01(\tR\btoolName\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\x12\x10\n" +
+	"\x03cwd\x18\x05 \x01(\tR\x03cwd\x12\x14\n" +
+	"\x05input\x18\x06 \x01(\tR\x05input\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x13\n" +
+	"\x11ListGrantsRequest\"B\n" +
+	"\x12ListGrantsResponse\x12,\n" +
+	"\x06grants\x18\x01 \x03(\v2\x14.permission.v1.GrantR\x06grants\"$\n" +
+	"\x12RevokeGrantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	"\x13RevokeGrantResponse\x12\x18\n" +
+	"\arevoked\x18\x01 \x01(\bR\arevoked\"\x8a\x02\n" +
+	"\x13QueryHistoryRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12=\n" +
+	"\bdecision\x18\x05 \x01(\x0e2!.permission.v1.PermissionDecisionR\bdecision\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"I\n" +
+	"\x14QueryHistoryResponse\x121\n" +
+	"\x06events\x18\x01 \x03(\v2\x19.permission.v1.AuditEventR\x06events*\xad\x02\n" +
+	"\x0eDecisionSource\x12\x1f\n" +
+	"\x1bDECISION_SOURCE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15DECISION_SOURCE_HUMAN\x10\x01\x12\x1a\n" +
+	"\x16DECISION_SOURCE_POLICY\x10\x02\x12\x19\n" +
+	"\x15DECISION_SOURCE_GRANT\x10\x03\x12\x1e\n" +
+	"\x1aDECISION_SOURCE_PATH_GUARD\x10\x04\x12\x1b\n" +
+	"\x17DECISION_SOURCE_TIMEOUT\x10\x05\x12\x1c\n" +
+	"\x18DECISION_SOURCE_FALLBACK\x10\x06\x12\x17\n" +
+	"\x13DECISION_SOURCE_TUI\x10\a\x12\x17\n" +
+	"\x13DECISION_SOURCE_WEB\x10\b\x12\x1b\n" +
+	"\x17DECISION_SOURCE_WEBHOOK\x10\t*\x93\x01\n" +
 	"\x12PermissionDecision\x12#\n" +
 	"\x1fPERMISSION_DECISION_UNSPECIFIED\x10\x00\x12\x1d\n" +
 	"\x19PERMISSION_DECISION_ALLOW\x10\x01\x12\x1c\n" +
 	"\x18PERMISSION_DECISION_DENY\x10\x02\x12\x1b\n" +
-	"\x17PERMISSION_DECISION_ASK\x10\x032\xb1\x01\n" +
+	"\x17PERMISSION_DECISION_ASK\x10\x03*y\n" +
+	"\n" +
+	"GrantScope\x12\x1b\n" +
+	"\x17GRANT_SCOPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13GRANT_SCOPE_SESSION\x10\x01\x12\x1f\n" +
+	"\x1bGRANT_SCOPE_PROJECT_COMMAND\x10\x02\x12\x14\n" +
+	"\x10GRANT_SCOPE_TOOL\x10\x032\xfa\x03\n" +
 	"\x11PermissionService\x12X\n" +
 	"\x11RequestPermission\x12 .permission.v1.PermissionRequest\x1a!.permission.v1.PermissionResponse\x12B\n" +
-	"\x05Audit\x12\x19.permission.v1.AuditEvent\x1a\x1c.permission.v1.AuditResponse(\x01B\xc1\x01\n" +
+	"\x05Audit\x12\x19.permission.v1.AuditEvent\x1a\x1c.permission.v1.AuditResponse(\x01\x12Q\n" +
+	"\n" +
+	"ListGrants\x12 .permission.v1.ListGrantsRequest\x1a!.permission.v1.ListGrantsResponse\x12T\n" +
+	"\vRevokeGrant\x12!.permission.v1.RevokeGrantRequest\x1a\".permission.v1.RevokeGrantResponse\x12W\n" +
+	"\fQueryHistory\x12\".permission.v1.QueryHistoryRequest\x1a#.permission.v1.QueryHistoryResponse\x12E\n" +
+	"\n" +
+	"HandleHook\x12\x1a.permission.v1.HookRequest\x1a\x1b.permission.v1.HookResponseB\xc1\x01\n" +
 	"\x11com.permission.v1B\x0fPermissionProtoP\x01ZFgithub.com/ngicks/crabswarm/hook/api/gen/go/permission/v1;permissionv1\xa2\x02\x03PXX\xaa\x02\rPermission.V1\xca\x02\rPermission\\V1\xe2\x02\x19Permission\\V1\\GPBMetadata\xea\x02\x0ePermission::V1b\x06proto3"
 
 var (
@@ -519,31 +1512,72 @@ func file_permission_v1_permission_proto_rawDescGZIP() []byte {
 	return file_permission_v1_permission_proto_rawDescData
 }
 
-var file_permission_v1_permission_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_permission_v1_permission_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_permission_v1_permission_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_permission_v1_permission_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_permission_v1_permission_proto_goTypes = []any{
-	(PermissionDecision)(0),       // 0: permission.v1.PermissionDecision
-	(*PermissionRequest)(nil),     // 1: permission.v1.PermissionRequest
-	(*PermissionResponse)(nil),    // 2: permission.v1.PermissionResponse
-	(*HookSpecificOutput)(nil),    // 3: permission.v1.HookSpecificOutput
-	(*AuditEvent)(nil),            // 4: permission.v1.AuditEvent
-	(*AuditResponse)(nil),         // 5: permission.v1.AuditResponse
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(DecisionSource)(0),           // 0: permission.v1.DecisionSource
+	(PermissionDecision)(0),       // 1: permission.v1.PermissionDecision
+	(GrantScope)(0),               // 2: permission.v1.GrantScope
+	(*PermissionRequest)(nil),     // 3: permission.v1.PermissionRequest
+	(*TmuxPane)(nil),              // 4: permission.v1.TmuxPane
+	(*HookRequest)(nil),           // 5: permission.v1.HookRequest
+	(*HookResponse)(nil),          // 6: permission.v1.HookResponse
+	(*PermissionResponse)(nil),    // 7: permission.v1.PermissionResponse
+	(*HookSpecificOutput)(nil),    // 8: permission.v1.HookSpecificOutput
+	(*AuditEvent)(nil),            // 9: permission.v1.AuditEvent
+	(*PeerCredentials)(nil),       // 10: permission.v1.PeerCredentials
+	(*AuditResponse)(nil),         // 11: permission.v1.AuditResponse
+	(*Grant)(nil),                 // 12: permission.v1.Grant
+	(*ListGrantsRequest)(nil),     // 13: permission.v1.ListGrantsRequest
+	(*ListGrantsResponse)(nil),    // 14: permission.v1.ListGrantsResponse
+	(*RevokeGrantRequest)(nil),    // 15: permission.v1.RevokeGrantRequest
+	(*RevokeGrantResponse)(nil),   // 16: permission.v1.RevokeGrantResponse
+	(*QueryHistoryRequest)(nil),   // 17: permission.v1.QueryHistoryRequest
+	(*QueryHistoryResponse)(nil),  // 18: permission.v1.QueryHistoryResponse
+	(*v1.ToolInput)(nil),          // 19: sdk_types.v1.ToolInput
+	(*v1.SyncHookJSONOutput)(nil), // 20: sdk_types.v1.SyncHookJSONOutput
+	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 22: google.protobuf.Duration
 }
 var file_permission_v1_permission_proto_depIdxs = []int32{
-	3, // 0: permission.v1.PermissionResponse.hook_specific_output:type_name -> permission.v1.HookSpecificOutput
-	0, // 1: permission.v1.HookSpecificOutput.permission_decision:type_name -> permission.v1.PermissionDecision
-	1, // 2: permission.v1.AuditEvent.request:type_name -> permission.v1.PermissionRequest
-	6, // 3: permission.v1.AuditEvent.timestamp:type_name -> google.protobuf.Timestamp
-	1, // 4: permission.v1.PermissionService.RequestPermission:input_type -> permission.v1.PermissionRequest
-	4, // 5: permission.v1.PermissionService.Audit:input_type -> permission.v1.AuditEvent
-	2, // 6: permission.v1.PermissionService.RequestPermission:output_type -> permission.v1.PermissionResponse
-	5, // 7: permission.v1.PermissionService.Audit:output_type -> permission.v1.AuditResponse
-	6, // [6:8] is the sub-list for method output_type
-	4, // [4:6] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	4,  // 0: permission.v1.PermissionRequest.tmux:type_name -> permission.v1.TmuxPane
+	19, // 1: permission.v1.PermissionRequest.tool_input:type_name -> sdk_types.v1.ToolInput
+	4,  // 2: permission.v1.HookRequest.tmux:type_name -> permission.v1.TmuxPane
+	20, // 3: permission.v1.HookResponse.output:type_name -> sdk_types.v1.SyncHookJSONOutput
+	8,  // 4: permission.v1.PermissionResponse.hook_specific_output:type_name -> permission.v1.HookSpecificOutput
+	0,  // 5: permission.v1.PermissionResponse.decision_source:type_name -> permission.v1.DecisionSource
+	1,  // 6: permission.v1.HookSpecificOutput.permission_decision:type_name -> permission.v1.PermissionDecision
+	3,  // 7: permission.v1.AuditEvent.request:type_name -> permission.v1.PermissionRequest
+	21, // 8: permission.v1.AuditEvent.timestamp:type_name -> google.protobuf.Timestamp
+	7,  // 9: permission.v1.AuditEvent.response:type_name -> permission.v1.PermissionResponse
+	22, // 10: permission.v1.AuditEvent.latency:type_name -> google.protobuf.Duration
+	10, // 11: permission.v1.AuditEvent.peer:type_name -> permission.v1.PeerCredentials
+	20, // 12: permission.v1.AuditEvent.hook_output:type_name -> sdk_types.v1.SyncHookJSONOutput
+	2,  // 13: permission.v1.Grant.scope:type_name -> permission.v1.GrantScope
+	21, // 14: permission.v1.Grant.created_at:type_name -> google.protobuf.Timestamp
+	21, // 15: permission.v1.Grant.expires_at:type_name -> google.protobuf.Timestamp
+	12, // 16: permission.v1.ListGrantsResponse.grants:type_name -> permission.v1.Grant
+	21, // 17: permission.v1.QueryHistoryRequest.since:type_name -> google.protobuf.Timestamp
+	21, // 18: permission.v1.QueryHistoryRequest.until:type_name -> google.protobuf.Timestamp
+	1,  // 19: permission.v1.QueryHistoryRequest.decision:type_name -> permission.v1.PermissionDecision
+	9,  // 20: permission.v1.QueryHistoryResponse.events:type_name -> permission.v1.AuditEvent
+	3,  // 21: permission.v1.PermissionService.RequestPermission:input_type -> permission.v1.PermissionRequest
+	9,  // 22: permission.v1.PermissionService.Audit:input_type -> permission.v1.AuditEvent
+	13, // 23: permission.v1.PermissionService.ListGrants:input_type -> permission.v1.ListGrantsRequest
+	15, // 24: permission.v1.PermissionService.RevokeGrant:input_type -> permission.v1.RevokeGrantRequest
+	17, // 25: permission.v1.PermissionService.QueryHistory:input_type -> permission.v1.QueryHistoryRequest
+	5,  // 26: permission.v1.PermissionService.HandleHook:input_type -> permission.v1.HookRequest
+	7,  // 27: permission.v1.PermissionService.RequestPermission:output_type -> permission.v1.PermissionResponse
+	11, // 28: permission.v1.PermissionService.Audit:output_type -> permission.v1.AuditResponse
+	14, // 29: permission.v1.PermissionService.ListGrants:output_type -> permission.v1.ListGrantsResponse
+	16, // 30: permission.v1.PermissionService.RevokeGrant:output_type -> permission.v1.RevokeGrantResponse
+	18, // 31: permission.v1.PermissionService.QueryHistory:output_type -> permission.v1.QueryHistoryResponse
+	6,  // 32: permission.v1.PermissionService.HandleHook:output_type -> permission.v1.HookResponse
+	27, // [27:33] is the sub-list for method output_type
+	21, // [21:27] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_permission_v1_permission_proto_init() }
@@ -556,8 +1590,8 @@ func file_permission_v1_permission_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_permission_v1_permission_proto_rawDesc), len(file_permission_v1_permission_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   5,
+			NumEnums:      3,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
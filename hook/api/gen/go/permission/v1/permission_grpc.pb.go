@@ -21,6 +21,10 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	PermissionService_RequestPermission_FullMethodName = "/permission.v1.PermissionService/RequestPermission"
 	PermissionService_Audit_FullMethodName             = "/permission.v1.PermissionService/Audit"
+	PermissionService_ListGrants_FullMethodName        = "/permission.v1.PermissionService/ListGrants"
+	PermissionService_RevokeGrant_FullMethodName       = "/permission.v1.PermissionService/RevokeGrant"
+	PermissionService_QueryHistory_FullMethodName      = "/permission.v1.PermissionService/QueryHistory"
+	PermissionService_HandleHook_FullMethodName        = "/permission.v1.PermissionService/HandleHook"
 )
 
 // PermissionServiceClient is the client API for PermissionService service.
@@ -37,6 +41,16 @@ type PermissionServiceClient interface {
 	// Client-streaming is chosen over unary for future extensibility
 	// (e.g., a long-lived daemon client could reuse the stream).
 	Audit(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[AuditEvent, AuditResponse], error)
+	// ListGrants returns the active "remember this decision" grants.
+	ListGrants(ctx context.Context, in *ListGrantsRequest, opts ...grpc.CallOption) (*ListGrantsResponse, error)
+	// RevokeGrant removes a grant by ID.
+	RevokeGrant(ctx context.Context, in *RevokeGrantRequest, opts ...grpc.CallOption) (*RevokeGrantResponse, error)
+	// QueryHistory returns recorded audit events matching the filter.
+	QueryHistory(ctx context.Context, in *QueryHistoryRequest, opts ...grpc.CallOption) (*QueryHistoryResponse, error)
+	// HandleHook delivers a hook event that is not a permission request
+	// (PostToolUse, Stop, Notification, SessionStart, ...) and returns the
+	// hook output.
+	HandleHook(ctx context.Context, in *HookRequest, opts ...grpc.CallOption) (*HookResponse, error)
 }
 
 type permissionServiceClient struct {
@@ -70,6 +84,46 @@ func (c *permissionServiceClient) Audit(ctx context.Context, opts ...grpc.CallOp
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type PermissionService_AuditClient = grpc.ClientStreamingClient[AuditEvent, AuditResponse]
 
+func (c *permissionServiceClient) ListGrants(ctx context.Context, in *ListGrantsRequest, opts ...grpc.CallOption) (*ListGrantsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGrantsResponse)
+	err := c.cc.Invoke(ctx, PermissionService_ListGrants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionServiceClient) RevokeGrant(ctx context.Context, in *RevokeGrantRequest, opts ...grpc.CallOption) (*RevokeGrantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeGrantResponse)
+	err := c.cc.Invoke(ctx, PermissionService_RevokeGrant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionServiceClient) QueryHistory(ctx context.Context, in *QueryHistoryRequest, opts ...grpc.CallOption) (*QueryHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryHistoryResponse)
+	err := c.cc.Invoke(ctx, PermissionService_QueryHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *permissionServiceClient) HandleHook(ctx context.Context, in *HookRequest, opts ...grpc.CallOption) (*HookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HookResponse)
+	err := c.cc.Invoke(ctx, PermissionService_HandleHook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PermissionServiceServer is the server API for PermissionService service.
 // All implementations must embed UnimplementedPermissionServiceServer
 // for forward compatibility.
@@ -84,6 +138,16 @@ type PermissionServiceServer interface {
 	// Client-streaming is chosen over unary for future extensibility
 	// (e.g., a long-lived daemon client could reuse the stream).
 	Audit(grpc.ClientStreamingServer[AuditEvent, AuditResponse]) error
+	// ListGrants returns the active "remember this decision" grants.
+	ListGrants(context.Context, *ListGrantsRequest) (*ListGrantsResponse, error)
+	// RevokeGrant removes a grant by ID.
+	RevokeGrant(context.Context, *RevokeGrantRequest) (*RevokeGrantResponse, error)
+	// QueryHistory returns recorded audit events matching the filter.
+	QueryHistory(context.Context, *QueryHistoryRequest) (*QueryHistoryResponse, error)
+	// HandleHook delivers a hook event that is not a permission request
+	// (PostToolUse, Stop, Notification, SessionStart, ...) and returns the
+	// hook output.
+	HandleHook(context.Context, *HookRequest) (*HookResponse, error)
 	mustEmbedUnimplementedPermissionServiceServer()
 }
 
@@ -100,6 +164,18 @@ func (UnimplementedPermissionServiceServer) RequestPermission(context.Context, *
 func (UnimplementedPermissionServiceServer) Audit(grpc.ClientStreamingServer[AuditEvent, AuditResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method Audit not implemented")
 }
+func (UnimplementedPermissionServiceServer) ListGrants(context.Context, *ListGrantsRequest) (*ListGrantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGrants not implemented")
+}
+func (UnimplementedPermissionServiceServer) RevokeGrant(context.Context, *RevokeGrantRequest) (*RevokeGrantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeGrant not implemented")
+}
+func (UnimplementedPermissionServiceServer) QueryHistory(context.Context, *QueryHistoryRequest) (*QueryHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryHistory not implemented")
+}
+func (UnimplementedPermissionServiceServer) HandleHook(context.Context, *HookRequest) (*HookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleHook not implemented")
+}
 func (UnimplementedPermissionServiceServer) mustEmbedUnimplementedPermissionServiceServer() {}
 func (UnimplementedPermissionServiceServer) testEmbeddedByValue()                           {}
 
@@ -146,6 +222,78 @@ func _PermissionService_Audit_Handler(srv interface{}, stream grpc.ServerStream)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type PermissionService_AuditServer = grpc.ClientStreamingServer[AuditEvent, AuditResponse]
 
+func _PermissionService_ListGrants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGrantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermissionServiceServer).ListGrants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PermissionService_ListGrants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermissionServiceServer).ListGrants(ctx, req.(*ListGrantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PermissionService_RevokeGrant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermissionServiceServer).RevokeGrant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PermissionService_RevokeGrant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermissionServiceServer).RevokeGrant(ctx, req.(*RevokeGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PermissionService_QueryHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermissionServiceServer).QueryHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PermissionService_QueryHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermissionServiceServer).QueryHistory(ctx, req.(*QueryHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PermissionService_HandleHook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PermissionServiceServer).HandleHook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PermissionService_HandleHook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PermissionServiceServer).HandleHook(ctx, req.(*HookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PermissionService_ServiceDesc is the grpc.ServiceDesc for PermissionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,6 +305,22 @@ var PermissionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RequestPermission",
 			Handler:    _PermissionService_RequestPermission_Handler,
 		},
+		{
+			MethodName: "ListGrants",
+			Handler:    _PermissionService_ListGrants_Handler,
+		},
+		{
+			MethodName: "RevokeGrant",
+			Handler:    _PermissionService_RevokeGrant_Handler,
+		},
+		{
+			MethodName: "QueryHistory",
+			Handler:    _PermissionService_QueryHistory_Handler,
+		},
+		{
+			MethodName: "HandleHook",
+			Handler:    _PermissionService_HandleHook_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
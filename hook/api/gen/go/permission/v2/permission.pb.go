@@ -0,0 +1,334 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: permission/v2/permission.proto
+
+package permissionv2
+
+import (
+	v11 "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	v1 "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PermissionRequest contains the hook input from Claude Code.
+type PermissionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the hook being invoked ("PreToolUse" or "PermissionRequest").
+	HookEventName string `protobuf:"bytes,1,opt,name=hook_event_name,json=hookEventName,proto3" json:"hook_event_name,omitempty"`
+	// The name of the tool being used (e.g., "Bash", "Write", "Edit").
+	ToolName string `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	// The tool input.
+	ToolInput *v1.ToolInput `protobuf:"bytes,3,opt,name=tool_input,json=toolInput,proto3" json:"tool_input,omitempty"`
+	// Session ID from Claude Code.
+	SessionId string `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Message ID from Claude Code.
+	MessageId string `protobuf:"bytes,5,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Current working directory.
+	Cwd string `protobuf:"bytes,6,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	// Path to conversation transcript.
+	TranscriptPath string `protobuf:"bytes,7,opt,name=transcript_path,json=transcriptPath,proto3" json:"transcript_path,omitempty"`
+	// The permission mode of the session (e.g., "default", "plan").
+	PermissionMode string `protobuf:"bytes,8,opt,name=permission_mode,json=permissionMode,proto3" json:"permission_mode,omitempty"`
+	// The complete hook input as received from Claude Code (JSON).
+	HookInputJson string `protobuf:"bytes,9,opt,name=hook_input_json,json=hookInputJson,proto3" json:"hook_input_json,omitempty"`
+	// The tmux pane the hook client runs in, if any.
+	Tmux          *v11.TmuxPane `protobuf:"bytes,10,opt,name=tmux,proto3" json:"tmux,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionRequest) Reset() {
+	*x = PermissionRequest{}
+	mi := &file_permission_v2_permission_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionRequest) ProtoMessage() {}
+
+func (x *PermissionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v2_permission_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionRequest.ProtoReflect.Descriptor instead.
+func (*PermissionRequest) Descriptor() ([]byte, []int) {
+	return file_permission_v2_permission_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PermissionRequest) GetHookEventName() string {
+	if x != nil {
+		return x.HookEventName
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetToolInput() *v1.ToolInput {
+	if x != nil {
+		return x.ToolInput
+	}
+	return nil
+}
+
+func (x *PermissionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetTranscriptPath() string {
+	if x != nil {
+		return x.TranscriptPath
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetPermissionMode() string {
+	if x != nil {
+		return x.PermissionMode
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetHookInputJson() string {
+	if x != nil {
+		return x.HookInputJson
+	}
+	return ""
+}
+
+func (x *PermissionRequest) GetTmux() *v11.TmuxPane {
+	if x != nil {
+		return x.Tmux
+	}
+	return nil
+}
+
+// PermissionResponse contains the decision from the interactive server.
+type PermissionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the agent should continue after this hook (default: true).
+	ShouldContinue bool `protobuf:"varint,1,opt,name=should_continue,json=shouldContinue,proto3" json:"should_continue,omitempty"`
+	// Message shown when should_continue is false.
+	StopReason string `protobuf:"bytes,2,opt,name=stop_reason,json=stopReason,proto3" json:"stop_reason,omitempty"`
+	// Hide stdout from the transcript.
+	SuppressOutput bool `protobuf:"varint,3,opt,name=suppress_output,json=suppressOutput,proto3" json:"suppress_output,omitempty"`
+	// Message injected into the conversation for Claude to see.
+	SystemMessage string `protobuf:"bytes,4,opt,name=system_message,json=systemMessage,proto3" json:"system_message,omitempty"`
+	// The hook-specific output. pre_tool_use answers PermissionRequest
+	// events as well.
+	HookSpecificOutput *v1.HookSpecificOutput `protobuf:"bytes,5,opt,name=hook_specific_output,json=hookSpecificOutput,proto3" json:"hook_specific_output,omitempty"`
+	// Who made the decision.
+	DecisionSource v11.DecisionSource `protobuf:"varint,6,opt,name=decision_source,json=decisionSource,proto3,enum=permission.v1.DecisionSource" json:"decision_source,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PermissionResponse) Reset() {
+	*x = PermissionResponse{}
+	mi := &file_permission_v2_permission_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionResponse) ProtoMessage() {}
+
+func (x *PermissionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_permission_v2_permission_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionResponse.ProtoReflect.Descriptor instead.
+func (*PermissionResponse) Descriptor() ([]byte, []int) {
+	return file_permission_v2_permission_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PermissionResponse) GetShouldContinue() bool {
+	if x != nil {
+		return x.ShouldContinue
+	}
+	return false
+}
+
+func (x *PermissionResponse) GetStopReason() string {
+	if x != nil {
+		return x.StopReason
+	}
+	return ""
+}
+
+func (x *PermissionResponse) GetSuppressOutput() bool {
+	if x != nil {
+		return x.SuppressOutput
+	}
+	return false
+}
+
+func (x *PermissionResponse) GetSystemMessage() string {
+	if x != nil {
+		return x.SystemMessage
+	}
+	return ""
+}
+
+func (x *PermissionResponse) GetHookSpecificOutput() *v1.HookSpecificOutput {
+	if x != nil {
+		return x.HookSpecificOutput
+	}
+	return nil
+}
+
+func (x *PermissionResponse) GetDecisionSource() v11.DecisionSource {
+	if x != nil {
+		return x.DecisionSource
+	}
+	return v11.DecisionSource(0)
+}
+
+var File_permission_v2_permission_proto protoreflect.FileDescriptor
+
+const file_permission_v2_permission_proto_rawDesc = "" +
+	"\n" +
+	"\x1epermission/v2/permission.proto\x12\rpermission.v2\x1a\x1epermission/v1/permission.proto\x1a\x17sdk_types/v1/hook.proto\x1a\x1dsdk_types/v1/tool_input.proto\"\x87\x03\n" +
+	"\x11PermissionRequest\x12&\n" +
+	"\x0fhook_event_name\x18\x01 \x01(\tR\rhookEventName\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x126\n" +
+	"\n" +
+	"tool_input\x18\x03 \x01(\v2\x17.sdk_types.v1.ToolInputR\ttoolInput\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x05 \x01(\tR\tmessageId\x12\x10\n" +
+	"\x03cwd\x18\x06 \x01(\tR\x03cwd\x12'\n" +
+	"\x0ftranscript_path\x18\a \x01(\tR\x0etranscriptPath\x12'\n" +
+	"\x0fpermission_mode\x18\b \x01(\tR\x0epermissionMode\x12&\n" +
+	"\x0fhook_input_json\x18\t \x01(\tR\rhookInputJson\x12+\n" +
+	"\x04tmux\x18\n" +
+	" \x01(\v2\x17.permission.v1.TmuxPaneR\x04tmux\"\xca\x02\n" +
+	"\x12PermissionResponse\x12'\n" +
+	"\x0fshould_continue\x18\x01 \x01(\bR\x0eshouldContinue\x12\x1f\n" +
+	"\vstop_reason\x18\x02 \x01(\tR\n" +
+	"stopReason\x12'\n" +
+	"\x0fsuppress_output\x18\x03 \x01(\bR\x0esuppressOutput\x12%\n" +
+	"\x0esystem_message\x18\x04 \x01(\tR\rsystemMessage\x12R\n" +
+	"\x14hook_specific_output\x18\x05 \x01(\v2 .sdk_types.v1.HookSpecificOutputR\x12hookSpecificOutput\x12F\n" +
+	"\x0fdecision_source\x18\x06 \x01(\x0e2\x1d.permission.v1.DecisionSourceR\x0edecisionSource2m\n" +
+	"\x11PermissionService\x12X\n" +
+	"\x11RequestPermission\x12 .permission.v2.PermissionRequest\x1a!.permission.v2.PermissionResponseB\xc1\x01\n" +
+	"\x11com.permission.v2B\x0fPermissionProtoP\x01ZFgithub.com/ngicks/crabswarm/hook/api/gen/go/permission/v2;permissionv2\xa2\x02\x03PXX\xaa\x02\rPermission.V2\xca\x02\rPermission\\V2\xe2\x02\x19Permission\\V2\\GPBMetadata\xea\x02\x0ePermission::V2b\x06proto3"
+
+var (
+	file_permission_v2_permission_proto_rawDescOnce sync.Once
+	file_permission_v2_permission_proto_rawDescData []byte
+)
+
+func file_permission_v2_permission_proto_rawDescGZIP() []byte {
+	file_permission_v2_permission_proto_rawDescOnce.Do(func() {
+		file_permission_v2_permission_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_permission_v2_permission_proto_rawDesc), len(file_permission_v2_permission_proto_rawDesc)))
+	})
+	return file_permission_v2_permission_proto_rawDescData
+}
+
+var file_permission_v2_permission_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_permission_v2_permission_proto_goTypes = []any{
+	(*PermissionRequest)(nil),     // 0: permission.v2.PermissionRequest
+	(*PermissionResponse)(nil),    // 1: permission.v2.PermissionResponse
+	(*v1.ToolInput)(nil),          // 2: sdk_types.v1.ToolInput
+	(*v11.TmuxPane)(nil),          // 3: permission.v1.TmuxPane
+	(*v1.HookSpecificOutput)(nil), // 4: sdk_types.v1.HookSpecificOutput
+	(v11.DecisionSource)(0),       // 5: permission.v1.DecisionSource
+}
+var file_permission_v2_permission_proto_depIdxs = []int32{
+	2, // 0: permission.v2.PermissionRequest.tool_input:type_name -> sdk_types.v1.ToolInput
+	3, // 1: permission.v2.PermissionRequest.tmux:type_name -> permission.v1.TmuxPane
+	4, // 2: permission.v2.PermissionResponse.hook_specific_output:type_name -> sdk_types.v1.HookSpecificOutput
+	5, // 3: permission.v2.PermissionResponse.decision_source:type_name -> permission.v1.DecisionSource
+	0, // 4: permission.v2.PermissionService.RequestPermission:input_type -> permission.v2.PermissionRequest
+	1, // 5: permission.v2.PermissionService.RequestPermission:output_type -> permission.v2.PermissionResponse
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_permission_v2_permission_proto_init() }
+func file_permission_v2_permission_proto_init() {
+	if File_permission_v2_permission_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_permission_v2_permission_proto_rawDesc), len(file_permission_v2_permission_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_permission_v2_permission_proto_goTypes,
+		DependencyIndexes: file_permission_v2_permission_proto_depIdxs,
+		MessageInfos:      file_permission_v2_permission_proto_msgTypes,
+	}.Build()
+	File_permission_v2_permission_proto = out.File
+	file_permission_v2_permission_proto_goTypes = nil
+	file_permission_v2_permission_proto_depIdxs = nil
+}
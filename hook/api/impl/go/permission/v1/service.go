@@ -7,6 +7,8 @@ import (
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // PermissionHandler is the interface that must be implemented to handle permission requests.
@@ -20,6 +22,29 @@ type AuditHandler interface {
 	HandleAuditEvent(ctx context.Context, event *pb.AuditEvent) error
 }
 
+// HookHandler is optionally implemented by the PermissionHandler to serve
+// the HandleHook RPC.
+type HookHandler interface {
+	// HandleHook processes a non-permission hook event and returns the hook output.
+	HandleHook(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error)
+}
+
+// GrantHandler is optionally implemented by the PermissionHandler to serve
+// the ListGrants and RevokeGrant RPCs.
+type GrantHandler interface {
+	// ListGrants returns the active grants.
+	ListGrants(ctx context.Context) ([]*pb.Grant, error)
+	// RevokeGrant removes the grant with the given ID and reports whether it existed.
+	RevokeGrant(ctx context.Context, id string) (bool, error)
+}
+
+// HistoryHandler is optionally implemented by the PermissionHandler to serve
+// the QueryHistory RPC.
+type HistoryHandler interface {
+	// QueryHistory returns the recorded audit events matching req.
+	QueryHistory(ctx context.Context, req *pb.QueryHistoryRequest) ([]*pb.AuditEvent, error)
+}
+
 // Service implements the PermissionServiceServer interface.
 type Service struct {
 	pb.UnimplementedPermissionServiceServer
@@ -63,3 +88,51 @@ func (s *Service) Audit(stream grpc.ClientStreamingServer[pb.AuditEvent, pb.Audi
 		count++
 	}
 }
+
+// HandleHook implements the PermissionServiceServer interface.
+func (s *Service) HandleHook(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error) {
+	h, ok := s.handler.(HookHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "hook events are not supported")
+	}
+	return h.HandleHook(ctx, req)
+}
+
+// ListGrants implements the PermissionServiceServer interface.
+func (s *Service) ListGrants(ctx context.Context, _ *pb.ListGrantsRequest) (*pb.ListGrantsResponse, error) {
+	h, ok := s.handler.(GrantHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "grants are not supported")
+	}
+	grants, err := h.ListGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListGrantsResponse{Grants: grants}, nil
+}
+
+// RevokeGrant implements the PermissionServiceServer interface.
+func (s *Service) RevokeGrant(ctx context.Context, req *pb.RevokeGrantRequest) (*pb.RevokeGrantResponse, error) {
+	h, ok := s.handler.(GrantHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "grants are not supported")
+	}
+	revoked, err := h.RevokeGrant(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RevokeGrantResponse{Revoked: revoked}, nil
+}
+
+// QueryHistory implements the PermissionServiceServer interface.
+func (s *Service) QueryHistory(ctx context.Context, req *pb.QueryHistoryRequest) (*pb.QueryHistoryResponse, error) {
+	h, ok := s.handler.(HistoryHandler)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "history is not supported")
+	}
+	events, err := h.QueryHistory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.QueryHistoryResponse{Events: events}, nil
+}
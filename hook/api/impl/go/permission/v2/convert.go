@@ -0,0 +1,175 @@
+package permissionv2impl
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v2"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/model/protoconv"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+)
+
+var decisions = map[v1pb.PermissionDecision]model.PermissionDecision{
+	v1pb.PermissionDecision_PERMISSION_DECISION_ALLOW: model.PermissionAllow,
+	v1pb.PermissionDecision_PERMISSION_DECISION_DENY:  model.PermissionDeny,
+	v1pb.PermissionDecision_PERMISSION_DECISION_ASK:   model.PermissionAsk,
+}
+
+// RequestToV1 converts a v2 request to v1. The typed tool input is kept and
+// also encoded back to JSON for consumers of tool_input_json.
+func RequestToV1(req *pb.PermissionRequest) (*v1pb.PermissionRequest, error) {
+	input, err := protoconv.ToolInputFromProto(req.GetToolInput())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert tool input: %w", err)
+	}
+	return &v1pb.PermissionRequest{
+		HookEventName:  req.GetHookEventName(),
+		ToolName:       req.GetToolName(),
+		ToolInputJson:  string(input),
+		SessionId:      req.GetSessionId(),
+		MessageId:      req.GetMessageId(),
+		Cwd:            req.GetCwd(),
+		TranscriptPath: req.GetTranscriptPath(),
+		HookInputJson:  req.GetHookInputJson(),
+		Tmux:           req.GetTmux(),
+		PermissionMode: req.GetPermissionMode(),
+		ToolInput:      req.GetToolInput(),
+	}, nil
+}
+
+// RequestFromV1 converts a v1 request to v2. The typed tool input is used if
+// set, and converted from tool_input_json otherwise.
+func RequestFromV1(req *v1pb.PermissionRequest) (*pb.PermissionRequest, error) {
+	input := req.GetToolInput()
+	if input == nil {
+		var err error
+		input, err = protoconv.ToolInputToProto(model.ToolName(req.GetToolName()), json.RawMessage(req.GetToolInputJson()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert tool input: %w", err)
+		}
+	}
+	return &pb.PermissionRequest{
+		HookEventName:  req.GetHookEventName(),
+		ToolName:       req.GetToolName(),
+		ToolInput:      input,
+		SessionId:      req.GetSessionId(),
+		MessageId:      req.GetMessageId(),
+		Cwd:            req.GetCwd(),
+		TranscriptPath: req.GetTranscriptPath(),
+		HookInputJson:  req.GetHookInputJson(),
+		Tmux:           req.GetTmux(),
+		PermissionMode: req.GetPermissionMode(),
+	}, nil
+}
+
+// ResponseFromV1 converts a v1 response to v2. tool is the tool of the
+// request and selects the typed message of the updated input.
+//
+// PreToolUse and PermissionRequest outputs both become pre_tool_use. Outputs
+// of other events without a counterpart in HookSpecificOutput are an error.
+func ResponseFromV1(resp *v1pb.PermissionResponse, tool string) (*pb.PermissionResponse, error) {
+	out := &pb.PermissionResponse{
+		ShouldContinue: resp.GetShouldContinue(),
+		StopReason:     resp.GetStopReason(),
+		SuppressOutput: resp.GetSuppressOutput(),
+		SystemMessage:  resp.GetSystemMessage(),
+		DecisionSource: resp.GetDecisionSource(),
+	}
+
+	hso := resp.GetHookSpecificOutput()
+	if hso == nil {
+		return out, nil
+	}
+	context := optString(hso.GetAdditionalContext())
+	switch model.HookEventName(hso.GetHookEventName()) {
+	case "", model.HookEventPreToolUse, model.HookEventPermissionRequest:
+		updated, err := protoconv.ToolInputToProto(model.ToolName(tool), json.RawMessage(hso.GetUpdatedInputJson()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert updated input: %w", err)
+		}
+		pre := &sdkpb.PreToolUseHookSpecificOutput{
+			PermissionDecision:       optString(string(decisions[hso.GetPermissionDecision()])),
+			PermissionDecisionReason: optString(hso.GetPermissionDecisionReason()),
+			UpdatedInput:             updated,
+			AdditionalContext:        context,
+		}
+		out.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_PreToolUse{PreToolUse: pre}}
+	case model.HookEventUserPromptSubmit:
+		out.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_UserPromptSubmit{
+			UserPromptSubmit: &sdkpb.UserPromptSubmitHookSpecificOutput{AdditionalContext: context},
+		}}
+	case model.HookEventSessionStart:
+		out.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_SessionStart{
+			SessionStart: &sdkpb.SessionStartHookSpecificOutput{AdditionalContext: context},
+		}}
+	case model.HookEventPostToolUse:
+		out.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_PostToolUse{
+			PostToolUse: &sdkpb.PostToolUseHookSpecificOutput{AdditionalContext: context},
+		}}
+	default:
+		return nil, fmt.Errorf("hook-specific output for %q has no v2 counterpart", hso.GetHookEventName())
+	}
+	return out, nil
+}
+
+// ResponseToV1 converts a v2 response to v1. hookEventName is the event of
+// the request, which pre_tool_use outputs are reported for.
+func ResponseToV1(resp *pb.PermissionResponse, hookEventName string) (*v1pb.PermissionResponse, error) {
+	out := &v1pb.PermissionResponse{
+		ShouldContinue: resp.GetShouldContinue(),
+		StopReason:     resp.GetStopReason(),
+		SuppressOutput: resp.GetSuppressOutput(),
+		SystemMessage:  resp.GetSystemMessage(),
+		DecisionSource: resp.GetDecisionSource(),
+	}
+
+	switch x := resp.GetHookSpecificOutput().GetOutput().(type) {
+	case nil:
+	case *sdkpb.HookSpecificOutput_PreToolUse:
+		updated, err := protoconv.ToolInputFromProto(x.PreToolUse.GetUpdatedInput())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert updated input: %w", err)
+		}
+		var decision v1pb.PermissionDecision
+		for d, m := range decisions {
+			if string(m) == x.PreToolUse.GetPermissionDecision() {
+				decision = d
+			}
+		}
+		out.HookSpecificOutput = &v1pb.HookSpecificOutput{
+			HookEventName:            hookEventName,
+			PermissionDecision:       decision,
+			PermissionDecisionReason: x.PreToolUse.GetPermissionDecisionReason(),
+			UpdatedInputJson:         string(updated),
+			AdditionalContext:        x.PreToolUse.GetAdditionalContext(),
+		}
+	case *sdkpb.HookSpecificOutput_UserPromptSubmit:
+		out.HookSpecificOutput = &v1pb.HookSpecificOutput{
+			HookEventName:     string(model.HookEventUserPromptSubmit),
+			AdditionalContext: x.UserPromptSubmit.GetAdditionalContext(),
+		}
+	case *sdkpb.HookSpecificOutput_SessionStart:
+		out.HookSpecificOutput = &v1pb.HookSpecificOutput{
+			HookEventName:     string(model.HookEventSessionStart),
+			AdditionalContext: x.SessionStart.GetAdditionalContext(),
+		}
+	case *sdkpb.HookSpecificOutput_PostToolUse:
+		out.HookSpecificOutput = &v1pb.HookSpecificOutput{
+			HookEventName:     string(model.HookEventPostToolUse),
+			AdditionalContext: x.PostToolUse.GetAdditionalContext(),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported hook-specific output %T", x)
+	}
+	return out, nil
+}
+
+// optString returns a pointer to s, or nil if s is empty.
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,42 @@
+// Package permissionv2impl provides the server implementation for the v2
+// PermissionService. Requests are converted to v1, keeping their typed tool
+// input, and decided by the same permissionv1impl.PermissionHandler, so both
+// versions can be served side by side.
+package permissionv2impl
+
+import (
+	"context"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v2"
+	permissionv1impl "github.com/ngicks/crabswarm/hook/api/impl/go/permission/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Service implements the PermissionServiceServer interface.
+type Service struct {
+	pb.UnimplementedPermissionServiceServer
+	handler permissionv1impl.PermissionHandler
+}
+
+// NewService creates a new Service deciding requests with handler.
+func NewService(handler permissionv1impl.PermissionHandler) *Service {
+	return &Service{handler: handler}
+}
+
+// RequestPermission implements the PermissionServiceServer interface.
+func (s *Service) RequestPermission(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	v1Req, err := RequestToV1(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := s.handler.HandlePermissionRequest(ctx, v1Req)
+	if err != nil {
+		return nil, err
+	}
+	out, err := ResponseFromV1(resp, req.GetToolName())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return out, nil
+}
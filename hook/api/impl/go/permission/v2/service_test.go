@@ -0,0 +1,231 @@
+package permissionv2impl
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	v1pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v2"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordingPermissionHandler records the v1 request and answers with resp.
+type recordingPermissionHandler struct {
+	req  *v1pb.PermissionRequest
+	resp *v1pb.PermissionResponse
+}
+
+func (h *recordingPermissionHandler) HandlePermissionRequest(_ context.Context, req *v1pb.PermissionRequest) (*v1pb.PermissionResponse, error) {
+	h.req = req
+	return h.resp, nil
+}
+
+func newTestClient(t *testing.T, svc *Service) pb.PermissionServiceClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterPermissionServiceServer(grpcServer, svc)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPermissionServiceClient(conn)
+}
+
+func TestServiceRequestPermission(t *testing.T) {
+	handler := &recordingPermissionHandler{resp: &v1pb.PermissionResponse{
+		ShouldContinue: true,
+		HookSpecificOutput: &v1pb.HookSpecificOutput{
+			HookEventName:            "PreToolUse",
+			PermissionDecision:       v1pb.PermissionDecision_PERMISSION_DECISION_ALLOW,
+			PermissionDecisionReason: "looks fine",
+			UpdatedInputJson:         `{"command":"ls -la"}`,
+		},
+		DecisionSource: v1pb.DecisionSource_DECISION_SOURCE_HUMAN,
+	}}
+	client := newTestClient(t, NewService(handler))
+
+	resp, err := client.RequestPermission(context.Background(), &pb.PermissionRequest{
+		HookEventName: "PreToolUse",
+		ToolName:      "Bash",
+		ToolInput: &sdkpb.ToolInput{Input: &sdkpb.ToolInput_Bash{
+			Bash: &sdkpb.BashInput{Command: "ls"},
+		}},
+		SessionId:      "sess-1",
+		Cwd:            "/work",
+		PermissionMode: "plan",
+		Tmux:           &v1pb.TmuxPane{PaneId: "%3"},
+	})
+	if err != nil {
+		t.Fatalf("RequestPermission() error = %v", err)
+	}
+
+	got := handler.req
+	if got.GetToolName() != "Bash" || got.GetSessionId() != "sess-1" || got.GetCwd() != "/work" || got.GetTmux().GetPaneId() != "%3" {
+		t.Errorf("v1 request = %v", got)
+	}
+	if got.GetToolInputJson() != `{"command":"ls"}` {
+		t.Errorf("tool_input_json = %s, want %s", got.GetToolInputJson(), `{"command":"ls"}`)
+	}
+	if got.GetToolInput().GetBash().GetCommand() != "ls" {
+		t.Errorf("tool_input = %v, want the typed Bash input", got.GetToolInput())
+	}
+	if got.GetPermissionMode() != "plan" {
+		t.Errorf("permission_mode = %q, want %q", got.GetPermissionMode(), "plan")
+	}
+
+	pre := resp.GetHookSpecificOutput().GetPreToolUse()
+	if pre.GetPermissionDecision() != "allow" || pre.GetPermissionDecisionReason() != "looks fine" {
+		t.Errorf("pre_tool_use = %v", pre)
+	}
+	if pre.GetUpdatedInput().GetBash().GetCommand() != "ls -la" {
+		t.Errorf("updated_input = %v, want bash command %q", pre.GetUpdatedInput(), "ls -la")
+	}
+	if resp.GetDecisionSource() != v1pb.DecisionSource_DECISION_SOURCE_HUMAN {
+		t.Errorf("decision_source = %v", resp.GetDecisionSource())
+	}
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	req := &v1pb.PermissionRequest{
+		HookEventName:  "PermissionRequest",
+		ToolName:       "Edit",
+		ToolInputJson:  `{"file_path":"/work/a.go","old_string":"a","new_string":"b"}`,
+		SessionId:      "sess-1",
+		MessageId:      "msg-1",
+		Cwd:            "/work",
+		TranscriptPath: "/tmp/t.jsonl",
+		HookInputJson:  `{"hook_event_name":"PermissionRequest"}`,
+		Tmux:           &v1pb.TmuxPane{SocketPath: "/tmp/tmux", PaneId: "%1"},
+		PermissionMode: "acceptEdits",
+	}
+
+	v2Req, err := RequestFromV1(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2Req.GetToolInput().GetFileEdit().GetOldString() != "a" {
+		t.Errorf("tool_input = %v, want a typed Edit input", v2Req.GetToolInput())
+	}
+	back, err := RequestToV1(v2Req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameJSON(t, back.GetToolInputJson(), req.GetToolInputJson())
+	back.ToolInputJson = req.ToolInputJson
+	if !proto.Equal(back.GetToolInput(), v2Req.GetToolInput()) {
+		t.Errorf("tool_input = %v, want the typed input %v", back.GetToolInput(), v2Req.GetToolInput())
+	}
+	back.ToolInput = nil
+	if !proto.Equal(back, req) {
+		t.Errorf("round trip = %v, want %v", back, req)
+	}
+}
+
+func TestResponseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		resp  *v1pb.PermissionResponse
+	}{
+		{
+			name:  "no output",
+			event: "PreToolUse",
+			resp:  &v1pb.PermissionResponse{ShouldContinue: false, StopReason: "stop", SystemMessage: "bye"},
+		},
+		{
+			name:  "deny",
+			event: "PreToolUse",
+			resp: &v1pb.PermissionResponse{
+				ShouldContinue: true,
+				HookSpecificOutput: &v1pb.HookSpecificOutput{
+					HookEventName:            "PreToolUse",
+					PermissionDecision:       v1pb.PermissionDecision_PERMISSION_DECISION_DENY,
+					PermissionDecisionReason: "no",
+				},
+				DecisionSource: v1pb.DecisionSource_DECISION_SOURCE_POLICY,
+			},
+		},
+		{
+			name:  "permission request with updated input",
+			event: "PermissionRequest",
+			resp: &v1pb.PermissionResponse{
+				ShouldContinue: true,
+				HookSpecificOutput: &v1pb.HookSpecificOutput{
+					HookEventName:      "PermissionRequest",
+					PermissionDecision: v1pb.PermissionDecision_PERMISSION_DECISION_ALLOW,
+					UpdatedInputJson:   `{"questions":[{"question":"Which?","header":"Pick","options":[{"label":"A","description":"a"}],"multiSelect":false}],"answers":{"Which?":"A"}}`,
+					AdditionalContext:  "answered",
+				},
+			},
+		},
+		{
+			name:  "post tool use context",
+			event: "PostToolUse",
+			resp: &v1pb.PermissionResponse{
+				ShouldContinue: true,
+				HookSpecificOutput: &v1pb.HookSpecificOutput{
+					HookEventName:     "PostToolUse",
+					AdditionalContext: "ok",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v2Resp, err := ResponseFromV1(tt.resp, "AskUserQuestion")
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := ResponseToV1(v2Resp, tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.resp.GetHookSpecificOutput().GetUpdatedInputJson(); want != "" {
+				assertSameJSON(t, back.GetHookSpecificOutput().GetUpdatedInputJson(), want)
+				back.HookSpecificOutput.UpdatedInputJson = want
+			}
+			if !proto.Equal(back, tt.resp) {
+				t.Errorf("round trip = %v, want %v", back, tt.resp)
+			}
+		})
+	}
+}
+
+func TestResponseFromV1_UnsupportedEvent(t *testing.T) {
+	_, err := ResponseFromV1(&v1pb.PermissionResponse{
+		HookSpecificOutput: &v1pb.HookSpecificOutput{HookEventName: "Stop"},
+	}, "")
+	if err == nil {
+		t.Error("ResponseFromV1() error = nil, want error")
+	}
+}
+
+func assertSameJSON(t *testing.T, got, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal([]byte(got), &g); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid JSON %s: %v", want, err)
+	}
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	if string(gb) != string(wb) {
+		t.Errorf("JSON = %s, want %s", gb, wb)
+	}
+}
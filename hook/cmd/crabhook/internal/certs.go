@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/ngicks/crabswarm/hook/internal/certs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	certsDir      string
+	certsHosts    []string
+	certsValidity time.Duration
+	certsForce    bool
+)
+
+// certsCmd groups the certificate management subcommands.
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Manage TLS certificates for client/server authentication",
+}
+
+var certsInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate a local CA and server/client certificates",
+	Long: `Generate a local certificate authority and server and client key pairs
+signed by it, for mutual TLS between crabhook clients and 'crabhook serve'.
+
+Private keys are written with mode 0600. Existing files are kept unless
+--force is given.`,
+	Args: cobra.NoArgs,
+	RunE: runCertsInit,
+}
+
+func init() {
+	certsInitCmd.Flags().StringVar(&certsDir, "dir", "", "Output directory (default $XDG_CONFIG_HOME/crabhook/certs)")
+	certsInitCmd.Flags().StringSliceVar(&certsHosts, "host", certs.DefaultHosts, "DNS name or IP address of the server certificate (repeatable)")
+	certsInitCmd.Flags().DurationVar(&certsValidity, "validity", certs.DefaultValidity, "Certificate lifetime")
+	certsInitCmd.Flags().BoolVar(&certsForce, "force", false, "Overwrite existing files")
+	certsCmd.AddCommand(certsInitCmd)
+	rootCmd.AddCommand(certsCmd)
+}
+
+func runCertsInit(cmd *cobra.Command, args []string) error {
+	dir := certsDir
+	if dir == "" {
+		var err error
+		if dir, err = certs.DefaultDir(); err != nil {
+			return err
+		}
+	}
+
+	if err := certs.Generate(dir, certs.Options{
+		Hosts:    certsHosts,
+		Validity: certsValidity,
+		Force:    certsForce,
+	}); err != nil {
+		return fmt.Errorf("failed to generate certificates: %w", err)
+	}
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Certificates written to %s\n\n", dir)
+	fmt.Fprintf(out, "Server:\n  crabhook serve --tls-cert %s --tls-key %s --tls-client-ca %s\n",
+		path(certs.ServerCertFile), path(certs.ServerKeyFile), path(certs.CACertFile))
+	fmt.Fprintf(out, "Client:\n  crabhook --tls-ca %s --tls-cert %s --tls-key %s\n",
+		path(certs.CACertFile), path(certs.ClientCertFile), path(certs.ClientKeyFile))
+	return nil
+}
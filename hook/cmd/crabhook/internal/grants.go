@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/spf13/cobra"
+)
+
+// grantsCmd groups the grant management subcommands.
+var grantsCmd = &cobra.Command{
+	Use:   "grants",
+	Short: "List or revoke remembered permission grants",
+	Long: `List or revoke the "remember this decision" grants held by a running
+permission server. Grants are created from the TUI and are consulted before
+prompting.`,
+}
+
+var grantsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List active grants",
+	Args:  cobra.NoArgs,
+	RunE:  runGrantsList,
+}
+
+var grantsRevokeCmd = &cobra.Command{
+	Use:   "revoke ID...",
+	Short: "Revoke grants by ID",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runGrantsRevoke,
+}
+
+func init() {
+	addClientFlags(grantsCmd.PersistentFlags())
+	grantsCmd.AddCommand(grantsListCmd, grantsRevokeCmd)
+	rootCmd.AddCommand(grantsCmd)
+}
+
+func runGrantsList(cmd *cobra.Command, args []string) error {
+	client, conn, err := dialServer()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.ListGrants(ctx, &pb.ListGrantsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list grants: %w", err)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSCOPE\tTOOL\tTARGET\tEXPIRES")
+	for _, g := range resp.GetGrants() {
+		expires := "-"
+		if g.GetExpiresAt() != nil {
+			expires = g.GetExpiresAt().AsTime().Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", g.GetId(), enumName(g.GetScope().String(), "GRANT_SCOPE_"), g.GetToolName(), grantTarget(g), expires)
+	}
+	return w.Flush()
+}
+
+func runGrantsRevoke(cmd *cobra.Command, args []string) error {
+	client, conn, err := dialServer()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+	defer cancel()
+
+	for _, id := range args {
+		resp, err := client.RevokeGrant(ctx, &pb.RevokeGrantRequest{Id: id})
+		if err != nil {
+			return fmt.Errorf("failed to revoke grant %s: %w", id, err)
+		}
+		if !resp.GetRevoked() {
+			return fmt.Errorf("grant %s not found", id)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "revoked %s\n", id)
+	}
+	return nil
+}
+
+// grantTarget describes what a grant applies to besides the tool.
+func grantTarget(g *pb.Grant) string {
+	switch g.GetScope() {
+	case pb.GrantScope_GRANT_SCOPE_SESSION:
+		return "session " + g.GetSessionId()
+	case pb.GrantScope_GRANT_SCOPE_PROJECT_COMMAND:
+		return fmt.Sprintf("%q in %s", g.GetInput(), g.GetCwd())
+	default:
+		return "any session"
+	}
+}
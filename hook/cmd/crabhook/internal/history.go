@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	historySession  string
+	historyTool     string
+	historySince    string
+	historyUntil    string
+	historyDecision string
+	historyLimit    int32
+	historyJSON     bool
+)
+
+// historyCmd queries the decision history recorded by the permission server.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Query the permission decision history",
+	Long: `Query the decision history recorded by a permission server started with
+--history.
+
+--since and --until accept an RFC 3339 time or a duration relative to now
+(e.g. "2h" for two hours ago).`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	addClientFlags(historyCmd.Flags())
+	historyCmd.Flags().StringVar(&historySession, "session", "", "Only events of this session ID")
+	historyCmd.Flags().StringVar(&historyTool, "tool", "", "Only events of this tool")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only events at or after this time")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Only events before this time")
+	historyCmd.Flags().StringVar(&historyDecision, "decision", "", "Only events with this decision: \"allow\", \"deny\", or \"ask\"")
+	historyCmd.Flags().Int32VarP(&historyLimit, "limit", "n", 50, "Maximum number of most recent events (0 for no limit)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Print events as JSON lines")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	req := &pb.QueryHistoryRequest{
+		SessionId: historySession,
+		ToolName:  historyTool,
+		Limit:     historyLimit,
+	}
+
+	now := time.Now()
+	var err error
+	if req.Since, err = parseHistoryTime(historySince, now); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if req.Until, err = parseHistoryTime(historyUntil, now); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	switch historyDecision {
+	case "":
+	case "allow":
+		req.Decision = pb.PermissionDecision_PERMISSION_DECISION_ALLOW
+	case "deny":
+		req.Decision = pb.PermissionDecision_PERMISSION_DECISION_DENY
+	case "ask":
+		req.Decision = pb.PermissionDecision_PERMISSION_DECISION_ASK
+	default:
+		return fmt.Errorf("invalid --decision %q: must be \"allow\", \"deny\", or \"ask\"", historyDecision)
+	}
+
+	client, conn, err := dialServer()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.QueryHistory(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+
+	if historyJSON {
+		for _, event := range resp.GetEvents() {
+			data, err := protojson.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSESSION\tEVENT\tTOOL\tDECISION\tSOURCE\tLATENCY\tREASON")
+	for _, event := range resp.GetEvents() {
+		req := event.GetRequest()
+		hso := event.GetResponse().GetHookSpecificOutput()
+		latency := "-"
+		if event.GetLatency() != nil {
+			latency = event.GetLatency().AsDuration().Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			event.GetTimestamp().AsTime().Local().Format(time.DateTime),
+			req.GetSessionId(),
+			req.GetHookEventName(),
+			req.GetToolName(),
+			enumName(hso.GetPermissionDecision().String(), "PERMISSION_DECISION_"),
+			enumName(event.GetResponse().GetDecisionSource().String(), "DECISION_SOURCE_"),
+			latency,
+			hso.GetPermissionDecisionReason(),
+		)
+	}
+	return w.Flush()
+}
+
+// parseHistoryTime parses an RFC 3339 time or a duration before now.
+// An empty string yields nil.
+func parseHistoryTime(s string, now time.Time) (*timestamppb.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return timestamppb.New(now.Add(-d)), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	return timestamppb.New(t), nil
+}
+
+// enumName returns the lower-case short name of a protobuf enum value,
+// or "-" for the unspecified value.
+func enumName(name, prefix string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, prefix))
+	if name == "unspecified" {
+		return "-"
+	}
+	return name
+}
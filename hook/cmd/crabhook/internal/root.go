@@ -5,21 +5,41 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"time"
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	pbv2 "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v2"
+	implv2 "github.com/ngicks/crabswarm/hook/api/impl/go/permission/v2"
+	"github.com/ngicks/crabswarm/hook/internal/server"
 	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/model/protoconv"
+	"github.com/ngicks/crabswarm/internal/tmux"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
-	serverAddr string
-	timeout    time.Duration
+	serverAddr         string
+	timeout            time.Duration
+	connectTimeout     time.Duration
+	clientTLSCA        string
+	clientTLSCert      string
+	clientTLSKey       string
+	clientToken        string
+	fallbackAction     string
+	fallbackCategories map[string]string
+	fallbackPolicy     string
 )
 
 // rootCmd is the root command (hook client mode).
@@ -34,13 +54,27 @@ When run without a subcommand, it acts as a hook client:
 - Sends a gRPC request to the permission server
 - Writes the decision to stdout (JSON)
 
+If the server cannot be reached within --connect-timeout, or the connection
+fails, permission requests get the --fallback decision instead: "deny",
+"allow", "ask" (defer to Claude Code's native prompt) or "policy" (evaluate
+the rule file given by --fallback-policy offline). --fallback-category
+overrides the action per tool category, e.g. command=policy,file_path=ask.
+Categories are command, file_path, web, task, user_interaction, plan_mode,
+mcp and other. Requests nobody answers within --timeout are deferred to
+Claude Code's native prompt, and requests the server rejects, e.g. for a
+wrong --token-file, are denied, regardless of --fallback.
+
 Use 'crabhook serve' to start the interactive permission server.`,
 	RunE: runHookClient,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&serverAddr, "server", "s", "localhost:50051", "Permission server address")
+	addClientFlags(rootCmd.Flags())
 	rootCmd.Flags().DurationVarP(&timeout, "timeout", "t", 5*time.Minute, "Request timeout")
+	rootCmd.Flags().DurationVar(&connectTimeout, "connect-timeout", 2*time.Second, "Give up connecting to the server after this long")
+	rootCmd.Flags().StringVar(&fallbackAction, "fallback", string(server.FallbackDeny), "Decision when the server is unavailable: deny, allow, ask or policy")
+	rootCmd.Flags().StringToStringVar(&fallbackCategories, "fallback-category", nil, "Per tool category fallback, e.g. command=policy,file_path=ask")
+	rootCmd.Flags().StringVar(&fallbackPolicy, "fallback-policy", "", "Policy file evaluated offline by the \"policy\" fallback")
 }
 
 // Execute runs the root command.
@@ -50,25 +84,32 @@ func Execute() error {
 
 // runHookClient is the main logic for the hook client mode.
 func runHookClient(cmd *cobra.Command, args []string) error {
-	// Read hook input from stdin
+	// Read hook input from stdin. The raw JSON is forwarded as well so that
+	// the server sees every field.
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read hook input: %w", err)
+	}
 	var input model.HookInput
-	decoder := json.NewDecoder(os.Stdin)
-	if err := decoder.Decode(&input); err != nil {
+	if err := json.Unmarshal(data, &input); err != nil {
 		return fmt.Errorf("failed to decode hook input: %w", err)
 	}
 
+	fallback, err := loadFallback()
+	if err != nil {
+		return err
+	}
+
 	// Connect to the permission server
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	conn, err := grpc.NewClient(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	client, conn, err := dialServer()
 	if err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
+		return err
 	}
 	defer conn.Close()
 
-	client := pb.NewPermissionServiceClient(conn)
-
 	// Build the permission request
 	toolInputJSON := ""
 	if input.ToolInput != nil {
@@ -83,29 +124,229 @@ func runHookClient(cmd *cobra.Command, args []string) error {
 		MessageId:      input.MessageID,
 		Cwd:            input.Cwd,
 		TranscriptPath: input.TranscriptPath,
+		HookInputJson:  string(data),
+		Tmux:           tmuxPaneFromEnv(),
+		PermissionMode: input.PermissionMode,
+	}
+
+	// Fail fast if the server is not reachable instead of waiting for the
+	// request timeout. Nothing can be audited in that case.
+	if err := waitForReady(ctx, conn); err != nil {
+		if !isPermissionEvent(input.HookEventName) {
+			return fmt.Errorf("%s request failed: %w", input.HookEventName, err)
+		}
+		slog.Warn("permission server unreachable, applying fallback", "error", err)
+		return writeHookOutput(pbResponseToHookOutput(fallback.Decide(req, err), input.HookEventName))
+	}
+
+	// Permission events are decided by the server's prompt pipeline; all
+	// other events go through HandleHook.
+	if !isPermissionEvent(input.HookEventName) {
+		return runHook(ctx, client, req)
 	}
 
 	// Send the request
-	resp, err := client.RequestPermission(ctx, req)
+	start := time.Now()
+	resp, err := requestPermission(ctx, conn, client, req)
+
+	// Send audit event (best-effort, failures only logged to stderr).
+	// Failed requests are recorded too, along with the fallback decision.
+	event := &pb.AuditEvent{
+		Request:   req,
+		Timestamp: timestamppb.New(start),
+		Latency:   durationpb.New(time.Since(start)),
+	}
 	if err != nil {
-		return fmt.Errorf("permission request failed: %w", err)
+		event.Error = err.Error()
+		switch {
+		case server.Unavailable(err):
+			slog.Warn("permission server unavailable, applying fallback", "error", err)
+			resp = fallback.Decide(req, err)
+		case status.Code(err) == codes.DeadlineExceeded:
+			slog.Warn("permission request timed out", "error", err)
+			resp = server.BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_ASK,
+				"crabhook permission request timed out")
+		default:
+			slog.Error("permission request rejected", "error", err)
+			resp = server.BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_DENY,
+				fmt.Sprintf("crabhook permission request failed: %v", err))
+		}
 	}
+	event.Response = resp
 
 	// Convert the gRPC response to hook output
 	output := pbResponseToHookOutput(resp, input.HookEventName)
 
 	// Write the output to stdout
-	encoder := json.NewEncoder(os.Stdout)
-	if err := encoder.Encode(output); err != nil {
-		return fmt.Errorf("failed to encode hook output: %w", err)
+	if err := writeHookOutput(output); err != nil {
+		return err
+	}
+
+	sendAuditEvent(client, event)
+
+	return nil
+}
+
+// runHook sends a non-permission event through HandleHook and writes the
+// hook output the server returns, which may block the event.
+func runHook(ctx context.Context, client pb.PermissionServiceClient, req *pb.PermissionRequest) error {
+	start := time.Now()
+	resp, err := client.HandleHook(ctx, &pb.HookRequest{
+		HookEventName: req.HookEventName,
+		SessionId:     req.SessionId,
+		Cwd:           req.Cwd,
+		HookInputJson: req.HookInputJson,
+		Tmux:          req.Tmux,
+	})
+	event := &pb.AuditEvent{
+		Request:   req,
+		Timestamp: timestamppb.New(start),
+		Latency:   durationpb.New(time.Since(start)),
+	}
+	var output *model.HookOutput
+	if err == nil {
+		output, err = protoconv.HookOutputFromProto(resp.GetOutput())
+	}
+	if err != nil {
+		event.Error = err.Error()
+		sendAuditEvent(client, event)
+		return fmt.Errorf("%s request failed: %w", req.GetHookEventName(), err)
+	}
+	event.HookOutput = resp.GetOutput()
+
+	if err := writeHookOutput(*output); err != nil {
+		return err
+	}
+	sendAuditEvent(client, event)
+	return nil
+}
+
+// requestPermission sends req through permission.v2, whose request carries
+// the typed tool input. Servers that predate v2 and inputs that cannot be
+// converted fall back to v1. The response is converted back to v1.
+func requestPermission(ctx context.Context, conn *grpc.ClientConn, client pb.PermissionServiceClient, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	v2Req, err := implv2.RequestFromV1(req)
+	if err != nil {
+		slog.Debug("sending permission request over v1", "error", err)
+		return client.RequestPermission(ctx, req)
+	}
+
+	resp, err := pbv2.NewPermissionServiceClient(conn).RequestPermission(ctx, v2Req)
+	if status.Code(err) == codes.Unimplemented {
+		return client.RequestPermission(ctx, req)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return implv2.ResponseToV1(resp, req.GetHookEventName())
+}
 
-	// Send audit event (best-effort, failures only logged to stderr)
-	sendAuditEvent(client, req)
+// tmuxPaneFromEnv returns the tmux pane this hook runs in, or nil outside
+// tmux. Claude Code passes its environment on to hooks.
+func tmuxPaneFromEnv() *pb.TmuxPane {
+	socketPath, paneID, ok := tmux.PaneFromEnv()
+	if !ok {
+		return nil
+	}
+	return &pb.TmuxPane{
+		SocketPath: socketPath,
+		PaneId:     paneID,
+		Member:     os.Getenv(tmux.MemberEnv),
+	}
+}
 
+// writeHookOutput writes output to stdout as JSON.
+func writeHookOutput(output model.HookOutput) error {
+	if err := json.NewEncoder(os.Stdout).Encode(output); err != nil {
+		return fmt.Errorf("failed to encode hook output: %w", err)
+	}
 	return nil
 }
 
+// loadFallback builds the fallback from the --fallback* flags.
+func loadFallback() (*server.Fallback, error) {
+	var policy *server.Policy
+	if fallbackPolicy != "" {
+		var err error
+		if policy, err = server.LoadPolicy(fallbackPolicy); err != nil {
+			return nil, err
+		}
+	}
+	return server.NewFallback(fallbackAction, fallbackCategories, policy)
+}
+
+// waitForReady connects conn and waits until it is ready, failing as soon
+// as the connection attempt fails or connectTimeout elapses.
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("failed to connect to server %s: %s", serverAddr, state)
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("failed to connect to server %s: %w", serverAddr, ctx.Err())
+		}
+	}
+}
+
+// isPermissionEvent reports whether event is answered with a permission
+// decision. Inputs without an event name are treated as permission requests.
+func isPermissionEvent(event model.HookEventName) bool {
+	switch event {
+	case "", model.HookEventPreToolUse, model.HookEventPermissionRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+// addClientFlags registers the flags selecting and authenticating to the
+// permission server.
+func addClientFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&serverAddr, "server", "s", server.DefaultAddress(), "Permission server address (host:port or unix:///path/to.sock)")
+	flags.StringVar(&clientTLSCA, "tls-ca", "", "Connect with TLS, verifying the server against this CA certificate")
+	flags.StringVar(&clientTLSCert, "tls-cert", "", "Client certificate for mutual TLS (requires --tls-key)")
+	flags.StringVar(&clientTLSKey, "tls-key", "", "Client private key for mutual TLS")
+	flags.StringVar(&clientToken, "token-file", "", "Send the shared token read from this file")
+}
+
+// dialServer creates a client for the permission server at serverAddr.
+func dialServer() (pb.PermissionServiceClient, *grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	useTLS := clientTLSCA != "" || clientTLSCert != ""
+	if useTLS {
+		tlsConfig, err := server.ClientTLSConfig(clientTLSCA, clientTLSCert, clientTLSKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if clientToken != "" {
+		if err := server.CheckTokenTransport(serverAddr, useTLS); err != nil {
+			return nil, nil, err
+		}
+		token, err := server.LoadToken(clientToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(server.TokenCredentials(token)))
+	}
+
+	conn, err := grpc.NewClient(serverAddr, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	return pb.NewPermissionServiceClient(conn), conn, nil
+}
+
 // pbResponseToHookOutput converts a protobuf PermissionResponse to a model.HookOutput.
 func pbResponseToHookOutput(resp *pb.PermissionResponse, eventName model.HookEventName) model.HookOutput {
 	output := model.HookOutput{
@@ -126,10 +367,14 @@ func pbResponseToHookOutput(resp *pb.PermissionResponse, eventName model.HookEve
 		}
 		specific := &model.HookSpecificOutput{
 			HookEventName:           hookEventName,
-			PermissionDecision:      pbDecisionToModel(hso.PermissionDecision),
 			PermissionDecisionReason: hso.PermissionDecisionReason,
 			AdditionalContext:       hso.AdditionalContext,
 		}
+		// Only permission events carry a decision; an unspecified decision
+		// is treated as deny for them.
+		if isPermissionEvent(hookEventName) {
+			specific.PermissionDecision = pbDecisionToModel(hso.PermissionDecision)
+		}
 		if hso.UpdatedInputJson != "" {
 			specific.UpdatedInput = json.RawMessage(hso.UpdatedInputJson)
 		}
@@ -155,7 +400,7 @@ func pbDecisionToModel(d pb.PermissionDecision) model.PermissionDecision {
 
 // sendAuditEvent sends a single audit event to the server. This is best-effort;
 // failures are logged to stderr but do not affect the hook outcome.
-func sendAuditEvent(client pb.PermissionServiceClient, req *pb.PermissionRequest) {
+func sendAuditEvent(client pb.PermissionServiceClient, event *pb.AuditEvent) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -165,11 +410,6 @@ func sendAuditEvent(client pb.PermissionServiceClient, req *pb.PermissionRequest
 		return
 	}
 
-	event := &pb.AuditEvent{
-		Request:   req,
-		Timestamp: timestamppb.Now(),
-	}
-
 	if err := stream.Send(event); err != nil {
 		slog.Warn("failed to send audit event", "error", err)
 		return
@@ -1,13 +1,22 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"time"
 
 	"github.com/ngicks/crabswarm/hook/internal/server"
 	"github.com/ngicks/crabswarm/hook/internal/tui"
+	"github.com/ngicks/crabswarm/hook/internal/web"
+	"github.com/ngicks/crabswarm/hook/internal/webhook"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
+	"github.com/ngicks/crabswarm/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +26,31 @@ var (
 	auditEnable bool
 	auditOutput string
 	auditFormat string
+	policyPath  string
+	pathGuard   string
+	allowDirs   []string
+	grantsFile  string
+	historyPath string
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+	tokenFile   string
+	webAddr     string
+	tmuxSocket  string
+
+	promptTimeout    string
+	promptTimeoutFor map[string]string
+
+	notifyVia      []string
+	notifyCommand  string
+	notifyInterval time.Duration
+	quietHours     string
+
+	webhookURL       string
+	webhookFormat    string
+	webhookListen    string
+	webhookPublicURL string
+	webhookLinkTTL   time.Duration
 )
 
 // serveCmd is the serve subcommand for running the interactive permission server.
@@ -29,16 +63,124 @@ from crabhook clients.
 The server displays permission requests in a TUI and prompts for user input.
 Run this in a separate terminal before using Claude Code with hooks configured.
 
-Use --plain for a non-interactive plain text mode (no TUI).`,
+Use --plain for a non-interactive plain text mode (no TUI).
+
+Use --web host:port to also answer requests from a browser (e.g. on a
+phone): it serves a small page listing pending requests with their tool
+input, AskUserQuestion forms and ExitPlanMode plans, updated live. Requests
+are shown in both the TUI and the page; the first answer wins and the
+request is withdrawn from the other. With --plain, only the page prompts.
+The page is served over plain HTTP, on localhost unless --web names a
+host. Its API requires a random token generated at startup, separate from
+--token-file since it is sent in cleartext. Open the page as
+http://host:port/?token=<token>; the URL is logged at startup.
+
+The TUI lists pending requests grouped by Claude session, the session
+blocked longest first. Answer them in any order: tab and shift+tab switch
+between requests, ctrl+o jumps to the one waiting longest.
+
+Sessions that finished or wait for input (Stop and idle Notification hook
+events) can be answered with ctrl+r: the follow-up prompt is typed into the
+session's tmux pane with send-keys. This requires crabhook to be configured
+for those events too and Claude Code to run as a swarm member ('crabswarm
+spawn' does both). Replies are only typed into member panes of the swarm's
+tmux server: the default one, or the socket named by --tmux-socket (as
+'crabswarm -L').
+
+By default the server listens on a Unix socket under $XDG_RUNTIME_DIR
+(mode 0600, so only your user can connect), falling back to localhost:50051
+if it is not set. Use --listen with host:port or unix:///path/to.sock to
+change it; clients take the same address with --server. The directory of a
+socket must be owned by you with mode 0700.
+
+To keep other local users or processes from answering or injecting requests,
+use TLS (--tls-cert/--tls-key, plus --tls-client-ca to require client
+certificates; 'crabhook certs init' generates a local CA and key pairs)
+and/or --token-file to require a shared token on every RPC. Clients take
+--tls-ca, --tls-cert, --tls-key and --token-file accordingly. A token is
+only sent in cleartext over a Unix socket or a loopback address; elsewhere
+it requires TLS.
+
+Use --policy to load a YAML or TOML rule file. Rules are evaluated in order
+before prompting; the first matching rule decides. Requests matching no rule
+are prompted as usual.
+
+File tool requests (Read, Write, Edit, Glob, Grep, NotebookEdit) are checked
+against the workspace (the session's cwd) and any --allow-dir directories,
+following symlinks. Writes outside them and accesses to sensitive paths
+(.git, ~/.ssh, .env*) are handled by --path-guard: "prompt" always asks you
+even if a policy rule would allow them, "deny" and "ask" decide automatically,
+and "off" disables the check.
+
+The TUI offers "remember this decision" choices (this session, this exact
+input in this project, this tool for 30 minutes); Bash is only remembered
+per exact command. Grants answer requests that would otherwise be prompted,
+never overriding a policy rule. They are kept in memory, or in --grants-file
+if set, and can be reviewed with ctrl+g or 'crabhook grants'.
+
+Use --prompt-timeout to decide requests nobody answers in time, e.g.
+"5m:deny" (the action is allow, deny or ask; deny if omitted), and
+--prompt-timeout-for to override it per tool or tool category, e.g.
+Bash=2m:deny,file_path=10s:ask. The prompt counts down to the deadline and
+the decision is recorded with the "timeout" source. AskUserQuestion and
+ExitPlanMode are never allowed by timeout: an allow rule defers them to
+Claude Code's own prompt (ask) instead. Keep timeouts below the client's
+--timeout (5m by default), after which the hook gives up anyway.
+
+Use --webhook URL to post waiting requests to a webhook ("generic" JSON, or
+"slack", "ntfy" or "gotify" with --webhook-format) with signed one-time
+approve/deny links. The links are served on --webhook-listen and must be
+reachable under --webhook-public-url from wherever you open them; opening a
+link shows a confirmation page, so chat link previews cannot decide.
+AskUserQuestion and ExitPlanMode only get a deny link: answer them in the
+TUI or with --web. Like --web, the webhook is asked along with the TUI and
+the first answer wins.
+
+Use --notify to be alerted when a request starts waiting for you: "bell"
+rings the terminal bell, "osc9" and "osc777" post a desktop notification
+through the terminal, and "desktop" runs notify-send. --notify-command runs
+a shell command with the title and message as $1 and $2. Notifications are
+sent at most once per --notify-interval and not during --quiet-hours
+(e.g. 22:00-07:00). Inside tmux, "osc9" and "osc777" are passed through to
+the outer terminal, which requires 'set -g allow-passthrough on' in
+tmux.conf.
+
+Use --history to record every request with its decision, reason, latency and
+decision source in an embedded database. Requests are recorded by the server
+as they are decided, including the front-end that answered (tui, web or
+webhook) and requests abandoned by their hook. Query it with 'crabhook
+history'; the TUI log panel is restored from it on startup.`,
 	RunE: runServer,
 }
 
 func init() {
-	serveCmd.Flags().StringVarP(&listenAddr, "listen", "l", "localhost:50051", "Address to listen on")
+	serveCmd.Flags().StringVarP(&listenAddr, "listen", "l", server.DefaultAddress(), "Address to listen on (host:port or unix:///path/to.sock)")
 	serveCmd.Flags().BoolVar(&plainMode, "plain", false, "Use plain text prompts instead of TUI")
 	serveCmd.Flags().BoolVar(&auditEnable, "audit-enable", false, "Enable audit logging of hook events")
 	serveCmd.Flags().StringVar(&auditOutput, "audit-output", "stderr", "Audit output destination: \"stderr\" or a file path")
 	serveCmd.Flags().StringVar(&auditFormat, "audit-format", "text", "Audit output format: \"text\" or \"json\"")
+	serveCmd.Flags().StringVar(&policyPath, "policy", "", "Path to a YAML or TOML policy rule file")
+	serveCmd.Flags().StringVar(&pathGuard, "path-guard", "prompt", "Action for writes outside the workspace and sensitive paths: \"prompt\", \"deny\", \"ask\", or \"off\"")
+	serveCmd.Flags().StringSliceVar(&allowDirs, "allow-dir", nil, "Extra directory treated like the workspace by the path guard (repeatable)")
+	serveCmd.Flags().StringVar(&grantsFile, "grants-file", "", "Persist remembered grants to this JSON file")
+	serveCmd.Flags().StringVar(&historyPath, "history", "", "Record decision history in this database file")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Serve TLS with this certificate (requires --tls-key)")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "Private key for --tls-cert")
+	serveCmd.Flags().StringVar(&tlsClientCA, "tls-client-ca", "", "Require client certificates signed by this CA (mutual TLS)")
+	serveCmd.Flags().StringVar(&tokenFile, "token-file", "", "Require the shared token read from this file on every RPC")
+	serveCmd.Flags().StringVar(&promptTimeout, "prompt-timeout", "off", "Decide unanswered requests after this long, as <duration>[:allow|deny|ask]")
+	serveCmd.Flags().StringToStringVar(&promptTimeoutFor, "prompt-timeout-for", nil, "Per tool or tool category prompt timeout, e.g. Bash=2m:deny,file_path=10s:ask")
+	serveCmd.Flags().StringSliceVar(&notifyVia, "notify", nil, "Notify of waiting requests: bell, osc9, osc777 or desktop (repeatable)")
+	serveCmd.Flags().StringVar(&notifyCommand, "notify-command", "", "Shell command run for each notification, with the title and message as $1 and $2")
+	serveCmd.Flags().DurationVar(&notifyInterval, "notify-interval", 10*time.Second, "Minimum time between notifications")
+	serveCmd.Flags().StringVar(&quietHours, "quiet-hours", "", "Suppress notifications during this daily time range, e.g. 22:00-07:00")
+	serveCmd.Flags().StringVar(&webhookURL, "webhook", "", "Post waiting requests with approve/deny links to this webhook URL")
+	serveCmd.Flags().StringVar(&webhookFormat, "webhook-format", string(webhook.FormatGeneric), "Webhook payload: generic, slack, ntfy or gotify")
+	serveCmd.Flags().StringVar(&webhookListen, "webhook-listen", "localhost:8090", "Address serving the webhook approve/deny links")
+	serveCmd.Flags().StringVar(&webhookPublicURL, "webhook-public-url", "", "Base URL of --webhook-listen as reachable from your phone (default http://<webhook-listen>)")
+	serveCmd.Flags().DurationVar(&webhookLinkTTL, "webhook-link-ttl", webhook.DefaultLinkTTL, "Validity of webhook approve/deny links")
+	serveCmd.Flags().StringVar(&tmuxSocket, "tmux-socket", "", "tmux server socket name of the swarm (tmux -L)")
+	serveCmd.Flags().StringVar(&webAddr, "web", "", "Also answer requests from a web page served on this address (host:port)")
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -48,6 +190,65 @@ func runServer(cmd *cobra.Command, args []string) error {
 		Address: listenAddr,
 	}
 
+	if tlsCert != "" || tlsKey != "" || tlsClientCA != "" {
+		if tlsCert == "" || tlsKey == "" {
+			return fmt.Errorf("--tls-cert and --tls-key are required for TLS")
+		}
+		tlsConfig, err := server.ServerTLSConfig(tlsCert, tlsKey, tlsClientCA)
+		if err != nil {
+			return err
+		}
+		cfg.TLS = tlsConfig
+	}
+
+	if tokenFile != "" {
+		if err := server.CheckTokenTransport(listenAddr, cfg.TLS != nil); err != nil {
+			return err
+		}
+		token, err := server.LoadToken(tokenFile)
+		if err != nil {
+			return err
+		}
+		cfg.Token = token
+	}
+
+	if policyPath != "" {
+		policy, err := server.LoadPolicy(policyPath)
+		if err != nil {
+			return err
+		}
+		cfg.Policy = policy
+	}
+
+	timeouts, err := server.NewPromptTimeouts(promptTimeout, promptTimeoutFor)
+	if err != nil {
+		return err
+	}
+	cfg.Timeouts = timeouts
+
+	if len(notifyVia) > 0 || notifyCommand != "" {
+		notifications, err := createNotifications()
+		if err != nil {
+			return err
+		}
+		cfg.Notifier = notifications
+	}
+
+	var tuiOpts []tui.Option
+	if pathGuard != "off" {
+		guard := pathguard.New(allowDirs...)
+		cfg.PathGuard = guard
+		cfg.PathGuardAction = server.RuleAction(pathGuard)
+		tuiOpts = append(tuiOpts, tui.WithPathGuard(guard))
+	}
+
+	grants, err := server.NewGrantStore(grantsFile)
+	if err != nil {
+		return err
+	}
+	cfg.Grants = grants
+	tuiOpts = append(tuiOpts, tui.WithGrants(grants), tui.WithTmux(&tmux.Client{SocketName: tmuxSocket}))
+
 	// Create base audit handler (file/slog) if enabled.
 	var closer io.Closer
 	if auditEnable {
@@ -62,16 +263,59 @@ func runServer(cmd *cobra.Command, args []string) error {
 		defer closer.Close()
 	}
 
-	if plainMode {
+	if historyPath != "" {
+		history, err := server.OpenHistoryStore(historyPath)
+		if err != nil {
+			return err
+		}
+		defer history.Close()
+		cfg.History = history
+		tuiOpts = append(tuiOpts, tui.WithHistory(history))
+	}
+
+	// Front-ends answered over HTTP, asked along with the TUI.
+	var remote []server.Prompter
+	var frontends []httpFrontend
+	if webAddr != "" {
+		// The page's token travels in URLs over plain HTTP, so it must not
+		// be the RPC token.
+		webPrompter := web.New()
+		remote = append(remote, webPrompter)
+		frontends = append(frontends, httpFrontend{
+			name:    "web UI",
+			addr:    loopbackIfNoHost(webAddr),
+			token:   webPrompter.Token(),
+			handler: webPrompter.Handler(),
+		})
+	}
+	if webhookURL != "" {
+		hookPrompter, err := createWebhookPrompter()
+		if err != nil {
+			return err
+		}
+		remote = append(remote, hookPrompter)
+		frontends = append(frontends, httpFrontend{name: "webhook reply endpoint", addr: webhookListen, handler: hookPrompter.Handler()})
+	}
+
+	switch {
+	case plainMode && len(remote) > 0:
+		// The plain prompter cannot withdraw a prompt from stdin, so it
+		// does not take part in the fan-out.
+		cfg.Prompter = server.NewMultiPrompter(remote...)
+	case plainMode:
 		cfg.Reader = cmd.InOrStdin()
 		cfg.Writer = cmd.OutOrStdout()
-	} else {
-		prompter, program := tui.New()
+	default:
+		prompter, program := tui.New(tuiOpts...)
 		cfg.Prompter = prompter
+		if len(remote) > 0 {
+			cfg.Prompter = server.NewMultiPrompter(append([]server.Prompter{prompter}, remote...)...)
+		}
 		cfg.Program = program
 		// Always wrap with TUIAuditHandler in TUI mode so audit events
 		// appear in the log panel regardless of --audit-enable.
 		cfg.AuditHandler = tui.NewTUIAuditHandler(program, cfg.AuditHandler)
+		cfg.HookHandler = tui.NewTUIHookHandler(program, nil)
 	}
 
 	srv, err := server.New(cfg)
@@ -79,6 +323,15 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create server: %w", err)
 	}
 
+	for _, f := range frontends {
+		httpServer, err := f.start(srv)
+		if err != nil {
+			srv.Stop()
+			return err
+		}
+		defer httpServer.Close()
+	}
+
 	if plainMode {
 		slog.Info("permission server started", "address", srv.Address())
 		slog.Info("waiting for permission requests")
@@ -92,6 +345,92 @@ func runServer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// httpFrontend is a Prompter's HTTP handler served next to the gRPC server.
+type httpFrontend struct {
+	name    string
+	addr    string
+	token   string // added to the logged URL, if set
+	handler http.Handler
+}
+
+// loopbackIfNoHost binds addr to localhost if it has no host part, e.g.
+// ":8080", instead of every interface.
+func loopbackIfNoHost(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
+
+// start serves f in the background, stopping srv if serving fails.
+func (f httpFrontend) start(srv *server.Server) (*http.Server, error) {
+	ln, err := net.Listen("tcp", f.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s for the %s: %w", f.addr, f.name, err)
+	}
+	httpServer := &http.Server{Handler: f.handler}
+	go func() {
+		if err := httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(f.name+" stopped", "error", err)
+			srv.Stop()
+		}
+	}()
+	link := "http://" + ln.Addr().String() + "/"
+	if f.token != "" {
+		link += "?token=" + url.QueryEscape(f.token)
+	}
+	slog.Info(f.name+" started", "url", link)
+	return httpServer, nil
+}
+
+// createWebhookPrompter creates the webhook Prompter from the --webhook flags.
+func createWebhookPrompter() (*webhook.Prompter, error) {
+	format, err := webhook.ParseFormat(webhookFormat)
+	if err != nil {
+		return nil, err
+	}
+	publicURL := webhookPublicURL
+	if publicURL == "" {
+		publicURL = "http://" + webhookListen
+	}
+	return webhook.New(webhook.Config{
+		URL:       webhookURL,
+		Format:    format,
+		PublicURL: publicURL,
+		LinkTTL:   webhookLinkTTL,
+	})
+}
+
+// createNotifications creates the notifiers selected by the --notify flags.
+func createNotifications() (*server.Notifications, error) {
+	var notifiers []server.Notifier
+	for _, via := range notifyVia {
+		switch style := server.TerminalStyle(via); style {
+		case server.TerminalBell, server.TerminalOSC9, server.TerminalOSC777:
+			notifiers = append(notifiers, &server.TerminalNotifier{W: os.Stderr, Style: style, Tmux: os.Getenv("TMUX") != ""})
+		case "desktop":
+			notifiers = append(notifiers, server.NewDesktopNotifier())
+		default:
+			return nil, fmt.Errorf("invalid --notify %q: must be \"bell\", \"osc9\", \"osc777\" or \"desktop\"", via)
+		}
+	}
+	if notifyCommand != "" {
+		notifiers = append(notifiers, server.NewShellNotifier(notifyCommand))
+	}
+
+	notifications := server.NewNotifications(notifiers...)
+	notifications.MinInterval = notifyInterval
+	if quietHours != "" {
+		quiet, err := server.ParseQuietHours(quietHours)
+		if err != nil {
+			return nil, err
+		}
+		notifications.Quiet = quiet
+	}
+	return notifications, nil
+}
+
 // createAuditHandler creates a LogAuditHandler based on the audit flags.
 // It returns the handler, an optional io.Closer for the underlying file (nil for stderr), and an error.
 func createAuditHandler() (*server.LogAuditHandler, io.Closer, error) {
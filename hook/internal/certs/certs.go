@@ -0,0 +1,199 @@
+// Package certs generates a local certificate authority and the
+// server/client key pairs used for mutual TLS between crabhook clients and
+// the permission server.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Names of the files written by Generate.
+const (
+	CACertFile     = "ca.crt"
+	CAKeyFile      = "ca.key"
+	ServerCertFile = "server.crt"
+	ServerKeyFile  = "server.key"
+	ClientCertFile = "client.crt"
+	ClientKeyFile  = "client.key"
+)
+
+// DefaultHosts are the names the server certificate is valid for by
+// default. gRPC uses "localhost" as the authority of Unix socket targets.
+var DefaultHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// DefaultValidity is the default lifetime of generated certificates.
+const DefaultValidity = 365 * 24 * time.Hour
+
+// Options configures Generate.
+type Options struct {
+	// Hosts are the DNS names and IP addresses of the server certificate.
+	// Defaults to DefaultHosts.
+	Hosts []string
+	// Validity is the certificate lifetime. Defaults to DefaultValidity.
+	Validity time.Duration
+	// Force overwrites existing files.
+	Force bool
+}
+
+// DefaultDir returns the default certificate directory,
+// $XDG_CONFIG_HOME/crabhook/certs (or the platform equivalent).
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "crabhook", "certs"), nil
+}
+
+// Generate creates a CA and server and client key pairs signed by it in dir.
+// Private keys are written with mode 0600. Unless opts.Force is set, it
+// fails if any of the files already exists.
+func Generate(dir string, opts Options) error {
+	if len(opts.Hosts) == 0 {
+		opts.Hosts = DefaultHosts
+	}
+	if opts.Validity <= 0 {
+		opts.Validity = DefaultValidity
+	}
+
+	if !opts.Force {
+		for _, name := range []string{CACertFile, CAKeyFile, ServerCertFile, ServerKeyFile, ClientCertFile, ClientKeyFile} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return fmt.Errorf("%s already exists in %s", name, dir)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to stat %s: %w", name, err)
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+
+	notBefore := time.Now().Add(-time.Minute)
+	notAfter := notBefore.Add(opts.Validity)
+
+	caTmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "crabhook local CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caCert, caKey, err := issue(caTmpl, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := writePair(dir, CACertFile, CAKeyFile, caCert, caKey); err != nil {
+		return err
+	}
+
+	serverTmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "crabhook server"},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range opts.Hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			serverTmpl.IPAddresses = append(serverTmpl.IPAddresses, ip)
+		} else {
+			serverTmpl.DNSNames = append(serverTmpl.DNSNames, h)
+		}
+	}
+	if err := issueAndWrite(dir, ServerCertFile, ServerKeyFile, serverTmpl, caCert, caKey); err != nil {
+		return err
+	}
+
+	clientTmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "crabhook client"},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	return issueAndWrite(dir, ClientCertFile, ClientKeyFile, clientTmpl, caCert, caKey)
+}
+
+func issueAndWrite(dir, certName, keyName string, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) error {
+	cert, key, err := issue(tmpl, parent, parentKey)
+	if err != nil {
+		return err
+	}
+	return writePair(dir, certName, keyName, cert, key)
+}
+
+// issue creates a key and a certificate from tmpl signed by parent, or a
+// self-signed one if parent is nil.
+func issue(tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	tmpl.SerialNumber = serial
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create %q certificate: %w", tmpl.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, key, nil
+}
+
+func writePair(dir, certName, keyName string, cert *x509.Certificate, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode key: %w", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := os.WriteFile(filepath.Join(dir, certName), certPEM, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", certName, err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if err := writeKey(filepath.Join(dir, keyName), keyPEM); err != nil {
+		return fmt.Errorf("failed to write %s: %w", keyName, err)
+	}
+	return nil
+}
+
+// writeKey writes a private key to a new file with mode 0600. An existing
+// file (overwritten with Force) is removed first: os.WriteFile would keep
+// its mode, which may let others read the new key.
+func writeKey(path string, data []byte) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
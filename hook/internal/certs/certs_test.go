@@ -0,0 +1,89 @@
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readCert(t *testing.T, path string) *x509.Certificate {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		t.Fatalf("no PEM block in %s", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	if err := Generate(dir, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ca := readCert(t, filepath.Join(dir, CACertFile))
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	tests := []struct {
+		file    string
+		usage   x509.ExtKeyUsage
+		dnsName string
+	}{
+		{ServerCertFile, x509.ExtKeyUsageServerAuth, "localhost"},
+		{ClientCertFile, x509.ExtKeyUsageClientAuth, ""},
+	}
+	for _, tt := range tests {
+		cert := readCert(t, filepath.Join(dir, tt.file))
+		_, err := cert.Verify(x509.VerifyOptions{
+			Roots:     roots,
+			DNSName:   tt.dnsName,
+			KeyUsages: []x509.ExtKeyUsage{tt.usage},
+		})
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+		}
+	}
+
+	for _, name := range []string{CAKeyFile, ServerKeyFile, ClientKeyFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s mode = %o, want 600", name, perm)
+		}
+	}
+
+	if err := Generate(dir, Options{}); err == nil {
+		t.Error("expected error when files exist")
+	}
+	// Force replaces keys whose mode was loosened with fresh 0600 files.
+	for _, name := range []string{CAKeyFile, ServerKeyFile, ClientKeyFile} {
+		if err := os.Chmod(filepath.Join(dir, name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Generate(dir, Options{Force: true}); err != nil {
+		t.Errorf("Generate with Force: %v", err)
+	}
+	for _, name := range []string{CAKeyFile, ServerKeyFile, ClientKeyFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s mode after Force = %o, want 600", name, perm)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package diffview renders the changes requested by file editing tools
+// (Edit, Write, NotebookEdit) as unified diffs.
+package diffview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of a diff line.
+type Op byte
+
+const (
+	// Equal is a context line present on both sides.
+	Equal Op = ' '
+	// Delete is a line only present in the old text.
+	Delete Op = '-'
+	// Insert is a line only present in the new text.
+	Insert Op = '+'
+)
+
+// Line is a single line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// String formats l as in a unified diff.
+func (l Line) String() string {
+	return string(l.Op) + l.Text
+}
+
+// maxCells bounds the size of the LCS table. Larger changes are shown as a
+// deletion of the old lines followed by an insertion of the new ones.
+const maxCells = 4_000_000
+
+// Lines returns the line diff turning a into b.
+func Lines(a, b []string) []Line {
+	// Edits are usually local: only the middle needs the LCS table.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]Line, 0, len(a)+len(b))
+	for _, s := range a[:prefix] {
+		out = append(out, Line{Equal, s})
+	}
+	out = append(out, lcsDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, s := range a[len(a)-suffix:] {
+		out = append(out, Line{Equal, s})
+	}
+	return out
+}
+
+// lcsDiff diffs a and b through their longest common subsequence.
+func lcsDiff(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n*m > maxCells {
+		out := make([]Line, 0, n+m)
+		for _, s := range a {
+			out = append(out, Line{Delete, s})
+		}
+		for _, s := range b {
+			out = append(out, Line{Insert, s})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	out := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, Line{Equal, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, Line{Delete, a[i]})
+			i++
+		default:
+			out = append(out, Line{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, Line{Delete, a[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, Line{Insert, b[j]})
+	}
+	return out
+}
+
+// Hunk is a group of changed lines with their context.
+type Hunk struct {
+	// OldStart and NewStart are 1-based line numbers.
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Header returns the "@@ -l,s +l,s @@" line of h.
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// Unified diffs old and new and groups the changes into hunks with up to
+// context unchanged lines around them.
+func Unified(old, new string, context int) []Hunk {
+	lines := Lines(SplitLines(old), SplitLines(new))
+
+	// oldNo[i] and newNo[i] are the line numbers at lines[i].
+	oldNo := make([]int, len(lines))
+	newNo := make([]int, len(lines))
+	o, n := 1, 1
+	var changes []int
+	for i, l := range lines {
+		oldNo[i], newNo[i] = o, n
+		if l.Op != Insert {
+			o++
+		}
+		if l.Op != Delete {
+			n++
+		}
+		if l.Op != Equal {
+			changes = append(changes, i)
+		}
+	}
+
+	var hunks []Hunk
+	for k := 0; k < len(changes); {
+		// Changes closer than twice the context share a hunk.
+		first, last := changes[k], changes[k]
+		for k++; k < len(changes) && changes[k]-last <= 2*context; k++ {
+			last = changes[k]
+		}
+		start := max(first-context, 0)
+		end := min(last+context+1, len(lines))
+
+		h := Hunk{OldStart: oldNo[start], NewStart: newNo[start], Lines: lines[start:end]}
+		for _, l := range h.Lines {
+			if l.Op != Insert {
+				h.OldLines++
+			}
+			if l.Op != Delete {
+				h.NewLines++
+			}
+		}
+		// An empty side refers to the line before the change.
+		if h.OldLines == 0 {
+			h.OldStart--
+		}
+		if h.NewLines == 0 {
+			h.NewStart--
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+// SplitLines splits s into lines without their terminators. A trailing
+// newline does not start an empty line.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
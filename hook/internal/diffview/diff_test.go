@@ -0,0 +1,70 @@
+package diffview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	got := Lines(
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "x", "c", "d", "e"},
+	)
+	var ops []string
+	for _, l := range got {
+		ops = append(ops, l.String())
+	}
+	want := []string{" a", "-b", "+x", " c", " d", "+e"}
+	if strings.Join(ops, ",") != strings.Join(want, ",") {
+		t.Errorf("Lines() = %q, want %q", ops, want)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	var old []string
+	for i := range 20 {
+		old = append(old, string(rune('a'+i)))
+	}
+	changed := append([]string(nil), old...)
+	changed[1] = "B"
+	changed[3] = "D"  // within 2*context of line 2: same hunk
+	changed[15] = "P" // far away: own hunk
+
+	hunks := Unified(strings.Join(old, "\n")+"\n", strings.Join(changed, "\n")+"\n", 3)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+	if got := hunks[0].Header(); got != "@@ -1,7 +1,7 @@" {
+		t.Errorf("hunk 0 header = %q", got)
+	}
+	if got := hunks[1].Header(); got != "@@ -13,7 +13,7 @@" {
+		t.Errorf("hunk 1 header = %q", got)
+	}
+
+	if hunks := Unified("same\n", "same\n", 3); len(hunks) != 0 {
+		t.Errorf("identical texts gave %d hunks", len(hunks))
+	}
+
+	// New file: everything is inserted.
+	hunks = Unified("", "x\ny\n", 3)
+	if len(hunks) != 1 || hunks[0].Header() != "@@ -0,0 +1,2 @@" {
+		t.Errorf("new file hunks = %+v", hunks)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"a\n", 1},
+		{"a\n\n", 2},
+		{"a\nb", 2},
+	} {
+		if got := SplitLines(tc.in); len(got) != tc.want {
+			t.Errorf("SplitLines(%q) = %q, want %d lines", tc.in, got, tc.want)
+		}
+	}
+}
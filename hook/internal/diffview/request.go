@@ -0,0 +1,268 @@
+package diffview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// contextLines is the number of unchanged lines shown around changes.
+const contextLines = 3
+
+// FileDiff is the change a tool request makes to a file.
+type FileDiff struct {
+	// Path is the file as given in the request.
+	Path string
+	// Summary describes the change, e.g. "new file" or "replace all (3
+	// occurrences)". May be empty.
+	Summary string
+	Hunks   []Hunk
+}
+
+// ForRequest returns the diff of an Edit, Write or NotebookEdit request. The
+// current file content is read from disk (relative paths are resolved
+// against the request's cwd) to show the change in place; if that fails,
+// the diff is built from the request alone.
+func ForRequest(req *pb.PermissionRequest) (FileDiff, bool) {
+	input := []byte(req.GetToolInputJson())
+	switch model.ToolName(req.GetToolName()) {
+	case model.ToolNameEdit:
+		var in model.EditInput
+		if err := json.Unmarshal(input, &in); err != nil || in.FilePath == "" {
+			return FileDiff{}, false
+		}
+		return editDiff(in, resolve(req.GetCwd(), in.FilePath)), true
+	case model.ToolNameWrite:
+		var in model.WriteInput
+		if err := json.Unmarshal(input, &in); err != nil || in.FilePath == "" {
+			return FileDiff{}, false
+		}
+		return writeDiff(in, resolve(req.GetCwd(), in.FilePath)), true
+	case model.ToolNameNotebookEdit:
+		var in model.NotebookEditInput
+		if err := json.Unmarshal(input, &in); err != nil || in.NotebookPath == "" {
+			return FileDiff{}, false
+		}
+		return notebookDiff(in, resolve(req.GetCwd(), in.NotebookPath)), true
+	}
+	return FileDiff{}, false
+}
+
+func resolve(cwd, path string) string {
+	if filepath.IsAbs(path) || cwd == "" {
+		return path
+	}
+	return filepath.Join(cwd, path)
+}
+
+func editDiff(in model.EditInput, path string) FileDiff {
+	d := FileDiff{Path: in.FilePath}
+	content, err := os.ReadFile(path)
+	count := 0
+	if err == nil && in.OldString != "" {
+		count = strings.Count(string(content), in.OldString)
+	}
+
+	switch {
+	case count == 0:
+		// Without the file, show the strings themselves.
+		if err == nil {
+			d.Summary = "old_string not found in file"
+		} else if in.ReplaceAll {
+			d.Summary = "replace all"
+		}
+		d.Hunks = Unified(in.OldString, in.NewString, contextLines)
+	case in.ReplaceAll:
+		d.Summary = fmt.Sprintf("replace all (%d occurrences)", count)
+		d.Hunks = Unified(string(content), strings.ReplaceAll(string(content), in.OldString, in.NewString), contextLines)
+	default:
+		d.Hunks = Unified(string(content), strings.Replace(string(content), in.OldString, in.NewString, 1), contextLines)
+	}
+	return d
+}
+
+func writeDiff(in model.WriteInput, path string) FileDiff {
+	d := FileDiff{Path: in.FilePath}
+	content, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		d.Summary = "new file"
+	case err != nil:
+		d.Summary = "current content unavailable"
+	case string(content) == in.Content:
+		d.Summary = "no changes"
+	default:
+		d.Summary = "overwrite"
+	}
+	d.Hunks = Unified(string(content), in.Content, contextLines)
+	return d
+}
+
+// notebook is the part of an .ipynb file needed to find cells.
+type notebook struct {
+	Cells []struct {
+		ID       string          `json:"id"`
+		CellType string          `json:"cell_type"`
+		Source   json.RawMessage `json:"source"`
+	} `json:"cells"`
+}
+
+// cellSource decodes a cell source, stored as a string or a list of lines.
+func cellSource(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err == nil {
+		return strings.Join(lines, "")
+	}
+	return ""
+}
+
+func notebookDiff(in model.NotebookEditInput, path string) FileDiff {
+	mode := in.EditMode
+	if mode == "" {
+		mode = "replace"
+	}
+	cell := in.CellID
+	if cell == "" {
+		cell = "(first)"
+	}
+	d := FileDiff{Path: in.NotebookPath, Summary: fmt.Sprintf("%s cell %s", mode, cell)}
+	if in.CellType != "" {
+		d.Summary += " (" + in.CellType + ")"
+	}
+
+	var old string
+	if mode != "insert" {
+		if src, ok := findCell(path, in.CellID); ok {
+			old = src
+		} else {
+			d.Summary += ", current cell unavailable"
+		}
+	}
+	newSource := in.NewSource
+	if mode == "delete" {
+		newSource = ""
+	}
+	d.Hunks = Unified(old, newSource, contextLines)
+	return d
+}
+
+// findCell returns the source of the cell with id in the notebook at path.
+// An id of the form "cell-N" also selects the N-th cell (0-based), and an
+// empty id the first one.
+func findCell(path, id string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var nb notebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", false
+	}
+	for _, c := range nb.Cells {
+		if c.ID != "" && c.ID == id {
+			return cellSource(c.Source), true
+		}
+	}
+	index := 0
+	if id != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(id, "cell-"))
+		if err != nil || !strings.HasPrefix(id, "cell-") {
+			return "", false
+		}
+		index = n
+	}
+	if index < 0 || index >= len(nb.Cells) {
+		return "", false
+	}
+	return cellSource(nb.Cells[index].Source), true
+}
+
+// Text renders d as plain unified diff text, showing at most maxLines diff
+// lines (0 means no limit).
+func (d FileDiff) Text(maxLines int) string {
+	return d.Render(maxLines, nil)
+}
+
+// Render renders d like Text, passing each line through style, which gets
+// the line's Op ('@' for hunk headers). A nil style leaves lines unchanged.
+// Control characters in the file content and the request are made visible
+// (see Visible) before styling, so the diff cannot drive the terminal.
+func (d FileDiff) Render(maxLines int, style func(op Op, line string) string) string {
+	if style == nil {
+		style = func(_ Op, line string) string { return line }
+	}
+	unstyled := style
+	style = func(op Op, line string) string { return unstyled(op, Visible(line)) }
+	var b strings.Builder
+	header := d.Path
+	if d.Summary != "" {
+		header += " (" + d.Summary + ")"
+	}
+	b.WriteString(style('@', "--- "+header) + "\n")
+
+	shown := 0
+	total := 0
+	for _, h := range d.Hunks {
+		total += len(h.Lines)
+	}
+	if total == 0 {
+		b.WriteString(style(Equal, " (no changes)") + "\n")
+		return b.String()
+	}
+	for _, h := range d.Hunks {
+		if maxLines > 0 && shown >= maxLines {
+			break
+		}
+		b.WriteString(style('@', h.Header()) + "\n")
+		for _, l := range h.Lines {
+			if maxLines > 0 && shown >= maxLines {
+				break
+			}
+			b.WriteString(style(l.Op, l.String()) + "\n")
+			shown++
+		}
+	}
+	if shown < total {
+		b.WriteString(style('@', fmt.Sprintf("... %d more lines", total-shown)) + "\n")
+	}
+	return b.String()
+}
+
+// Visible replaces the control characters in s, except tabs, with a
+// printable form: C0 controls and DEL in caret notation (ESC becomes "^["),
+// other controls and invalid UTF-8 bytes as Go escapes ("\u009b", "\xff").
+func Visible(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == utf8.RuneError && !strings.HasPrefix(s[i:], string(utf8.RuneError)):
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\t':
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte('^')
+			b.WriteRune(r + '@')
+		case r == 0x7f:
+			b.WriteString("^?")
+		case unicode.IsControl(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,173 @@
+package diffview
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+func request(t *testing.T, tool, cwd string, input any) *pb.PermissionRequest {
+	t.Helper()
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pb.PermissionRequest{ToolName: tool, ToolInputJson: string(data), Cwd: cwd}
+}
+
+func TestForRequest_Edit(t *testing.T) {
+	dir := t.TempDir()
+	content := "package main\n\nfunc a() { foo() }\n\nfunc b() { foo() }\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("in file", func(t *testing.T) {
+		d, ok := ForRequest(request(t, "Edit", dir, map[string]any{
+			"file_path": "main.go", "old_string": "foo()", "new_string": "bar()",
+		}))
+		if !ok {
+			t.Fatal("ForRequest() = false")
+		}
+		text := d.Text(0)
+		// Only the first occurrence is replaced, shown at its line number.
+		if !strings.Contains(text, "@@ -1,5 +1,5 @@\n") ||
+			!strings.Contains(text, "-func a() { foo() }\n+func a() { bar() }\n") ||
+			strings.Contains(text, "+func b()") {
+			t.Errorf("diff =\n%s", text)
+		}
+	})
+
+	t.Run("replace all", func(t *testing.T) {
+		d, _ := ForRequest(request(t, "Edit", dir, map[string]any{
+			"file_path": filepath.Join(dir, "main.go"), "old_string": "foo()", "new_string": "bar()", "replace_all": true,
+		}))
+		text := d.Text(0)
+		if d.Summary != "replace all (2 occurrences)" || !strings.Contains(text, "+func b() { bar() }") {
+			t.Errorf("diff =\n%s", text)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		d, _ := ForRequest(request(t, "Edit", dir, map[string]any{
+			"file_path": "gone.go", "old_string": "x := 1", "new_string": "x := 2",
+		}))
+		if text := d.Text(0); !strings.Contains(text, "-x := 1\n+x := 2\n") {
+			t.Errorf("diff =\n%s", text)
+		}
+	})
+}
+
+func TestForRequest_Write(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+
+	d, _ := ForRequest(request(t, "Write", dir, map[string]any{"file_path": path, "content": "one\ntwo\n"}))
+	if d.Summary != "new file" || !strings.Contains(d.Text(0), "+one\n+two\n") {
+		t.Errorf("new file diff =\n%s", d.Text(0))
+	}
+
+	if err := os.WriteFile(path, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	d, _ = ForRequest(request(t, "Write", dir, map[string]any{"file_path": path, "content": "one\ntwo\n"}))
+	if d.Summary != "overwrite" || !strings.Contains(d.Text(0), " one\n+two\n") {
+		t.Errorf("overwrite diff =\n%s", d.Text(0))
+	}
+}
+
+func TestForRequest_NotebookEdit(t *testing.T) {
+	dir := t.TempDir()
+	nb := `{"cells":[
+		{"id":"intro","cell_type":"markdown","source":"# Title\n"},
+		{"id":"calc","cell_type":"code","source":["x = 1\n","print(x)\n"]}
+	]}`
+	path := filepath.Join(dir, "a.ipynb")
+	if err := os.WriteFile(path, []byte(nb), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input map[string]any
+		want  string
+	}{
+		{"replace", map[string]any{"cell_id": "calc", "new_source": "x = 2\nprint(x)\n"}, "-x = 1\n+x = 2\n print(x)\n"},
+		{"by index", map[string]any{"cell_id": "cell-0", "new_source": "# Better title\n"}, "-# Title\n+# Better title\n"},
+		{"insert", map[string]any{"cell_id": "calc", "new_source": "y = 3", "edit_mode": "insert"}, "@@ -0,0 +1,1 @@\n+y = 3\n"},
+		{"delete", map[string]any{"cell_id": "calc", "new_source": "", "edit_mode": "delete"}, "-x = 1\n-print(x)\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.input["notebook_path"] = path
+			d, ok := ForRequest(request(t, "NotebookEdit", "", tc.input))
+			if text := d.Text(0); !ok || !strings.Contains(text, tc.want) {
+				t.Errorf("diff =\n%s\nwant it to contain\n%s", text, tc.want)
+			}
+		})
+	}
+}
+
+func TestForRequest_OtherTools(t *testing.T) {
+	if _, ok := ForRequest(request(t, "Bash", "", map[string]any{"command": "ls"})); ok {
+		t.Error("ForRequest(Bash) = true")
+	}
+	if _, ok := ForRequest(&pb.PermissionRequest{ToolName: "Edit", ToolInputJson: "not json"}); ok {
+		t.Error("ForRequest(invalid input) = true")
+	}
+}
+
+func TestFileDiff_TextLimit(t *testing.T) {
+	d := FileDiff{Path: "big.txt", Hunks: Unified("", strings.Repeat("line\n", 50), 3)}
+	text := d.Text(10)
+	if strings.Count(text, "+line") != 10 || !strings.HasSuffix(text, "... 40 more lines\n") {
+		t.Errorf("text =\n%s", text)
+	}
+}
+
+func TestVisible(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain\ttext", "plain\ttext"},
+		{"\x1b[2J\x1b]0;title\a", "^[[2J^[]0;title^G"},
+		{"cr\rdel\x7f", "cr^Mdel^?"},
+		{"csi\u009b31m", `csi\u009b31m`},
+		{"bad\xffbyte", `bad\xffbyte`},
+		{"ok �", "ok �"},
+	}
+	for _, tt := range tests {
+		if got := Visible(tt.in); got != tt.want {
+			t.Errorf("Visible(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFileDiff_RenderEscapesControls(t *testing.T) {
+	req := request(t, "Edit", "", map[string]any{
+		"file_path":  "/nonexistent/\x1b]0;x\a.txt",
+		"old_string": "a",
+		"new_string": "b\x1b[2J\x1b[H",
+	})
+	d, ok := ForRequest(req)
+	if !ok {
+		t.Fatal("ForRequest(Edit) = false")
+	}
+	var styled []string
+	text := d.Render(0, func(_ Op, line string) string {
+		styled = append(styled, line)
+		return line
+	})
+	if strings.ContainsRune(text, '\x1b') || strings.ContainsRune(text, '\a') {
+		t.Errorf("text contains control characters: %q", text)
+	}
+	if !strings.Contains(text, "+b^[[2J^[[H") || !strings.Contains(text, "^[]0;x^G.txt") {
+		t.Errorf("text =\n%s", text)
+	}
+	for _, line := range styled {
+		if strings.ContainsRune(line, '\x1b') {
+			t.Errorf("style got unescaped line %q", line)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"strings"
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
 )
@@ -13,6 +15,24 @@ type AuditHandler interface {
 	Close() error
 }
 
+// DecisionString returns the short name of d ("allow", "deny", or "ask"),
+// or "" if d is unspecified.
+func DecisionString(d pb.PermissionDecision) string {
+	if d == pb.PermissionDecision_PERMISSION_DECISION_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(d.String(), "PERMISSION_DECISION_"))
+}
+
+// DecisionSourceString returns the short name of s (e.g. "human", "policy"),
+// or "" if s is unspecified.
+func DecisionSourceString(s pb.DecisionSource) string {
+	if s == pb.DecisionSource_DECISION_SOURCE_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(s.String(), "DECISION_SOURCE_"))
+}
+
 // NoOpAuditHandler discards all audit events.
 type NoOpAuditHandler struct{}
 
@@ -50,6 +70,27 @@ func (h *LogAuditHandler) HandleAuditEvent(ctx context.Context, event *pb.AuditE
 		)
 	}
 
+	if resp := event.GetResponse(); resp != nil {
+		hso := resp.GetHookSpecificOutput()
+		attrs = append(attrs,
+			slog.String("decision", DecisionString(hso.GetPermissionDecision())),
+			slog.String("reason", hso.GetPermissionDecisionReason()),
+			slog.String("source", DecisionSourceString(resp.GetDecisionSource())),
+		)
+	}
+	if latency := event.GetLatency(); latency != nil {
+		attrs = append(attrs, slog.Duration("latency", latency.AsDuration()))
+	}
+	if errMsg := event.GetError(); errMsg != "" {
+		attrs = append(attrs, slog.String("error", errMsg))
+	}
+	if peer := event.GetPeer(); peer != nil {
+		attrs = append(attrs,
+			slog.Int("peer_pid", int(peer.GetPid())),
+			slog.Int("peer_uid", int(peer.GetUid())),
+		)
+	}
+
 	h.logger.LogAttrs(ctx, slog.LevelInfo, "audit_event", attrs...)
 	return nil
 }
@@ -58,3 +99,37 @@ func (h *LogAuditHandler) HandleAuditEvent(ctx context.Context, event *pb.AuditE
 func (h *LogAuditHandler) Close() error {
 	return nil
 }
+
+// MultiAuditHandler forwards audit events to several handlers.
+type MultiAuditHandler struct {
+	handlers []AuditHandler
+}
+
+// NewMultiAuditHandler creates a MultiAuditHandler. Nil handlers are skipped.
+func NewMultiAuditHandler(handlers ...AuditHandler) *MultiAuditHandler {
+	m := &MultiAuditHandler{}
+	for _, h := range handlers {
+		if h != nil {
+			m.handlers = append(m.handlers, h)
+		}
+	}
+	return m
+}
+
+// HandleAuditEvent passes event to every handler and joins their errors.
+func (m *MultiAuditHandler) HandleAuditEvent(ctx context.Context, event *pb.AuditEvent) error {
+	var errs []error
+	for _, h := range m.handlers {
+		errs = append(errs, h.HandleAuditEvent(ctx, event))
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every handler and joins their errors.
+func (m *MultiAuditHandler) Close() error {
+	var errs []error
+	for _, h := range m.handlers {
+		errs = append(errs, h.Close())
+	}
+	return errors.Join(errs...)
+}
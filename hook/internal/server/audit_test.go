@@ -7,8 +7,10 @@ import (
 	"log/slog"
 	"strings"
 	"testing"
+	"time"
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -97,3 +99,64 @@ func TestLogAuditHandler_CloseIdempotent(t *testing.T) {
 		t.Fatalf("second close error: %v", err)
 	}
 }
+
+func TestMultiAuditHandler(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	h := NewMultiAuditHandler(
+		NewLogAuditHandler(slog.New(slog.NewTextHandler(&buf1, nil))),
+		nil,
+		NewLogAuditHandler(slog.New(slog.NewTextHandler(&buf2, nil))),
+	)
+	if err := h.HandleAuditEvent(context.Background(), testEvent()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf1.String(), "audit_event") || !strings.Contains(buf2.String(), "audit_event") {
+		t.Error("expected both handlers to receive the event")
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+}
+
+func TestLogAuditHandler_Response(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewLogAuditHandler(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	event := testEvent()
+	event.Response = BuildPermissionResponse(event.Request, pb.PermissionDecision_PERMISSION_DECISION_DENY, "not now")
+	event.Response.DecisionSource = pb.DecisionSource_DECISION_SOURCE_POLICY
+	event.Latency = durationpb.New(1500 * time.Millisecond)
+	event.Error = "boom"
+
+	if err := h.HandleAuditEvent(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var parsed map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	for key, want := range map[string]any{
+		"decision": "deny",
+		"reason":   "not now",
+		"source":   "policy",
+		"latency":  float64(1500 * time.Millisecond),
+		"error":    "boom",
+	} {
+		if parsed[key] != want {
+			t.Errorf("%s = %v, want %v", key, parsed[key], want)
+		}
+	}
+}
+
+func TestDecisionString(t *testing.T) {
+	if got := DecisionString(pb.PermissionDecision_PERMISSION_DECISION_ALLOW); got != "allow" {
+		t.Errorf("DecisionString(ALLOW) = %q", got)
+	}
+	if got := DecisionString(pb.PermissionDecision_PERMISSION_DECISION_UNSPECIFIED); got != "" {
+		t.Errorf("DecisionString(UNSPECIFIED) = %q", got)
+	}
+	if got := DecisionSourceString(pb.DecisionSource_DECISION_SOURCE_PATH_GUARD); got != "path_guard" {
+		t.Errorf("DecisionSourceString(PATH_GUARD) = %q", got)
+	}
+}
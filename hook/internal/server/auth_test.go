@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/certs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// startServer starts a server on a random local port and returns a client
+// connected to it with opts.
+func startServer(t *testing.T, cfg Config, opts ...grpc.DialOption) pb.PermissionServiceClient {
+	t.Helper()
+	cfg.Address = "localhost:0"
+	cfg.Prompter = &countingPrompter{}
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(srv.Address(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPermissionServiceClient(conn)
+}
+
+// callBoth calls RequestPermission and Audit and returns their status codes.
+func callBoth(t *testing.T, client pb.PermissionServiceClient) (codes.Code, codes.Code) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := client.RequestPermission(ctx, &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: "Bash"})
+	requestCode := status.Code(err)
+
+	stream, err := client.Audit(ctx)
+	if err == nil {
+		if err = stream.Send(testEvent()); err == nil {
+			_, err = stream.CloseAndRecv()
+		}
+	}
+	return requestCode, status.Code(err)
+}
+
+func TestLoadToken(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("  s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := LoadToken(path); err != nil || token != "s3cret" {
+		t.Errorf("LoadToken = %q, %v", token, err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadToken(empty); err == nil {
+		t.Error("expected error for empty token")
+	}
+}
+
+func TestCheckTokenTransport(t *testing.T) {
+	tests := []struct {
+		address string
+		tls     bool
+		ok      bool
+	}{
+		{"unix:///run/user/1000/crabhook/crabhook.sock", false, true},
+		{"localhost:50051", false, true},
+		{"127.0.0.1:50051", false, true},
+		{"[::1]:50051", false, true},
+		{"dns:///localhost:50051", false, true},
+		{"example.com:50051", false, false},
+		{"192.168.1.10:50051", false, false},
+		{":50051", false, false},
+		{"dns:///example.com:50051", false, false},
+		{"example.com:50051", true, true},
+	}
+	for _, tt := range tests {
+		err := CheckTokenTransport(tt.address, tt.tls)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckTokenTransport(%q, %v) = %v, want ok = %v", tt.address, tt.tls, err, tt.ok)
+		}
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	insecureCreds := grpc.WithTransportCredentials(insecure.NewCredentials())
+	tests := []struct {
+		name string
+		opts []grpc.DialOption
+		want codes.Code
+	}{
+		{"no token", []grpc.DialOption{insecureCreds}, codes.Unauthenticated},
+		{"wrong token", []grpc.DialOption{insecureCreds, grpc.WithPerRPCCredentials(TokenCredentials("wrong"))}, codes.Unauthenticated},
+		{"valid token", []grpc.DialOption{insecureCreds, grpc.WithPerRPCCredentials(TokenCredentials("s3cret"))}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := startServer(t, Config{Token: "s3cret"}, tt.opts...)
+			requestCode, auditCode := callBoth(t, client)
+			if requestCode != tt.want || auditCode != tt.want {
+				t.Errorf("RequestPermission = %v, Audit = %v, want %v", requestCode, auditCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	if err := certs.Generate(dir, certs.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	serverTLS, err := ServerTLSConfig(path(certs.ServerCertFile), path(certs.ServerKeyFile), path(certs.CACertFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withCert, err := ClientTLSConfig(path(certs.CACertFile), path(certs.ClientCertFile), path(certs.ClientKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutCert, err := ClientTLSConfig(path(certs.CACertFile), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		creds credentials.TransportCredentials
+		want  codes.Code
+	}{
+		{"plaintext", insecure.NewCredentials(), codes.Unavailable},
+		{"no client certificate", credentials.NewTLS(withoutCert), codes.Unavailable},
+		{"client certificate", credentials.NewTLS(withCert), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := startServer(t, Config{TLS: serverTLS}, grpc.WithTransportCredentials(tt.creds))
+			requestCode, auditCode := callBoth(t, client)
+			if requestCode != tt.want || auditCode != tt.want {
+				t.Errorf("RequestPermission = %v, Audit = %v, want %v", requestCode, auditCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FallbackAction is what the hook client does with a permission request the
+// server could not decide, e.g. because it is not running.
+type FallbackAction string
+
+const (
+	// FallbackAllow approves the tool execution (fail-open).
+	FallbackAllow FallbackAction = "allow"
+	// FallbackDeny blocks the tool execution (fail-closed).
+	FallbackDeny FallbackAction = "deny"
+	// FallbackAsk defers to Claude Code's native permission prompt.
+	FallbackAsk FallbackAction = "ask"
+	// FallbackPolicy evaluates a local policy file offline. Requests matching
+	// no rule, or a "prompt" rule, are deferred to Claude Code's native prompt.
+	FallbackPolicy FallbackAction = "policy"
+)
+
+// toolCategories are the categories a fallback action can be selected for.
+var toolCategories = []model.ToolCategory{
+	model.CategoryCommand,
+	model.CategoryFilePath,
+	model.CategoryWeb,
+	model.CategoryTask,
+	model.CategoryUserInteraction,
+	model.CategoryPlanMode,
+	model.CategoryMCP,
+	model.CategoryOther,
+}
+
+// ParseFallbackAction parses a fallback action name.
+func ParseFallbackAction(s string) (FallbackAction, error) {
+	switch a := FallbackAction(strings.ToLower(s)); a {
+	case FallbackAllow, FallbackDeny, FallbackAsk, FallbackPolicy:
+		return a, nil
+	default:
+		return "", fmt.Errorf("invalid fallback action %q (want allow, deny, ask or policy)", s)
+	}
+}
+
+// Unavailable reports whether err, returned by a permission RPC, means the
+// server could not be reached. Other errors must not be decided by a
+// Fallback, which may fail open: a rejected token or a malformed request,
+// and also a deadline, since a request nobody answered in time is not one
+// the fallback was meant to approve.
+func Unavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// Fallback decides permission requests on the client side when the server
+// is unavailable (see Unavailable).
+type Fallback struct {
+	// Default is the action for tools without a category override.
+	Default FallbackAction
+	// Categories overrides Default per tool category.
+	Categories map[model.ToolCategory]FallbackAction
+	// Policy is evaluated for FallbackPolicy. It must be set if any action is
+	// FallbackPolicy.
+	Policy *Policy
+}
+
+// NewFallback builds a Fallback from the default action and category
+// overrides keyed by category name (e.g. "command", "file_path", "mcp").
+func NewFallback(defaultAction string, categories map[string]string, policy *Policy) (*Fallback, error) {
+	def, err := ParseFallbackAction(defaultAction)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fallback{
+		Default:    def,
+		Categories: make(map[model.ToolCategory]FallbackAction, len(categories)),
+		Policy:     policy,
+	}
+	for name, action := range categories {
+		category := model.ToolCategory(name)
+		if !slices.Contains(toolCategories, category) {
+			return nil, fmt.Errorf("unknown tool category %q", name)
+		}
+		a, err := ParseFallbackAction(action)
+		if err != nil {
+			return nil, fmt.Errorf("category %q: %w", name, err)
+		}
+		f.Categories[category] = a
+	}
+
+	if policy == nil {
+		if def == FallbackPolicy {
+			return nil, fmt.Errorf("fallback action %q requires a policy file", FallbackPolicy)
+		}
+		for category, a := range f.Categories {
+			if a == FallbackPolicy {
+				return nil, fmt.Errorf("category %q: fallback action %q requires a policy file", category, FallbackPolicy)
+			}
+		}
+	}
+	return f, nil
+}
+
+// Action returns the fallback action for toolName.
+func (f *Fallback) Action(toolName string) FallbackAction {
+	if a, ok := f.Categories[model.ToolName(toolName).Category()]; ok {
+		return a
+	}
+	return f.Default
+}
+
+// Decide builds the fallback response for req. cause is the error that kept
+// the server from deciding; it is included in the decision reason.
+func (f *Fallback) Decide(req *pb.PermissionRequest, cause error) *pb.PermissionResponse {
+	prefix := fmt.Sprintf("crabhook permission server unavailable (%v)", cause)
+
+	var (
+		decision pb.PermissionDecision
+		reason   string
+	)
+	switch f.Action(req.GetToolName()) {
+	case FallbackAllow:
+		decision, reason = pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "allowed by fallback"
+	case FallbackAsk:
+		decision, reason = pb.PermissionDecision_PERMISSION_DECISION_ASK, "deferred to Claude Code"
+	case FallbackPolicy:
+		if d, ok := f.Policy.Evaluate(req); ok && d.Action != RuleActionPrompt {
+			resp := d.Response(req)
+			resp.HookSpecificOutput.PermissionDecisionReason = prefix + "; " + d.Reason()
+			return withSource(resp, pb.DecisionSource_DECISION_SOURCE_FALLBACK)
+		}
+		decision, reason = pb.PermissionDecision_PERMISSION_DECISION_ASK, "no fallback rule decided; deferred to Claude Code"
+	default:
+		decision, reason = pb.PermissionDecision_PERMISSION_DECISION_DENY, "denied by fallback"
+	}
+	resp := BuildPermissionResponse(req, decision, prefix+"; "+reason)
+	return withSource(resp, pb.DecisionSource_DECISION_SOURCE_FALLBACK)
+}
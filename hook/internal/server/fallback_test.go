@@ -0,0 +1,100 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewFallback_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		def        string
+		categories map[string]string
+	}{
+		{"bad default", "maybe", nil},
+		{"unknown category", "deny", map[string]string{"shell": "ask"}},
+		{"bad category action", "deny", map[string]string{"command": "maybe"}},
+		{"policy default without file", "policy", nil},
+		{"policy category without file", "deny", map[string]string{"command": "policy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFallback(tt.def, tt.categories, nil); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestFallback_Decide(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Name: "allow-git", Tools: Patterns{"Bash"}, Input: map[string]Patterns{"command": {"git *"}}, Action: RuleActionAllow},
+		{Name: "review-rm", Tools: Patterns{"Bash"}, Input: map[string]Patterns{"command": {"rm *"}}, Action: RuleActionPrompt},
+	}}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFallback("deny", map[string]string{
+		"command":   "policy",
+		"file_path": "ask",
+		"web":       "allow",
+	}, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tool   string
+		input  string
+		want   pb.PermissionDecision
+		reason string
+	}{
+		{"policy match", "Bash", `{"command":"git status"}`, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, `policy rule "allow-git"`},
+		{"policy prompt rule", "Bash", `{"command":"rm -rf x"}`, pb.PermissionDecision_PERMISSION_DECISION_ASK, "deferred"},
+		{"policy no match", "Bash", `{"command":"make"}`, pb.PermissionDecision_PERMISSION_DECISION_ASK, "deferred"},
+		{"category ask", "Read", `{}`, pb.PermissionDecision_PERMISSION_DECISION_ASK, "deferred"},
+		{"category allow", "WebFetch", `{}`, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "allowed by fallback"},
+		{"default deny", "mcp__x__y", `{}`, pb.PermissionDecision_PERMISSION_DECISION_DENY, "denied by fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: tt.tool, ToolInputJson: tt.input}
+			resp := f.Decide(req, errors.New("connection refused"))
+			hso := resp.GetHookSpecificOutput()
+			if hso.GetPermissionDecision() != tt.want {
+				t.Errorf("decision = %v, want %v", hso.GetPermissionDecision(), tt.want)
+			}
+			if reason := hso.GetPermissionDecisionReason(); !strings.Contains(reason, "connection refused") || !strings.Contains(reason, tt.reason) {
+				t.Errorf("reason = %q, want it to mention the cause and %q", reason, tt.reason)
+			}
+			if resp.GetDecisionSource() != pb.DecisionSource_DECISION_SOURCE_FALLBACK {
+				t.Errorf("source = %v, want FALLBACK", resp.GetDecisionSource())
+			}
+		})
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{status.Error(codes.Unavailable, "connection refused"), true},
+		{status.Error(codes.DeadlineExceeded, "context deadline exceeded"), false},
+		{status.Error(codes.Unauthenticated, "invalid token"), false},
+		{status.Error(codes.PermissionDenied, "peer not allowed"), false},
+		{status.Error(codes.InvalidArgument, "bad request"), false},
+		{errors.New("not a status"), false},
+	}
+	for _, tt := range tests {
+		if got := Unavailable(tt.err); got != tt.want {
+			t.Errorf("Unavailable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
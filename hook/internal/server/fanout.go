@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+// ErrAnsweredElsewhere is the cancellation cause seen by the Prompters of a
+// MultiPrompter that lost the race to answer a request.
+var ErrAnsweredElsewhere = errors.New("answered by another front-end")
+
+// MultiPrompter dispatches each request to several Prompters (e.g. the TUI
+// and the web UI) and returns the first answer. The request is retracted
+// from the other Prompters by cancelling their context with
+// ErrAnsweredElsewhere as the cause.
+type MultiPrompter struct {
+	prompters []Prompter
+}
+
+// NewMultiPrompter creates a MultiPrompter. Nil prompters are skipped.
+func NewMultiPrompter(prompters ...Prompter) *MultiPrompter {
+	m := &MultiPrompter{}
+	for _, p := range prompters {
+		if p != nil {
+			m.prompters = append(m.prompters, p)
+		}
+	}
+	return m
+}
+
+// Prompt asks every Prompter and returns the first successful answer. A
+// Prompter failing does not end the prompt as long as another one may still
+// answer; if all fail, their errors are joined.
+func (m *MultiPrompter) Prompt(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	if len(m.prompters) == 0 {
+		return nil, errors.New("no prompter configured")
+	}
+
+	promptCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(ErrAnsweredElsewhere)
+
+	type result struct {
+		resp *pb.PermissionResponse
+		err  error
+	}
+	results := make(chan result, len(m.prompters))
+	for _, p := range m.prompters {
+		go func() {
+			resp, err := p.Prompt(promptCtx, req)
+			results <- result{resp, err}
+		}()
+	}
+
+	var errs []error
+	for range m.prompters {
+		r := <-results
+		if r.err == nil {
+			return r.resp, nil
+		}
+		errs = append(errs, r.err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+// promptFunc adapts a function to the Prompter interface.
+type promptFunc func(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error)
+
+func (f promptFunc) Prompt(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	return f(ctx, req)
+}
+
+// blockingPrompter waits until cancelled and reports the cancellation cause.
+func blockingPrompter(cause chan<- error) Prompter {
+	return promptFunc(func(ctx context.Context, _ *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+}
+
+func TestMultiPrompter_FirstAnswerWins(t *testing.T) {
+	req := &pb.PermissionRequest{ToolName: "Bash"}
+	allow := BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "")
+	cause := make(chan error, 1)
+
+	m := NewMultiPrompter(
+		blockingPrompter(cause),
+		nil,
+		promptFunc(func(context.Context, *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+			return allow, nil
+		}),
+	)
+	resp, err := m.Prompt(context.Background(), req)
+	if err != nil || resp != allow {
+		t.Fatalf("Prompt() = %v, %v, want %v", resp, err, allow)
+	}
+
+	select {
+	case got := <-cause:
+		if !errors.Is(got, ErrAnsweredElsewhere) {
+			t.Errorf("cause = %v, want ErrAnsweredElsewhere", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("losing prompter was not cancelled")
+	}
+}
+
+func TestMultiPrompter_FailureFallsThrough(t *testing.T) {
+	req := &pb.PermissionRequest{ToolName: "Bash"}
+	deny := BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_DENY, "")
+	failed := make(chan struct{})
+
+	m := NewMultiPrompter(
+		promptFunc(func(context.Context, *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+			defer close(failed)
+			return nil, errors.New("TUI terminated")
+		}),
+		promptFunc(func(context.Context, *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+			<-failed
+			return deny, nil
+		}),
+	)
+	resp, err := m.Prompt(context.Background(), req)
+	if err != nil || resp != deny {
+		t.Fatalf("Prompt() = %v, %v, want %v", resp, err, deny)
+	}
+}
+
+func TestMultiPrompter_Errors(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	failWith := func(err error) Prompter {
+		return promptFunc(func(context.Context, *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+			return nil, err
+		})
+	}
+
+	_, err := NewMultiPrompter(failWith(errA), failWith(errB)).Prompt(context.Background(), &pb.PermissionRequest{})
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("err = %v, want both errors", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cause := make(chan error, 2)
+	_, err = NewMultiPrompter(blockingPrompter(cause), blockingPrompter(cause)).Prompt(ctx, &pb.PermissionRequest{})
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	if _, err := NewMultiPrompter().Prompt(context.Background(), &pb.PermissionRequest{}); err == nil {
+		t.Error("expected error without prompters")
+	}
+}
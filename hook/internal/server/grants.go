@@ -0,0 +1,266 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GrantScope is the scope of a remembered allow decision.
+type GrantScope string
+
+const (
+	// GrantScopeSession allows the tool for the rest of the session.
+	GrantScopeSession GrantScope = "session"
+	// GrantScopeProjectCommand allows the exact same tool input in the
+	// project (cwd), across sessions.
+	GrantScopeProjectCommand GrantScope = "project_command"
+	// GrantScopeTool allows the tool in any session until the grant expires.
+	GrantScopeTool GrantScope = "tool"
+)
+
+// AllowsTool reports whether grants of scope s may allow the tool. Bash is
+// only granted per exact command: a session or tool grant would allow
+// arbitrary commands.
+func (s GrantScope) AllowsTool(toolName string) bool {
+	return s == GrantScopeProjectCommand || toolName != string(model.ToolNameBash)
+}
+
+// DefaultToolGrantTTL is the lifetime of GrantScopeTool grants created from the TUI.
+const DefaultToolGrantTTL = 30 * time.Minute
+
+// Grant is a remembered allow decision consulted before prompting.
+type Grant struct {
+	ID        string     `json:"id"`
+	Scope     GrantScope `json:"scope"`
+	ToolName  string     `json:"tool_name"`
+	SessionID string     `json:"session_id,omitempty"`
+	Cwd       string     `json:"cwd,omitempty"`
+	// Input is the Bash command, or the tool input JSON for other tools.
+	Input     string    `json:"input,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is zero if the grant never expires.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// NewGrant creates a grant of the given scope from req. ttl sets ExpiresAt
+// relative to now if positive.
+func NewGrant(scope GrantScope, req *pb.PermissionRequest, ttl time.Duration) Grant {
+	g := Grant{
+		Scope:    scope,
+		ToolName: req.GetToolName(),
+	}
+	switch scope {
+	case GrantScopeSession:
+		g.SessionID = req.GetSessionId()
+	case GrantScopeProjectCommand:
+		g.Cwd = req.GetCwd()
+		g.Input = grantInput(req)
+	}
+	if ttl > 0 {
+		g.ExpiresAt = time.Now().Add(ttl)
+	}
+	return g
+}
+
+// Matches reports whether g applies to req at time now.
+func (g Grant) Matches(req *pb.PermissionRequest, now time.Time) bool {
+	if g.Expired(now) || g.ToolName != req.GetToolName() || !g.Scope.AllowsTool(g.ToolName) {
+		return false
+	}
+	switch g.Scope {
+	case GrantScopeSession:
+		return g.SessionID == req.GetSessionId()
+	case GrantScopeProjectCommand:
+		return g.Cwd == req.GetCwd() && g.Input == grantInput(req)
+	case GrantScopeTool:
+		return true
+	default:
+		return false
+	}
+}
+
+// Expired reports whether g has expired at time now.
+func (g Grant) Expired(now time.Time) bool {
+	return !g.ExpiresAt.IsZero() && !now.Before(g.ExpiresAt)
+}
+
+// Describe returns a short human-readable description of g.
+func (g Grant) Describe() string {
+	var desc string
+	switch g.Scope {
+	case GrantScopeSession:
+		desc = fmt.Sprintf("%s for session %s", g.ToolName, g.SessionID)
+	case GrantScopeProjectCommand:
+		desc = fmt.Sprintf("%s %q in %s", g.ToolName, g.Input, g.Cwd)
+	default:
+		desc = fmt.Sprintf("%s in any session", g.ToolName)
+	}
+	if !g.ExpiresAt.IsZero() {
+		desc += " until " + g.ExpiresAt.Format(time.TimeOnly)
+	}
+	return desc
+}
+
+// Reason returns the permission decision reason recorded for requests
+// allowed by g.
+func (g Grant) Reason() string {
+	return fmt.Sprintf("grant %s (%s)", g.ID, g.Scope)
+}
+
+// ToProto converts g to its protobuf representation.
+func (g Grant) ToProto() *pb.Grant {
+	out := &pb.Grant{
+		Id:        g.ID,
+		ToolName:  g.ToolName,
+		SessionId: g.SessionID,
+		Cwd:       g.Cwd,
+		Input:     g.Input,
+		CreatedAt: timestamppb.New(g.CreatedAt),
+	}
+	switch g.Scope {
+	case GrantScopeSession:
+		out.Scope = pb.GrantScope_GRANT_SCOPE_SESSION
+	case GrantScopeProjectCommand:
+		out.Scope = pb.GrantScope_GRANT_SCOPE_PROJECT_COMMAND
+	case GrantScopeTool:
+		out.Scope = pb.GrantScope_GRANT_SCOPE_TOOL
+	}
+	if !g.ExpiresAt.IsZero() {
+		out.ExpiresAt = timestamppb.New(g.ExpiresAt)
+	}
+	return out
+}
+
+// grantInput returns the part of the tool input compared by
+// GrantScopeProjectCommand grants.
+func grantInput(req *pb.PermissionRequest) string {
+	if req.GetToolName() == string(model.ToolNameBash) {
+		if command, ok := parseInputFields(req.GetToolInputJson())["command"].(string); ok {
+			return command
+		}
+	}
+	return req.GetToolInputJson()
+}
+
+// GrantStore holds grants in memory and optionally persists them to a
+// JSON file. It is safe for concurrent use.
+type GrantStore struct {
+	mu     sync.Mutex
+	path   string
+	grants []Grant
+	now    func() time.Time
+}
+
+// NewGrantStore creates a GrantStore. If path is not empty, grants are
+// loaded from and saved to that file; a missing file is not an error.
+func NewGrantStore(path string) (*GrantStore, error) {
+	s := &GrantStore{path: path, now: time.Now}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grants file %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.grants); err != nil {
+		return nil, fmt.Errorf("failed to parse grants file %q: %w", path, err)
+	}
+	return s, nil
+}
+
+// Add stores g, assigning its ID and CreatedAt. The grant is kept in
+// memory even if saving it to the file fails.
+func (s *GrantStore) Add(g Grant) (Grant, error) {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	g.ID = hex.EncodeToString(id[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g.CreatedAt = s.now()
+	s.grants = append(s.grants, g)
+	return g, s.saveLocked()
+}
+
+// Match returns the first unexpired grant matching req.
+func (s *GrantStore) Match(req *pb.PermissionRequest) (Grant, bool) {
+	if s == nil {
+		return Grant{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for _, g := range s.grants {
+		if g.Matches(req, now) {
+			return g, true
+		}
+	}
+	return Grant{}, false
+}
+
+// List returns the unexpired grants in creation order.
+func (s *GrantStore) List() []Grant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var out []Grant
+	for _, g := range s.grants {
+		if !g.Expired(now) {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// Revoke removes the grant with the given ID. It returns false if no such
+// grant exists.
+func (s *GrantStore) Revoke(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.grants, func(g Grant) bool { return g.ID == id })
+	if i < 0 {
+		return false, nil
+	}
+	s.grants = slices.Delete(s.grants, i, i+1)
+	return true, s.saveLocked()
+}
+
+// saveLocked writes unexpired grants to the file. s.mu must be held.
+func (s *GrantStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	now := s.now()
+	s.grants = slices.DeleteFunc(s.grants, func(g Grant) bool { return g.Expired(now) })
+
+	data, err := json.MarshalIndent(s.grants, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode grants: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create grants directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write grants file %q: %w", s.path, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write grants file %q: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+func TestGrant_Matches(t *testing.T) {
+	now := time.Now()
+	req := &pb.PermissionRequest{
+		ToolName:      "Bash",
+		SessionId:     "sess-1",
+		Cwd:           "/repo",
+		ToolInputJson: `{"command":"make test","description":"run tests"}`,
+	}
+	fetch := &pb.PermissionRequest{ToolName: "WebFetch", SessionId: "sess-1"}
+
+	tests := []struct {
+		name  string
+		grant Grant
+		req   *pb.PermissionRequest
+		want  bool
+	}{
+		{"session same session", NewGrant(GrantScopeSession, fetch, 0), fetch, true},
+		{"session other session", NewGrant(GrantScopeSession, fetch, 0), &pb.PermissionRequest{ToolName: "WebFetch", SessionId: "sess-2"}, false},
+		{"session other tool", NewGrant(GrantScopeSession, fetch, 0), &pb.PermissionRequest{ToolName: "Write", SessionId: "sess-1"}, false},
+		{"session bash", NewGrant(GrantScopeSession, req, 0), req, false},
+		{"project same command", NewGrant(GrantScopeProjectCommand, req, 0), &pb.PermissionRequest{ToolName: "Bash", SessionId: "sess-2", Cwd: "/repo", ToolInputJson: `{"command":"make test"}`}, true},
+		{"project other command", NewGrant(GrantScopeProjectCommand, req, 0), &pb.PermissionRequest{ToolName: "Bash", Cwd: "/repo", ToolInputJson: `{"command":"make clean"}`}, false},
+		{"project other cwd", NewGrant(GrantScopeProjectCommand, req, 0), &pb.PermissionRequest{ToolName: "Bash", Cwd: "/other", ToolInputJson: `{"command":"make test"}`}, false},
+		{"tool any session", NewGrant(GrantScopeTool, fetch, time.Minute), &pb.PermissionRequest{ToolName: "WebFetch", SessionId: "sess-3"}, true},
+		{"tool expired", Grant{Scope: GrantScopeTool, ToolName: "WebFetch", ExpiresAt: now.Add(-time.Second)}, fetch, false},
+		{"tool bash", NewGrant(GrantScopeTool, req, time.Minute), req, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.grant.Matches(tt.req, now); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrantStore_AddRevokePersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grants.json")
+	store, err := NewGrantStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pb.PermissionRequest{ToolName: "Read", SessionId: "sess-1"}
+	g, err := store.Add(NewGrant(GrantScopeSession, req, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.ID == "" || g.CreatedAt.IsZero() {
+		t.Errorf("Add did not assign ID/CreatedAt: %+v", g)
+	}
+	if _, err := store.Add(Grant{Scope: GrantScopeTool, ToolName: "Bash", ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload from file: expired grants are dropped.
+	reloaded, err := NewGrantStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reloaded.List()
+	if len(list) != 1 || list[0].ID != g.ID {
+		t.Fatalf("reloaded grants = %+v, want [%s]", list, g.ID)
+	}
+	if m, ok := reloaded.Match(req); !ok || m.ID != g.ID {
+		t.Errorf("Match = %+v, %v", m, ok)
+	}
+
+	revoked, err := reloaded.Revoke(g.ID)
+	if err != nil || !revoked {
+		t.Fatalf("Revoke = %v, %v", revoked, err)
+	}
+	if revoked, _ := reloaded.Revoke(g.ID); revoked {
+		t.Error("second Revoke should report false")
+	}
+	if _, ok := reloaded.Match(req); ok {
+		t.Error("revoked grant should not match")
+	}
+}
+
+func TestServer_HandlePermissionRequestGrants(t *testing.T) {
+	store, err := NewGrantStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompter := &countingPrompter{}
+	srv, err := New(Config{Address: "localhost:0", Prompter: prompter, Grants: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	req := &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: "WebFetch", SessionId: "sess-1"}
+	g, err := store.Add(NewGrant(GrantScopeSession, req, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := srv.HandlePermissionRequest(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetHookSpecificOutput().GetPermissionDecision(); got != pb.PermissionDecision_PERMISSION_DECISION_ALLOW {
+		t.Errorf("decision = %v, want ALLOW", got)
+	}
+	if prompter.calls != 0 {
+		t.Errorf("prompter calls = %d, want 0", prompter.calls)
+	}
+
+	grants, err := srv.ListGrants(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grants) != 1 || grants[0].GetId() != g.ID || grants[0].GetScope() != pb.GrantScope_GRANT_SCOPE_SESSION {
+		t.Errorf("ListGrants = %v", grants)
+	}
+
+	if revoked, err := srv.RevokeGrant(context.Background(), g.ID); err != nil || !revoked {
+		t.Fatalf("RevokeGrant = %v, %v", revoked, err)
+	}
+	if _, err := srv.HandlePermissionRequest(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if prompter.calls != 1 {
+		t.Errorf("prompter calls after revoke = %d, want 1", prompter.calls)
+	}
+}
+
+func TestServer_PolicyBeforeGrants(t *testing.T) {
+	store, err := NewGrantStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range []string{"WebFetch", "Read"} {
+		if _, err := store.Add(Grant{Scope: GrantScopeTool, ToolName: tool}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	policy := &Policy{Rules: []Rule{
+		{Name: "deny-fetch", Tools: Patterns{"WebFetch"}, Action: RuleActionDeny},
+		{Name: "prompt-read", Tools: Patterns{"Read"}, Action: RuleActionPrompt},
+	}}
+	prompter := &countingPrompter{}
+	srv, err := New(Config{Address: "localhost:0", Prompter: prompter, Policy: policy, Grants: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	for tool, want := range map[string]pb.PermissionDecision{
+		// A grant does not override a policy decision...
+		"WebFetch": pb.PermissionDecision_PERMISSION_DECISION_DENY,
+		// ...but answers for the Prompter.
+		"Read": pb.PermissionDecision_PERMISSION_DECISION_ALLOW,
+	} {
+		resp, err := srv.HandlePermissionRequest(context.Background(), &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: tool})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetHookSpecificOutput().GetPermissionDecision(); got != want {
+			t.Errorf("%s: decision = %v, want %v", tool, got, want)
+		}
+	}
+	if prompter.calls != 0 {
+		t.Errorf("prompter calls = %d, want 0", prompter.calls)
+	}
+}
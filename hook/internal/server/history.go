@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var historyBucket = []byte("events")
+
+// HistoryQuery filters recorded audit events. Zero fields match everything.
+type HistoryQuery struct {
+	SessionID string
+	ToolName  string
+	// Since and Until bound the event timestamp to [Since, Until).
+	Since time.Time
+	Until time.Time
+	// Decision matches the decision of the recorded response.
+	Decision pb.PermissionDecision
+	// Limit is the maximum number of most recent events returned.
+	Limit int
+}
+
+// HistoryQueryFromProto converts a QueryHistoryRequest to a HistoryQuery.
+func HistoryQueryFromProto(req *pb.QueryHistoryRequest) HistoryQuery {
+	q := HistoryQuery{
+		SessionID: req.GetSessionId(),
+		ToolName:  req.GetToolName(),
+		Decision:  req.GetDecision(),
+		Limit:     int(req.GetLimit()),
+	}
+	if req.GetSince() != nil {
+		q.Since = req.GetSince().AsTime()
+	}
+	if req.GetUntil() != nil {
+		q.Until = req.GetUntil().AsTime()
+	}
+	return q
+}
+
+func (q HistoryQuery) match(event *pb.AuditEvent) bool {
+	req := event.GetRequest()
+	if q.SessionID != "" && req.GetSessionId() != q.SessionID {
+		return false
+	}
+	if q.ToolName != "" && req.GetToolName() != q.ToolName {
+		return false
+	}
+	if q.Decision != pb.PermissionDecision_PERMISSION_DECISION_UNSPECIFIED &&
+		event.GetResponse().GetHookSpecificOutput().GetPermissionDecision() != q.Decision {
+		return false
+	}
+	return true
+}
+
+// HistoryStore is an AuditHandler that records audit events in an embedded
+// bbolt database, keyed by event time, and serves queries over them.
+type HistoryStore struct {
+	db *bolt.DB
+}
+
+// OpenHistoryStore opens (or creates) the history database at path.
+func OpenHistoryStore(path string) (*HistoryStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %q: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(historyBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database %q: %w", path, err)
+	}
+	return &HistoryStore{db: db}, nil
+}
+
+// HandleAuditEvent records event. Events without a timestamp are recorded
+// at the current time.
+func (h *HistoryStore) HandleAuditEvent(_ context.Context, event *pb.AuditEvent) error {
+	if event.GetTimestamp() == nil {
+		event = proto.CloneOf(event)
+		event.Timestamp = timestamppb.Now()
+	}
+	data, err := proto.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	return h.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(historyKey(event.GetTimestamp().AsTime(), seq), data)
+	})
+}
+
+// Query returns the events matching q in chronological order.
+func (h *HistoryStore) Query(q HistoryQuery) ([]*pb.AuditEvent, error) {
+	var events []*pb.AuditEvent
+	err := h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+
+		// Walk backwards from Until so that Limit keeps the most recent events.
+		var k, v []byte
+		if q.Until.IsZero() {
+			k, v = c.Last()
+		} else {
+			k, v = c.Seek(historyKey(q.Until, 0))
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if !q.Since.IsZero() && historyKeyTime(k).Before(q.Since) {
+				break
+			}
+			event := &pb.AuditEvent{}
+			if err := proto.Unmarshal(v, event); err != nil {
+				return fmt.Errorf("failed to decode audit event: %w", err)
+			}
+			if !q.match(event) {
+				continue
+			}
+			events = append(events, event)
+			if q.Limit > 0 && len(events) >= q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(events)
+	return events, nil
+}
+
+// Close closes the database.
+func (h *HistoryStore) Close() error {
+	return h.db.Close()
+}
+
+// historyKey orders events by time, with seq breaking ties.
+func historyKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+func historyKeyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8])))
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func historyEvent(at time.Time, session, tool string, decision pb.PermissionDecision) *pb.AuditEvent {
+	req := &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: tool, SessionId: session}
+	resp := BuildPermissionResponse(req, decision, "")
+	resp.DecisionSource = pb.DecisionSource_DECISION_SOURCE_HUMAN
+	return &pb.AuditEvent{
+		Request:   req,
+		Timestamp: timestamppb.New(at),
+		Response:  resp,
+		Latency:   durationpb.New(time.Second),
+	}
+}
+
+func TestHistoryStore_Query(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := OpenHistoryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	allow := pb.PermissionDecision_PERMISSION_DECISION_ALLOW
+	deny := pb.PermissionDecision_PERMISSION_DECISION_DENY
+	events := []*pb.AuditEvent{
+		historyEvent(base, "s1", "Bash", allow),
+		historyEvent(base.Add(time.Minute), "s1", "Write", deny),
+		historyEvent(base.Add(2*time.Minute), "s2", "Bash", deny),
+		historyEvent(base.Add(3*time.Minute), "s2", "Bash", allow),
+		// Same timestamp as the previous event.
+		historyEvent(base.Add(3*time.Minute), "s1", "Read", allow),
+	}
+	// Insert out of order; results are ordered by timestamp.
+	for _, i := range []int{3, 0, 2, 1, 4} {
+		if err := store.HandleAuditEvent(context.Background(), events[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen to make sure events are persisted.
+	store, err = OpenHistoryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	tests := []struct {
+		name  string
+		query HistoryQuery
+		want  []string // session/tool pairs
+	}{
+		{"all", HistoryQuery{}, []string{"s1/Bash", "s1/Write", "s2/Bash", "s2/Bash", "s1/Read"}},
+		{"session", HistoryQuery{SessionID: "s2"}, []string{"s2/Bash", "s2/Bash"}},
+		{"tool and decision", HistoryQuery{ToolName: "Bash", Decision: deny}, []string{"s2/Bash"}},
+		{"time range", HistoryQuery{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, []string{"s1/Write", "s2/Bash"}},
+		{"until after last", HistoryQuery{Until: base.Add(time.Hour), Limit: 1}, []string{"s1/Read"}},
+		{"limit keeps most recent", HistoryQuery{SessionID: "s1", Limit: 2}, []string{"s1/Write", "s1/Read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Query(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pairs []string
+			for _, e := range got {
+				pairs = append(pairs, e.GetRequest().GetSessionId()+"/"+e.GetRequest().GetToolName())
+			}
+			if len(pairs) != len(tt.want) {
+				t.Fatalf("got %v, want %v", pairs, tt.want)
+			}
+			for i := range pairs {
+				if pairs[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", pairs, tt.want)
+				}
+			}
+		})
+	}
+
+	got, err := store.Query(HistoryQuery{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].GetResponse().GetDecisionSource() != pb.DecisionSource_DECISION_SOURCE_HUMAN || got[0].GetLatency().AsDuration() != time.Second {
+		t.Errorf("recorded event lost response data: %v", got[0])
+	}
+}
+
+func TestServer_DecisionSource(t *testing.T) {
+	store, err := NewGrantStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(Grant{Scope: GrantScopeTool, ToolName: "Read"}); err != nil {
+		t.Fatal(err)
+	}
+	policy := &Policy{Rules: []Rule{{Name: "deny-fetch", Tools: Patterns{"WebFetch"}, Action: RuleActionDeny}}}
+	srv, err := New(Config{Address: "localhost:0", Prompter: &countingPrompter{}, Policy: policy, Grants: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	for tool, want := range map[string]pb.DecisionSource{
+		"Read":     pb.DecisionSource_DECISION_SOURCE_GRANT,
+		"WebFetch": pb.DecisionSource_DECISION_SOURCE_POLICY,
+		"Bash":     pb.DecisionSource_DECISION_SOURCE_HUMAN,
+	} {
+		resp, err := srv.HandlePermissionRequest(context.Background(), &pb.PermissionRequest{ToolName: tool})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetDecisionSource(); got != want {
+			t.Errorf("%s: decision source = %v, want %v", tool, got, want)
+		}
+	}
+}
+
+func TestServer_RecordsHistory(t *testing.T) {
+	store, err := OpenHistoryStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	policy := &Policy{Rules: []Rule{{Name: "deny-fetch", Tools: Patterns{"WebFetch"}, Action: RuleActionDeny}}}
+	srv, err := New(Config{Address: "localhost:0", Prompter: &waitingPrompter{}, Policy: policy, History: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	ctx := context.Background()
+
+	if _, err := srv.HandlePermissionRequest(ctx, &pb.PermissionRequest{ToolName: "WebFetch", SessionId: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+	// A hook killed while waiting for the prompt is recorded with its error.
+	killed, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := srv.HandlePermissionRequest(killed, &pb.PermissionRequest{ToolName: "Bash", SessionId: "s1"}); err == nil {
+		t.Fatal("cancelled request succeeded")
+	}
+	if _, err := srv.HandleHook(ctx, &pb.HookRequest{
+		HookEventName: "PostToolUse",
+		SessionId:     "s1",
+		HookInputJson: `{"hook_event_name":"PostToolUse","tool_name":"Read","tool_input":{"file_path":"a"}}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Audit events from clients do not reach the history.
+	forged := historyEvent(time.Now(), "s1", "Bash", pb.PermissionDecision_PERMISSION_DECISION_ALLOW)
+	if err := srv.HandleAuditEvent(ctx, forged); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := store.Query(HistoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("recorded %d events, want 3: %v", len(events), events)
+	}
+	if e := events[0]; e.GetRequest().GetToolName() != "WebFetch" ||
+		e.GetResponse().GetDecisionSource() != pb.DecisionSource_DECISION_SOURCE_POLICY ||
+		e.GetTimestamp() == nil || e.GetLatency() == nil {
+		t.Errorf("policy decision recorded as %v", e)
+	}
+	if e := events[1]; e.GetRequest().GetToolName() != "Bash" || e.GetResponse() != nil || e.GetError() == "" {
+		t.Errorf("killed request recorded as %v", e)
+	}
+	if e := events[2]; e.GetRequest().GetHookEventName() != "PostToolUse" || e.GetRequest().GetToolName() != "Read" ||
+		e.GetRequest().GetToolInputJson() != `{"file_path":"a"}` || e.GetHookOutput() == nil {
+		t.Errorf("hook event recorded as %v", e)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/model/protoconv"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+)
+
+// HookHandler handles hook events that are not permission requests
+// (PostToolUse, Stop, Notification, SessionStart, ...). See NewHookResponse
+// for building the response from a model.HookOutput, e.g. model.Block.
+type HookHandler interface {
+	HandleHook(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error)
+}
+
+// HookHandlerFunc adapts a function to the HookHandler interface.
+type HookHandlerFunc func(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error)
+
+// HandleHook calls f(ctx, req).
+func (f HookHandlerFunc) HandleHook(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error) {
+	return f(ctx, req)
+}
+
+// ParseHookInput decodes the complete hook input carried by req.
+func ParseHookInput(req *pb.HookRequest) (*model.HookInput, error) {
+	var input model.HookInput
+	if err := json.Unmarshal([]byte(req.GetHookInputJson()), &input); err != nil {
+		return nil, fmt.Errorf("failed to parse hook input: %w", err)
+	}
+	return &input, nil
+}
+
+// NewHookResponse converts out into a response to req. The tool of req's
+// hook input selects the typed message of an updated input.
+func NewHookResponse(req *pb.HookRequest, out model.HookOutput) (*pb.HookResponse, error) {
+	var tool model.ToolName
+	if input, err := ParseHookInput(req); err == nil {
+		tool = input.ToolName
+	}
+	output, err := protoconv.HookOutputToProto(&out, tool)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.HookResponse{Output: output}, nil
+}
+
+// BuildHookResponse builds a response that lets Claude Code continue,
+// adding additionalContext to the hook-specific output if non-empty.
+// additionalContext is only honored for PostToolUse, UserPromptSubmit and
+// SessionStart.
+func BuildHookResponse(req *pb.HookRequest, additionalContext string) *pb.HookResponse {
+	var out model.HookOutput
+	if additionalContext != "" {
+		out.HookSpecificOutput = &model.HookSpecificOutput{
+			HookEventName:     model.HookEventName(req.GetHookEventName()),
+			AdditionalContext: additionalContext,
+		}
+	}
+	resp, err := NewHookResponse(req, out)
+	if err != nil {
+		// The event has no hook-specific output.
+		return &pb.HookResponse{Output: &sdkpb.SyncHookJSONOutput{}}
+	}
+	return resp
+}
+
+// hookAuditRequest describes a hook event as the request of an audit
+// event, filling in what its hook input tells.
+func hookAuditRequest(req *pb.HookRequest) *pb.PermissionRequest {
+	out := &pb.PermissionRequest{
+		HookEventName: req.GetHookEventName(),
+		SessionId:     req.GetSessionId(),
+		Cwd:           req.GetCwd(),
+		HookInputJson: req.GetHookInputJson(),
+		Tmux:          req.GetTmux(),
+	}
+	if input, err := ParseHookInput(req); err == nil {
+		out.ToolName = string(input.ToolName)
+		if input.ToolInput != nil {
+			out.ToolInputJson = string(input.ToolInput)
+		}
+		out.MessageId = input.MessageID
+		out.TranscriptPath = input.TranscriptPath
+		out.PermissionMode = input.PermissionMode
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/model/protoconv"
+)
+
+func TestParseHookInput(t *testing.T) {
+	req := &pb.HookRequest{
+		HookEventName: "Notification",
+		HookInputJson: `{"hook_event_name":"Notification","session_id":"s1","message":"Claude needs your permission","notification_type":"permission_prompt"}`,
+	}
+	input, err := ParseHookInput(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.HookEventName != model.HookEventNotification || input.NotificationType != model.NotificationTypePermissionPrompt {
+		t.Errorf("input = %+v", input)
+	}
+
+	if _, err := ParseHookInput(&pb.HookRequest{HookInputJson: "{"}); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestServer_HandleHook(t *testing.T) {
+	req := &pb.HookRequest{HookEventName: "SessionStart", SessionId: "s1"}
+
+	srv, err := New(Config{Address: "localhost:0", Prompter: &countingPrompter{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	resp, err := srv.HandleHook(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := resp.GetOutput(); out.Continue != nil || out.GetHookSpecificOutput() != nil {
+		t.Errorf("default output = %v, want continue with no output", out)
+	}
+
+	srv, err = New(Config{
+		Address:  "localhost:0",
+		Prompter: &countingPrompter{},
+		HookHandler: HookHandlerFunc(func(_ context.Context, req *pb.HookRequest) (*pb.HookResponse, error) {
+			return BuildHookResponse(req, "remember the style guide"), nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	resp, err = srv.HandleHook(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetOutput().GetHookSpecificOutput().GetSessionStart().GetAdditionalContext(); got != "remember the style guide" {
+		t.Errorf("additional context = %q", got)
+	}
+}
+
+func TestServer_HandleHook_BlockStop(t *testing.T) {
+	srv, err := New(Config{
+		Address:  "localhost:0",
+		Prompter: &countingPrompter{},
+		HookHandler: HookHandlerFunc(func(_ context.Context, req *pb.HookRequest) (*pb.HookResponse, error) {
+			if req.GetHookEventName() != string(model.HookEventStop) {
+				return BuildHookResponse(req, ""), nil
+			}
+			return NewHookResponse(req, model.Block("tests are still failing"))
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	req := &pb.HookRequest{
+		HookEventName: "Stop",
+		SessionId:     "s1",
+		HookInputJson: `{"hook_event_name":"Stop","session_id":"s1","stop_hook_active":false}`,
+	}
+	resp, err := srv.HandleHook(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := protoconv.HookOutputFromProto(resp.GetOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Decision != model.DecisionBlock || out.Reason != "tests are still failing" {
+		t.Errorf("output = %+v, want a block with the reason", out)
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// unixScheme is the address prefix selecting a Unix domain socket,
+// e.g. "unix:///run/user/1000/crabhook/crabhook.sock".
+const unixScheme = "unix://"
+
+// DefaultTCPAddress is the default address when no per-user socket
+// location is available.
+const DefaultTCPAddress = "localhost:50051"
+
+// DefaultAddress returns the default server address: a Unix socket under
+// $XDG_RUNTIME_DIR if it is set, otherwise DefaultTCPAddress.
+func DefaultAddress() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return DefaultTCPAddress
+	}
+	return unixScheme + filepath.Join(dir, "crabhook", "crabhook.sock")
+}
+
+// SocketPath returns the socket path of a "unix://" address.
+// It returns false for TCP addresses.
+func SocketPath(address string) (string, bool) {
+	if path, ok := strings.CutPrefix(address, unixScheme); ok {
+		return path, true
+	}
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return path, true
+	}
+	return "", false
+}
+
+// Listen listens on address, which is either a TCP "host:port" or a
+// "unix:///path/to.sock" address.
+//
+// Unix sockets are created with mode 0600 in a directory that must be owned
+// by the current user with mode 0700; a missing directory is created. A stale socket file left by a
+// crashed server is removed; a socket with a live server behind it is
+// reported as an error. Connections accepted on a Unix socket carry the
+// peer's credentials in their RemoteAddr (see PeerCredentials).
+func Listen(address string) (net.Listener, error) {
+	path, ok := SocketPath(address)
+	if !ok {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+		}
+		return listener, nil
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := checkSocketDir(dir); err != nil {
+		return nil, err
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := listenUnix(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return &peerCredListener{Listener: listener}, nil
+}
+
+// removeStaleSocket removes the socket at path unless a server is
+// accepting connections on it.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket %q: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%q exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("another server is listening on %q", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket %q: %w", path, err)
+	}
+	return nil
+}
+
+// PeerCredentials are the credentials of the process on the other end of a
+// Unix socket connection. It is the RemoteAddr of connections accepted by
+// a listener returned from Listen.
+type PeerCredentials struct {
+	net.Addr
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// String implements net.Addr.
+func (c *PeerCredentials) String() string {
+	return fmt.Sprintf("pid=%d uid=%d gid=%d", c.PID, c.UID, c.GID)
+}
+
+// peerCredListener attaches PeerCredentials to accepted connections.
+type peerCredListener struct {
+	net.Listener
+}
+
+func (l *peerCredListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return conn, nil
+	}
+	cred, err := peerCredentials(unixConn)
+	if err != nil {
+		// Credentials are informational; keep serving without them.
+		return conn, nil
+	}
+	cred.Addr = conn.RemoteAddr()
+	return &peerCredConn{Conn: conn, cred: cred}, nil
+}
+
+type peerCredConn struct {
+	net.Conn
+	cred *PeerCredentials
+}
+
+func (c *peerCredConn) RemoteAddr() net.Addr {
+	return c.cred
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestSocketPath(t *testing.T) {
+	tests := []struct {
+		address string
+		path    string
+		ok      bool
+	}{
+		{"unix:///run/user/1000/crabhook.sock", "/run/user/1000/crabhook.sock", true},
+		{"unix:relative.sock", "relative.sock", true},
+		{"localhost:50051", "", false},
+	}
+	for _, tt := range tests {
+		path, ok := SocketPath(tt.address)
+		if path != tt.path || ok != tt.ok {
+			t.Errorf("SocketPath(%q) = %q, %v; want %q, %v", tt.address, path, ok, tt.path, tt.ok)
+		}
+	}
+}
+
+func TestDefaultAddress(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got, want := DefaultAddress(), "unix:///run/user/1000/crabhook/crabhook.sock"; got != want {
+		t.Errorf("DefaultAddress = %q, want %q", got, want)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if got := DefaultAddress(); got != DefaultTCPAddress {
+		t.Errorf("DefaultAddress = %q, want %q", got, DefaultTCPAddress)
+	}
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "crabhook.sock")
+	address := "unix://" + path
+
+	listener, err := Listen(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+
+	// A live socket is not removed.
+	if _, err := Listen(address); err == nil {
+		t.Error("expected error for a socket in use")
+	}
+
+	// Simulate a crashed server: the socket file is left behind.
+	listener.(*peerCredListener).Listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected stale socket to remain: %v", err)
+	}
+
+	listener, err = Listen(address)
+	if err != nil {
+		t.Fatalf("Listen over stale socket: %v", err)
+	}
+	listener.Close()
+}
+
+func TestListen_NotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "file")
+	if err := os.Mkdir(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen("unix://" + path); err == nil {
+		t.Error("expected error for a regular file")
+	}
+}
+
+func TestListen_InsecureDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are Unix only")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen("unix://" + filepath.Join(dir, "crabhook.sock")); err == nil {
+		t.Error("expected error for a directory other users can access")
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(t.TempDir(), link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen("unix://" + filepath.Join(link, "crabhook.sock")); err == nil {
+		t.Error("expected error for a symlinked directory")
+	}
+}
+
+// recordingHandler records audit events.
+type recordingHandler struct {
+	NoOpAuditHandler
+	mu     sync.Mutex
+	events []*pb.AuditEvent
+}
+
+func (h *recordingHandler) HandleAuditEvent(_ context.Context, event *pb.AuditEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	return nil
+}
+
+func TestServer_UnixSocketPeerCredentials(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_PEERCRED is Linux only")
+	}
+
+	address := "unix://" + filepath.Join(t.TempDir(), "sub", "crabhook.sock")
+	audit := &recordingHandler{}
+	srv, err := New(Config{Address: address, Prompter: &countingPrompter{}, AuditHandler: audit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewPermissionServiceClient(conn)
+
+	stream, err := client.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		t.Fatal(err)
+	}
+
+	audit.mu.Lock()
+	defer audit.mu.Unlock()
+	if len(audit.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(audit.events))
+	}
+	peer := audit.events[0].GetPeer()
+	if peer.GetPid() != int32(os.Getpid()) || peer.GetUid() != uint32(os.Getuid()) {
+		t.Errorf("peer = %v, want pid=%d uid=%d", peer, os.Getpid(), os.Getuid())
+	}
+}
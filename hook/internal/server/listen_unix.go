@@ -0,0 +1,45 @@
+//go:build unix
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenUnix listens on the Unix socket at path and restricts it to mode
+// 0600. Until the chmod, the socket is protected by its 0700 directory (see
+// checkSocketDir).
+func listenUnix(path string) (net.Listener, error) {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to restrict socket %q: %w", path, err)
+	}
+	return ln, nil
+}
+
+// checkSocketDir rejects a socket directory that other users could
+// traverse or write to: it must be a directory, not a symlink, owned by the
+// current user with mode 0700.
+func checkSocketDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to stat socket directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("socket directory %q is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("socket directory %q is owned by uid %d, not the current user", dir, stat.Uid)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		return fmt.Errorf("socket directory %q has mode %o, want 700", dir, perm)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+// Notification describes a request waiting for an answer.
+type Notification struct {
+	Title   string
+	Message string
+	Request *pb.PermissionRequest
+}
+
+// NewNotification builds the Notification for req.
+func NewNotification(req *pb.PermissionRequest) Notification {
+	msg := requestSummary(req)
+	if session := req.GetSessionId(); session != "" {
+		if msg != "" {
+			msg += "\n"
+		}
+		msg += "session " + session
+	}
+	return Notification{
+		Title:   fmt.Sprintf("crabhook: %s is waiting for approval", req.GetToolName()),
+		Message: msg,
+		Request: req,
+	}
+}
+
+// summaryFields are the tool input fields that best describe a request, in
+// order of preference.
+var summaryFields = []string{"command", "file_path", "notebook_path", "url", "pattern", "query", "description", "prompt"}
+
+// requestSummary returns a one-line description of the tool input of req.
+func requestSummary(req *pb.PermissionRequest) string {
+	var input map[string]any
+	if err := json.Unmarshal([]byte(req.GetToolInputJson()), &input); err != nil {
+		return ""
+	}
+	for _, field := range summaryFields {
+		if s, ok := input[field].(string); ok && s != "" {
+			s, _, _ = strings.Cut(s, "\n")
+			return truncateRunes(s, 120)
+		}
+	}
+	return ""
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Notifier alerts the operator that a request is waiting for an answer.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// TerminalStyle selects the escape sequence written by TerminalNotifier.
+type TerminalStyle string
+
+const (
+	// TerminalBell rings the terminal bell (BEL).
+	TerminalBell TerminalStyle = "bell"
+	// TerminalOSC9 posts a desktop notification with OSC 9 (iTerm2, WezTerm,
+	// Windows Terminal, foot, ...).
+	TerminalOSC9 TerminalStyle = "osc9"
+	// TerminalOSC777 posts a desktop notification with OSC 777 (urxvt,
+	// VTE-based terminals, ...).
+	TerminalOSC777 TerminalStyle = "osc777"
+)
+
+// TerminalNotifier notifies through terminal escape sequences written to W.
+type TerminalNotifier struct {
+	W     io.Writer
+	Style TerminalStyle
+	// Tmux wraps OSC sequences in tmux's DCS passthrough so that they reach
+	// the terminal tmux runs in; tmux drops them otherwise. tmux forwards
+	// them only with "set -g allow-passthrough on".
+	Tmux bool
+}
+
+// Notify writes the escape sequence for n.
+func (t *TerminalNotifier) Notify(_ context.Context, n Notification) error {
+	var seq string
+	switch t.Style {
+	case TerminalOSC9:
+		seq = "\x1b]9;" + oscText(n.Title+": "+n.Message) + "\x07"
+	case TerminalOSC777:
+		seq = "\x1b]777;notify;" + oscText(n.Title) + ";" + oscText(n.Message) + "\x07"
+	default:
+		seq = "\x07"
+	}
+	if t.Tmux && t.Style != TerminalBell {
+		seq = tmuxPassthrough(seq)
+	}
+	_, err := io.WriteString(t.W, seq)
+	return err
+}
+
+// tmuxPassthrough wraps seq in a DCS passthrough sequence, doubling the ESCs
+// inside it as tmux requires.
+func tmuxPassthrough(seq string) string {
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// oscText makes s safe to embed in an OSC sequence. Control characters,
+// including the C1 ones, would end the sequence early or start another, so
+// line breaks and tabs become spaces and the rest are dropped. ';' separates
+// OSC parameters and becomes ','.
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case r == ';':
+			return ','
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// CommandNotifier runs a command for each notification. The title and
+// message are appended to Args and exported as $CRABHOOK_NOTIFY_TITLE and
+// $CRABHOOK_NOTIFY_MESSAGE, along with $CRABHOOK_TOOL_NAME,
+// $CRABHOOK_SESSION_ID and $CRABHOOK_CWD.
+type CommandNotifier struct {
+	Args []string
+}
+
+// NewDesktopNotifier returns a CommandNotifier posting desktop notifications
+// with notify-send (D-Bus).
+func NewDesktopNotifier() *CommandNotifier {
+	return &CommandNotifier{Args: []string{"notify-send", "--app-name=crabhook"}}
+}
+
+// NewShellNotifier returns a CommandNotifier running command with sh -c;
+// the title and message are $1 and $2.
+func NewShellNotifier(command string) *CommandNotifier {
+	return &CommandNotifier{Args: []string{"sh", "-c", command, "crabhook-notify"}}
+}
+
+// Notify runs the command and waits for it.
+func (c *CommandNotifier) Notify(ctx context.Context, n Notification) error {
+	if len(c.Args) == 0 {
+		return errors.New("no notify command")
+	}
+	args := append(append([]string{}, c.Args[1:]...), n.Title, n.Message)
+	cmd := exec.CommandContext(ctx, c.Args[0], args...)
+	cmd.Env = append(os.Environ(),
+		"CRABHOOK_NOTIFY_TITLE="+n.Title,
+		"CRABHOOK_NOTIFY_MESSAGE="+n.Message,
+		"CRABHOOK_TOOL_NAME="+n.Request.GetToolName(),
+		"CRABHOOK_SESSION_ID="+n.Request.GetSessionId(),
+		"CRABHOOK_CWD="+n.Request.GetCwd(),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("notify command %s: %w: %s", c.Args[0], err, msg)
+		}
+		return fmt.Errorf("notify command %s: %w", c.Args[0], err)
+	}
+	return nil
+}
+
+// QuietHours is a daily time range, possibly spanning midnight, during
+// which notifications are suppressed.
+type QuietHours struct {
+	// Start and End are offsets from midnight, local time.
+	Start, End time.Duration
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM" (e.g. "22:00-07:00").
+func ParseQuietHours(s string) (QuietHours, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: %w", s, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: %w", s, err)
+	}
+	return QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls within the quiet hours. Equal Start and
+// End mean no quiet hours.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// Notifications dispatches notifications to several Notifiers, limited to
+// one per MinInterval and suppressed during QuietHours.
+type Notifications struct {
+	notifiers []Notifier
+	// MinInterval is the minimum time between two notifications; requests
+	// arriving in between are not notified.
+	MinInterval time.Duration
+	// Quiet suppresses notifications during these hours.
+	Quiet QuietHours
+
+	now  func() time.Time
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewNotifications creates Notifications. Nil notifiers are skipped.
+func NewNotifications(notifiers ...Notifier) *Notifications {
+	n := &Notifications{}
+	for _, notifier := range notifiers {
+		if notifier != nil {
+			n.notifiers = append(n.notifiers, notifier)
+		}
+	}
+	return n
+}
+
+// Notify passes note to every notifier unless it is rate limited or within
+// quiet hours, and joins their errors.
+func (n *Notifications) Notify(ctx context.Context, note Notification) error {
+	if n == nil || !n.allow() {
+		return nil
+	}
+	var errs []error
+	for _, notifier := range n.notifiers {
+		errs = append(errs, notifier.Notify(ctx, note))
+	}
+	return errors.Join(errs...)
+}
+
+// allow reports whether a notification may be sent now, and records it.
+func (n *Notifications) allow() bool {
+	now := time.Now()
+	if n.now != nil {
+		now = n.now()
+	}
+	if n.Quiet.Contains(now) {
+		return false
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.last.IsZero() && now.Sub(n.last) < n.MinInterval {
+		return false
+	}
+	n.last = now
+	return true
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+func TestNewNotification(t *testing.T) {
+	n := NewNotification(&pb.PermissionRequest{
+		ToolName:      "Bash",
+		ToolInputJson: `{"command":"rm -rf build\necho done","description":"clean"}`,
+		SessionId:     "s1",
+	})
+	if n.Title != "crabhook: Bash is waiting for approval" {
+		t.Errorf("title = %q", n.Title)
+	}
+	if n.Message != "rm -rf build\nsession s1" {
+		t.Errorf("message = %q", n.Message)
+	}
+}
+
+func TestTerminalNotifier(t *testing.T) {
+	note := Notification{Title: "crabhook: Bash", Message: "ls; pwd\x1b]0;x\u009c\r\nsession s1"}
+	tests := []struct {
+		style TerminalStyle
+		want  string
+	}{
+		{TerminalBell, "\x07"},
+		{TerminalOSC9, "\x1b]9;crabhook: Bash: ls, pwd]0,x session s1\x07"},
+		{TerminalOSC777, "\x1b]777;notify;crabhook: Bash;ls, pwd]0,x session s1\x07"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := (&TerminalNotifier{W: &buf, Style: tt.style}).Notify(context.Background(), note); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: wrote %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalNotifier_Tmux(t *testing.T) {
+	note := Notification{Title: "crabhook: Bash", Message: "ls"}
+	tests := []struct {
+		style TerminalStyle
+		want  string
+	}{
+		{TerminalBell, "\x07"},
+		{TerminalOSC9, "\x1bPtmux;\x1b\x1b]9;crabhook: Bash: ls\x07\x1b\\"},
+		{TerminalOSC777, "\x1bPtmux;\x1b\x1b]777;notify;crabhook: Bash;ls\x07\x1b\\"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := (&TerminalNotifier{W: &buf, Style: tt.style, Tmux: true}).Notify(context.Background(), note); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: wrote %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestCommandNotifier(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	n := NewShellNotifier(`printf '%s|%s|%s|%s\n' "$1" "$2" "$CRABHOOK_TOOL_NAME" "$CRABHOOK_SESSION_ID" > ` + out)
+
+	note := NewNotification(&pb.PermissionRequest{ToolName: "Read", ToolInputJson: `{"file_path":"/etc/hosts"}`})
+	if err := n.Notify(context.Background(), note); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "crabhook: Read is waiting for approval|/etc/hosts|Read|\n"; got != want {
+		t.Errorf("command saw %q, want %q", got, want)
+	}
+
+	err = NewShellNotifier("echo broken >&2; exit 3").Notify(context.Background(), note)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("err = %v, want command failure with its stderr", err)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 1, hour, minute, 0, 0, time.Local) }
+
+	overnight, err := ParseQuietHours("22:00-07:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	daytime, err := ParseQuietHours("12:00-13:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		quiet QuietHours
+		t     time.Time
+		want  bool
+	}{
+		{overnight, at(23, 0), true},
+		{overnight, at(3, 0), true},
+		{overnight, at(7, 30), false},
+		{overnight, at(12, 0), false},
+		{daytime, at(12, 30), true},
+		{daytime, at(13, 0), false},
+		{QuietHours{}, at(12, 0), false},
+	}
+	for _, tt := range tests {
+		if got := tt.quiet.Contains(tt.t); got != tt.want {
+			t.Errorf("%v.Contains(%s) = %v, want %v", tt.quiet, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"22:00", "25:00-07:00", "22:00-7pm"} {
+		if _, err := ParseQuietHours(s); err == nil {
+			t.Errorf("ParseQuietHours(%q) should fail", s)
+		}
+	}
+}
+
+// recordingNotifier records the notifications it receives.
+type recordingNotifier struct {
+	mu    sync.Mutex
+	notes []Notification
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notes = append(r.notes, n)
+	return nil
+}
+
+func (r *recordingNotifier) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.notes)
+}
+
+func TestNotifications_RateLimitAndQuietHours(t *testing.T) {
+	rec := &recordingNotifier{}
+	n := NewNotifications(rec, nil)
+	n.MinInterval = time.Minute
+	n.Quiet = QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	n.now = func() time.Time { return now }
+
+	note := Notification{Title: "t"}
+	for _, step := range []struct {
+		advance time.Duration
+		want    int
+	}{
+		{0, 1},
+		{30 * time.Second, 1}, // rate limited
+		{31 * time.Second, 2},
+		{11 * time.Hour, 2}, // 23:01, quiet
+		{9 * time.Hour, 3},  // 08:01
+	} {
+		now = now.Add(step.advance)
+		if err := n.Notify(context.Background(), note); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.count(); got != step.want {
+			t.Fatalf("at %s: %d notifications, want %d", now.Format("15:04:05"), got, step.want)
+		}
+	}
+}
+
+func TestServer_NotifiesPromptedRequests(t *testing.T) {
+	policy, err := LoadPolicy(writePolicyFile(t, "policy.yaml", testPolicyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recordingNotifier{}
+	srv, err := New(Config{Address: "localhost:0", Prompter: &countingPrompter{}, Policy: policy, Notifier: rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	for _, cmd := range []string{"git status", "make"} {
+		if _, err := srv.HandlePermissionRequest(context.Background(), &pb.PermissionRequest{
+			ToolName:      "Bash",
+			ToolInputJson: `{"command":"` + cmd + `"}`,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only "make" reached the prompter; notifications are sent in the
+	// background.
+	deadline := time.Now().Add(5 * time.Second)
+	for rec.count() < 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.notes) != 1 || rec.notes[0].Message != "make" {
+		t.Errorf("notifications = %+v, want one for make", rec.notes)
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
+)
+
+// ClassifyPath classifies the file tool target of req with guard.
+// It returns false if guard is nil or req is not a file tool request.
+func ClassifyPath(guard *pathguard.Guard, req *pb.PermissionRequest) (pathguard.Target, bool) {
+	if guard == nil {
+		return pathguard.Target{}, false
+	}
+	return guard.Classify(model.ToolName(req.GetToolName()), req.GetCwd(), json.RawMessage(req.GetToolInputJson()))
+}
+
+// checkPathGuard classifies req with the server's path guard. It returns
+// escalated=true if the target is a write outside the workspace or a
+// sensitive path; resp is then the automatic response, or nil if the
+// request must be forwarded to the Prompter regardless of the Policy.
+func (s *Server) checkPathGuard(req *pb.PermissionRequest) (resp *pb.PermissionResponse, escalated bool) {
+	target, ok := ClassifyPath(s.pathGuard, req)
+	if !ok || !target.Escalate() {
+		return nil, false
+	}
+
+	var decision pb.PermissionDecision
+	switch s.pathGuardAction {
+	case RuleActionPrompt:
+		return nil, true
+	case RuleActionAsk:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ASK
+	default:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_DENY
+	}
+	return BuildPermissionResponse(req, decision, pathGuardReason(target)), true
+}
+
+func pathGuardReason(t pathguard.Target) string {
+	if t.Sensitive != "" {
+		return fmt.Sprintf("path guard: %s is a sensitive path (%s)", t.Resolved, t.Sensitive)
+	}
+	return fmt.Sprintf("path guard: write to %s outside the workspace", t.Resolved)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
+)
+
+func TestServer_HandlePermissionRequestPathGuard(t *testing.T) {
+	workspace := t.TempDir()
+	policy := &Policy{Rules: []Rule{{Name: "allow-all", Action: RuleActionAllow}}}
+
+	tests := []struct {
+		name       string
+		action     RuleAction
+		input      string
+		want       pb.PermissionDecision
+		wantPrompt bool
+	}{
+		{"write inside workspace uses policy", RuleActionDeny, `{"file_path":"main.go"}`, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, false},
+		{"write outside denied", RuleActionDeny, `{"file_path":"/etc/passwd"}`, pb.PermissionDecision_PERMISSION_DECISION_DENY, false},
+		{"sensitive write asked", RuleActionAsk, `{"file_path":".env"}`, pb.PermissionDecision_PERMISSION_DECISION_ASK, false},
+		{"write outside escalated to prompter", RuleActionPrompt, `{"file_path":"/etc/passwd"}`, pb.PermissionDecision_PERMISSION_DECISION_DENY, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompter := &countingPrompter{}
+			srv, err := New(Config{
+				Address:         "localhost:0",
+				Prompter:        prompter,
+				Policy:          policy,
+				PathGuard:       pathguard.New(),
+				PathGuardAction: tt.action,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer srv.Stop()
+
+			resp, err := srv.HandlePermissionRequest(context.Background(), &pb.PermissionRequest{
+				HookEventName: "PreToolUse",
+				ToolName:      "Write",
+				Cwd:           workspace,
+				ToolInputJson: tt.input,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.GetHookSpecificOutput().GetPermissionDecision(); got != tt.want {
+				t.Errorf("decision = %v, want %v", got, tt.want)
+			}
+			if got := prompter.calls == 1; got != tt.wantPrompt {
+				t.Errorf("prompted = %v, want %v", got, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPathGuardAction(t *testing.T) {
+	if _, err := New(Config{Address: "localhost:0", PathGuardAction: "allow"}); err == nil {
+		t.Error("expected error")
+	}
+}
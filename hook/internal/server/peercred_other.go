@@ -0,0 +1,13 @@
+//go:build !linux
+
+package server
+
+import (
+	"errors"
+	"net"
+)
+
+// peerCredentials is only implemented on Linux (SO_PEERCRED).
+func peerCredentials(_ *net.UnixConn) (*PeerCredentials, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/sdk/bashcmd"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleAction is the action taken when a policy rule matches.
+type RuleAction string
+
+const (
+	// RuleActionAllow auto-approves the tool execution.
+	RuleActionAllow RuleAction = "allow"
+	// RuleActionDeny blocks the tool execution.
+	RuleActionDeny RuleAction = "deny"
+	// RuleActionAsk defers to Claude Code's native permission prompt.
+	RuleActionAsk RuleAction = "ask"
+	// RuleActionPrompt stops rule evaluation and forwards the request to the Prompter.
+	RuleActionPrompt RuleAction = "prompt"
+)
+
+// Patterns is a list of glob patterns. In policy files it may be written
+// either as a single string or as a list of strings.
+//
+// Patterns use a simple glob syntax where '*' matches any sequence of
+// characters (including '/') and '?' matches exactly one character.
+type Patterns []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *Patterns) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = Patterns{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*p = list
+	return nil
+}
+
+// UnmarshalTOML implements toml.Unmarshaler.
+func (p *Patterns) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*p = Patterns{v}
+	case []any:
+		list := make(Patterns, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("pattern must be a string, got %T", e)
+			}
+			list = append(list, s)
+		}
+		*p = list
+	default:
+		return fmt.Errorf("patterns must be a string or a list of strings, got %T", v)
+	}
+	return nil
+}
+
+// Match reports whether s matches any of the patterns.
+// An empty Patterns matches everything.
+func (p Patterns) Match(s string) bool {
+	if len(p) == 0 {
+		return true
+	}
+	for _, pattern := range p {
+		if bashcmd.MatchGlob(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule is a single policy rule. All non-empty conditions must match for the
+// rule to fire.
+type Rule struct {
+	// Name identifies the rule in decision reasons and audit logs.
+	Name string `yaml:"name" toml:"name"`
+	// Tools matches PermissionRequest.ToolName.
+	Tools Patterns `yaml:"tools" toml:"tools"`
+	// Cwd matches PermissionRequest.Cwd.
+	Cwd Patterns `yaml:"cwd" toml:"cwd"`
+	// Sessions matches PermissionRequest.SessionId.
+	Sessions Patterns `yaml:"sessions" toml:"sessions"`
+	// Input matches top-level fields of the parsed tool input, keyed by JSON field name.
+	// Non-string values are matched against their JSON encoding.
+	Input map[string]Patterns `yaml:"input" toml:"input"`
+	// Commands restricts the rule to Bash requests whose every program
+	// invocation matches one of these command prefixes (see bashcmd.Analysis.Allows).
+	Commands []string `yaml:"commands" toml:"commands"`
+	// Action is the action taken when the rule matches.
+	Action RuleAction `yaml:"action" toml:"action"`
+	// Reason is an optional explanation appended to the decision reason.
+	Reason string `yaml:"reason" toml:"reason"`
+}
+
+// Policy is an ordered list of rules evaluated in front of the Prompter.
+// The first matching rule wins.
+type Policy struct {
+	Rules []Rule `yaml:"rules" toml:"rules"`
+}
+
+// PolicyDecision is the result of a matching policy rule.
+type PolicyDecision struct {
+	// Rule is the rule that fired.
+	Rule *Rule
+	// Action is the action of the rule.
+	Action RuleAction
+}
+
+// LoadPolicy reads a policy file. The format is chosen by extension:
+// ".toml" for TOML, anything else is parsed as YAML.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file %q: %w", path, err)
+	}
+
+	var policy Policy
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		md, err := toml.NewDecoder(bytes.NewReader(data)).Decode(&policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy file %q: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, key := range undecoded {
+				keys[i] = key.String()
+			}
+			return nil, fmt.Errorf("failed to parse policy file %q: unknown keys %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&policy); err != nil {
+			return nil, fmt.Errorf("failed to parse policy file %q: %w", path, err)
+		}
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %q: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// Validate checks that every rule has a known action and assigns default
+// names to unnamed rules.
+func (p *Policy) Validate() error {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		switch r.Action {
+		case RuleActionAllow, RuleActionDeny, RuleActionAsk, RuleActionPrompt:
+		default:
+			return fmt.Errorf("rule %d: invalid action %q", i, r.Action)
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the decision of the first rule matching req.
+// It returns false if p is nil or no rule matches.
+func (p *Policy) Evaluate(req *pb.PermissionRequest) (PolicyDecision, bool) {
+	if p == nil {
+		return PolicyDecision{}, false
+	}
+
+	var input map[string]any
+	parsed := false
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !r.Tools.Match(req.GetToolName()) ||
+			!r.Cwd.Match(req.GetCwd()) ||
+			!r.Sessions.Match(req.GetSessionId()) {
+			continue
+		}
+		if len(r.Input) > 0 || len(r.Commands) > 0 {
+			if !parsed {
+				input = parseInputFields(req.GetToolInputJson())
+				parsed = true
+			}
+			if !r.matchInput(input) || !r.matchCommands(req.GetToolName(), input) {
+				continue
+			}
+		}
+		return PolicyDecision{Rule: r, Action: r.Action}, true
+	}
+	return PolicyDecision{}, false
+}
+
+func (r *Rule) matchInput(input map[string]any) bool {
+	for field, patterns := range r.Input {
+		v, ok := input[field]
+		if !ok {
+			return false
+		}
+		if !patterns.Match(inputFieldString(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchCommands reports whether the Bash command in input consists only of
+// invocations allowed by r.Commands. Commands that fail to parse never match.
+func (r *Rule) matchCommands(toolName string, input map[string]any) bool {
+	if len(r.Commands) == 0 {
+		return true
+	}
+	if toolName != string(model.ToolNameBash) {
+		return false
+	}
+	command, ok := input["command"].(string)
+	if !ok {
+		return false
+	}
+	a, err := bashcmd.Analyze(command)
+	if err != nil {
+		return false
+	}
+	allowed, _ := a.Allows(r.Commands)
+	return allowed
+}
+
+// Reason returns the permission decision reason recorded for this decision.
+func (d PolicyDecision) Reason() string {
+	reason := fmt.Sprintf("policy rule %q", d.Rule.Name)
+	if d.Rule.Reason != "" {
+		reason += ": " + d.Rule.Reason
+	}
+	return reason
+}
+
+// Response builds the PermissionResponse for this decision.
+// It must not be called for RuleActionPrompt.
+func (d PolicyDecision) Response(req *pb.PermissionRequest) *pb.PermissionResponse {
+	var decision pb.PermissionDecision
+	switch d.Action {
+	case RuleActionAllow:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ALLOW
+	case RuleActionAsk:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ASK
+	default:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_DENY
+	}
+	return BuildPermissionResponse(req, decision, d.Reason())
+}
+
+func parseInputFields(toolInputJSON string) map[string]any {
+	if toolInputJSON == "" {
+		return nil
+	}
+	var input map[string]any
+	if err := json.Unmarshal([]byte(toolInputJSON), &input); err != nil {
+		return nil
+	}
+	return input
+}
+
+func inputFieldString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/diffview"
 )
 
 // PlainPrompter handles interactive prompts for permission decisions via plain text stdin/stdout.
@@ -39,8 +40,10 @@ func (p *PlainPrompter) Prompt(ctx context.Context, req *pb.PermissionRequest) (
 	}
 	fmt.Fprintf(p.writer, "%s\n", strings.Repeat("-", 60))
 
-	// Pretty print the tool input
-	if req.ToolInputJson != "" {
+	// Show file edits as a diff, other tool input as pretty printed JSON
+	if d, ok := diffview.ForRequest(req); ok {
+		fmt.Fprintf(p.writer, "Diff:\n%s", d.Text(0))
+	} else if req.ToolInputJson != "" {
 		var prettyJSON map[string]any
 		if err := json.Unmarshal([]byte(req.ToolInputJson), &prettyJSON); err == nil {
 			formatted, _ := json.MarshalIndent(prettyJSON, "", "  ")
@@ -116,7 +119,7 @@ func (p *PlainPrompter) Prompt(ctx context.Context, req *pb.PermissionRequest) (
 
 // promptAskUserQuestion handles AskUserQuestion tool inputs.
 func (p *PlainPrompter) promptAskUserQuestion(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	input, err := ParseAskUserInput(req.ToolInputJson)
+	input, err := AskUserInputFromRequest(req)
 	if err != nil {
 		fmt.Fprintf(p.writer, "  (Failed to parse AskUserQuestion input, falling back to standard prompt)\n")
 		return p.promptStandard(ctx, req)
@@ -189,7 +192,7 @@ func (p *PlainPrompter) promptAskUserQuestion(ctx context.Context, req *pb.Permi
 
 // promptExitPlanMode handles ExitPlanMode tool inputs with a dedicated plan approval prompt.
 func (p *PlainPrompter) promptExitPlanMode(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	input, err := ParseExitPlanModeInput(req.ToolInputJson)
+	input, err := ExitPlanModeInputFromRequest(req)
 	if err != nil {
 		fmt.Fprintf(p.writer, "  (Failed to parse ExitPlanMode input, falling back to standard prompt)\n")
 		return p.promptStandard(ctx, req)
@@ -37,6 +37,24 @@ func ParseAskUserInput(toolInputJSON string) (AskUserQuestionInput, error) {
 	return input, nil
 }
 
+// AskUserInputFromRequest returns the AskUserQuestion input of req, taken from
+// its typed tool input if set and parsed from tool_input_json otherwise.
+func AskUserInputFromRequest(req *pb.PermissionRequest) (AskUserQuestionInput, error) {
+	typed := req.GetToolInput().GetAskUserQuestion()
+	if typed == nil {
+		return ParseAskUserInput(req.GetToolInputJson())
+	}
+	input := AskUserQuestionInput{Answers: typed.GetAnswers()}
+	for _, q := range typed.GetQuestions() {
+		question := AskQuestion{Question: q.GetQuestion(), Header: q.GetHeader(), MultiSelect: q.GetMultiSelect()}
+		for _, o := range q.GetOptions() {
+			question.Options = append(question.Options, AskOption{Label: o.GetLabel(), Description: o.GetDescription()})
+		}
+		input.Questions = append(input.Questions, question)
+	}
+	return input, nil
+}
+
 // ResolveAnswers converts numeric choices to option labels.
 func ResolveAnswers(choice string, options []AskOption) string {
 	parts := strings.Split(choice, ",")
@@ -75,6 +93,20 @@ func ParseExitPlanModeInput(toolInputJSON string) (ExitPlanModeInput, error) {
 	return input, nil
 }
 
+// ExitPlanModeInputFromRequest returns the ExitPlanMode input of req, taken
+// from its typed tool input if set and parsed from tool_input_json otherwise.
+func ExitPlanModeInputFromRequest(req *pb.PermissionRequest) (ExitPlanModeInput, error) {
+	typed := req.GetToolInput().GetExitPlanMode()
+	if typed == nil {
+		return ParseExitPlanModeInput(req.GetToolInputJson())
+	}
+	var input ExitPlanModeInput
+	for _, p := range typed.GetAllowedPrompts() {
+		input.AllowedPrompts = append(input.AllowedPrompts, AllowedPrompt{Tool: p.GetTool(), Prompt: p.GetPrompt()})
+	}
+	return input, nil
+}
+
 // BuildPermissionResponse builds a PermissionResponse for a standard permission decision.
 func BuildPermissionResponse(req *pb.PermissionRequest, decision pb.PermissionDecision, reason string) *pb.PermissionResponse {
 	return &pb.PermissionResponse{
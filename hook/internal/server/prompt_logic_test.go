@@ -2,9 +2,11 @@ package server
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
 )
 
 func TestResolveAnswers(t *testing.T) {
@@ -57,6 +59,40 @@ func TestParseAskUserInput_Invalid(t *testing.T) {
 	}
 }
 
+func TestAskUserInputFromRequest(t *testing.T) {
+	// The typed input wins over tool_input_json.
+	req := &pb.PermissionRequest{
+		ToolName:      "AskUserQuestion",
+		ToolInputJson: "not json",
+		ToolInput: &sdkpb.ToolInput{Input: &sdkpb.ToolInput_AskUserQuestion{AskUserQuestion: &sdkpb.AskUserQuestionInput{
+			Questions: []*sdkpb.Question{{
+				Question:    "Pick one?",
+				Header:      "Q",
+				Options:     []*sdkpb.QuestionOption{{Label: "A", Description: "a"}, {Label: "B"}},
+				MultiSelect: true,
+			}},
+		}}},
+	}
+	input, err := AskUserInputFromRequest(req)
+	if err != nil {
+		t.Fatalf("AskUserInputFromRequest error: %v", err)
+	}
+	want := []AskQuestion{{
+		Question:    "Pick one?",
+		Header:      "Q",
+		Options:     []AskOption{{Label: "A", Description: "a"}, {Label: "B"}},
+		MultiSelect: true,
+	}}
+	if !reflect.DeepEqual(input.Questions, want) {
+		t.Errorf("questions = %+v, want %+v", input.Questions, want)
+	}
+
+	req.ToolInput = nil
+	if _, err := AskUserInputFromRequest(req); err == nil {
+		t.Error("expected error parsing tool_input_json without a typed input")
+	}
+}
+
 func TestBuildPermissionResponse(t *testing.T) {
 	req := &pb.PermissionRequest{
 		HookEventName: "PreToolUse",
@@ -127,6 +163,24 @@ func TestParseExitPlanModeInput_Invalid(t *testing.T) {
 	}
 }
 
+func TestExitPlanModeInputFromRequest(t *testing.T) {
+	req := &pb.PermissionRequest{
+		ToolName:      "ExitPlanMode",
+		ToolInputJson: "not json",
+		ToolInput: &sdkpb.ToolInput{Input: &sdkpb.ToolInput_ExitPlanMode{ExitPlanMode: &sdkpb.ExitPlanModeInput{
+			Plan:           "1. test",
+			AllowedPrompts: []*sdkpb.AllowedPrompt{{Tool: "Bash", Prompt: "run tests"}},
+		}}},
+	}
+	input, err := ExitPlanModeInputFromRequest(req)
+	if err != nil {
+		t.Fatalf("ExitPlanModeInputFromRequest error: %v", err)
+	}
+	if want := []AllowedPrompt{{Tool: "Bash", Prompt: "run tests"}}; !reflect.DeepEqual(input.AllowedPrompts, want) {
+		t.Errorf("allowedPrompts = %+v, want %+v", input.AllowedPrompts, want)
+	}
+}
+
 func TestBuildAskUserResponse(t *testing.T) {
 	req := &pb.PermissionRequest{
 		HookEventName: "PreToolUse",
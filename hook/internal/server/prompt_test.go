@@ -279,3 +279,29 @@ func mapKeys(m map[string]string) []string {
 	}
 	return keys
 }
+
+func TestPlainPrompter_EditDiff(t *testing.T) {
+	var writer bytes.Buffer
+	prompter := NewPlainPrompter(strings.NewReader("a\n"), &writer)
+
+	req := &pb.PermissionRequest{
+		HookEventName: "PreToolUse",
+		ToolName:      "Edit",
+		ToolInputJson: `{"file_path":"/nonexistent/main.go","old_string":"foo()","new_string":"bar()"}`,
+		SessionId:     "test-session",
+	}
+	resp, err := prompter.Prompt(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Prompt error: %v", err)
+	}
+	if resp.HookSpecificOutput.PermissionDecision != pb.PermissionDecision_PERMISSION_DECISION_ALLOW {
+		t.Error("expected ALLOW decision")
+	}
+	output := writer.String()
+	if !strings.Contains(output, "Diff:\n--- /nonexistent/main.go\n@@ -1,1 +1,1 @@\n-foo()\n+bar()\n") {
+		t.Errorf("expected unified diff in output:\n%s", output)
+	}
+	if strings.Contains(output, "Input:") {
+		t.Error("file edits should not print the JSON input")
+	}
+}
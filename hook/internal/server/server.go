@@ -3,14 +3,27 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	pbv2 "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v2"
 	impl "github.com/ngicks/crabswarm/hook/api/impl/go/permission/v1"
+	implv2 "github.com/ngicks/crabswarm/hook/api/impl/go/permission/v2"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Prompter is the interface for prompting the user for permission decisions.
@@ -25,11 +38,22 @@ type Server struct {
 	listener     net.Listener
 	program      *tea.Program
 	auditHandler AuditHandler
+	policy       *Policy
+	grants       *GrantStore
+	history      *HistoryStore
+	hookHandler  HookHandler
+
+	pathGuard       *pathguard.Guard
+	pathGuardAction RuleAction
+
+	timeouts *PromptTimeouts
+	notifier Notifier
 }
 
 // Config holds the server configuration.
 type Config struct {
-	// Address is the address to listen on (e.g., "localhost:50051").
+	// Address is the address to listen on: a TCP "host:port" (e.g.,
+	// "localhost:50051") or a Unix socket "unix:///path/to.sock".
 	Address string
 	// Prompter is the prompter to use for handling permission requests.
 	// If nil, a plain text prompter is created using Reader/Writer.
@@ -43,13 +67,57 @@ type Config struct {
 	Writer io.Writer
 	// AuditHandler handles audit events. If nil, a NoOpAuditHandler is used.
 	AuditHandler AuditHandler
+	// Policy is evaluated before the Prompter. Requests matching no rule
+	// (or a "prompt" rule) are forwarded to the Prompter. May be nil.
+	Policy *Policy
+	// HookHandler handles hook events other than permission requests.
+	// If nil, such events are observed (via audit events) and answered
+	// with an empty output.
+	HookHandler HookHandler
+	// History records every permission request and hook event the server
+	// handles, once its outcome is known, and serves QueryHistory. Audit
+	// events sent by clients are not recorded. May be nil.
+	History *HistoryStore
+	// Grants holds remembered allow decisions, consulted before prompting:
+	// after PathGuard and the Policy, for requests matching no rule (or a
+	// "prompt" rule). May be nil.
+	Grants *GrantStore
+	// PathGuard classifies the targets of file tools. Writes outside the
+	// workspace and accesses to sensitive paths are handled according to
+	// PathGuardAction before the Policy is consulted. May be nil.
+	PathGuard *pathguard.Guard
+	// PathGuardAction is the action for requests escalated by PathGuard:
+	// "deny", "ask", or "prompt" (forward to the Prompter, bypassing the
+	// Policy). Defaults to "prompt".
+	PathGuardAction RuleAction
+	// TLS enables TLS on the listener. Set ClientCAs and ClientAuth (see
+	// ServerTLSConfig) to require client certificates. May be nil.
+	TLS *tls.Config
+	// Token, if non-empty, is a shared secret every RPC must present as a
+	// bearer token (see TokenCredentials).
+	Token string
+	// Timeouts decides prompted requests nobody answers in time. The
+	// deadline is passed to the Prompter through its context. May be nil.
+	Timeouts *PromptTimeouts
+	// Notifier is called in the background for every request forwarded to
+	// the Prompter. Failures are logged. May be nil.
+	Notifier Notifier
 }
 
 // New creates a new Server with the given configuration.
 func New(cfg Config) (*Server, error) {
-	listener, err := net.Listen("tcp", cfg.Address)
+	pathGuardAction := cfg.PathGuardAction
+	switch pathGuardAction {
+	case "":
+		pathGuardAction = RuleActionPrompt
+	case RuleActionDeny, RuleActionAsk, RuleActionPrompt:
+	default:
+		return nil, fmt.Errorf("invalid path guard action %q", pathGuardAction)
+	}
+
+	listener, err := Listen(cfg.Address)
 	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.Address, err)
+		return nil, err
 	}
 
 	prompter := cfg.Prompter
@@ -62,7 +130,17 @@ func New(cfg Config) (*Server, error) {
 		auditHandler = &NoOpAuditHandler{}
 	}
 
-	grpcServer := grpc.NewServer()
+	var opts []grpc.ServerOption
+	if cfg.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(cfg.TLS)))
+	}
+	if cfg.Token != "" {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(TokenUnaryInterceptor(cfg.Token)),
+			grpc.ChainStreamInterceptor(TokenStreamInterceptor(cfg.Token)),
+		)
+	}
+	grpcServer := grpc.NewServer(opts...)
 
 	server := &Server{
 		prompter:     prompter,
@@ -70,25 +148,183 @@ func New(cfg Config) (*Server, error) {
 		listener:     listener,
 		program:      cfg.Program,
 		auditHandler: auditHandler,
+		policy:       cfg.Policy,
+		grants:       cfg.Grants,
+		history:      cfg.History,
+		hookHandler:  cfg.HookHandler,
+
+		pathGuard:       cfg.PathGuard,
+		pathGuardAction: pathGuardAction,
+
+		timeouts: cfg.Timeouts,
+		notifier: cfg.Notifier,
 	}
 
-	// Register the permission service
+	// Register the permission service. v1 stays registered for crabhook
+	// binaries that predate v2; both versions are decided by the same handler.
 	service := impl.NewService(server, server)
 	pb.RegisterPermissionServiceServer(grpcServer, service)
+	pbv2.RegisterPermissionServiceServer(grpcServer, implv2.NewService(server))
 
 	return server, nil
 }
 
 // HandlePermissionRequest implements the impl.PermissionHandler interface.
 func (s *Server) HandlePermissionRequest(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
-	return s.prompter.Prompt(ctx, req)
+	start := time.Now()
+	resp, err := s.decide(ctx, req)
+	s.record(ctx, &pb.AuditEvent{Request: req, Response: resp}, start, err)
+	return resp, err
+}
+
+// decide runs req through the path guard, the policy, the grants and
+// finally the Prompter.
+func (s *Server) decide(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	if resp, escalated := s.checkPathGuard(req); escalated {
+		if resp != nil {
+			return withSource(resp, pb.DecisionSource_DECISION_SOURCE_PATH_GUARD), nil
+		}
+		return s.prompt(ctx, req)
+	}
+	if d, ok := s.policy.Evaluate(req); ok && d.Action != RuleActionPrompt {
+		return withSource(d.Response(req), pb.DecisionSource_DECISION_SOURCE_POLICY), nil
+	}
+	if g, ok := s.grants.Match(req); ok {
+		resp := BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, g.Reason())
+		return withSource(resp, pb.DecisionSource_DECISION_SOURCE_GRANT), nil
+	}
+	return s.prompt(ctx, req)
+}
+
+// prompt forwards req to the Prompter. Responses without a decision source
+// are attributed to a human. If the request's timeout rule expires first, the
+// rule decides it.
+func (s *Server) prompt(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	promptCtx := ctx
+	rule, ok := s.timeouts.Rule(req.GetToolName())
+	if ok {
+		var cancel context.CancelFunc
+		promptCtx, cancel = withTimeout(ctx, rule)
+		defer cancel()
+	}
+
+	s.notify(req)
+	resp, err := s.prompter.Prompt(promptCtx, req)
+	if err != nil {
+		if ok && ctx.Err() == nil && errors.Is(context.Cause(promptCtx), ErrPromptTimeout) {
+			return rule.Decide(req), nil
+		}
+		return nil, err
+	}
+	if resp.GetDecisionSource() == pb.DecisionSource_DECISION_SOURCE_UNSPECIFIED {
+		resp = withSource(resp, pb.DecisionSource_DECISION_SOURCE_HUMAN)
+	}
+	return resp, nil
+}
+
+// notifyTimeout bounds a single notification.
+const notifyTimeout = 10 * time.Second
+
+// notify alerts the operator of req without delaying the prompt.
+func (s *Server) notify(req *pb.PermissionRequest) {
+	if s.notifier == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := s.notifier.Notify(ctx, NewNotification(req)); err != nil {
+			slog.Warn("failed to send notification", "error", err)
+		}
+	}()
+}
+
+func withSource(resp *pb.PermissionResponse, source pb.DecisionSource) *pb.PermissionResponse {
+	if resp != nil {
+		resp.DecisionSource = source
+	}
+	return resp
+}
+
+// record adds a request the server handled, with its outcome, to the
+// history. Unlike the audit events clients send afterwards, this covers
+// requests whose hook was killed and cannot be forged by a client.
+func (s *Server) record(ctx context.Context, event *pb.AuditEvent, start time.Time, err error) {
+	if s.history == nil {
+		return
+	}
+	event.Timestamp = timestamppb.New(start)
+	event.Latency = durationpb.New(time.Since(start))
+	event.Peer = clientCredentials(ctx)
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if err := s.history.HandleAuditEvent(ctx, event); err != nil {
+		slog.Warn("failed to record history", "error", err)
+	}
+}
+
+// HandleHook implements the impl.HookHandler interface.
+func (s *Server) HandleHook(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error) {
+	start := time.Now()
+	var resp *pb.HookResponse
+	var err error
+	if s.hookHandler != nil {
+		resp, err = s.hookHandler.HandleHook(ctx, req)
+	} else {
+		resp = BuildHookResponse(req, "")
+	}
+	s.record(ctx, &pb.AuditEvent{Request: hookAuditRequest(req), HookOutput: resp.GetOutput()}, start, err)
+	return resp, err
+}
+
+// ListGrants implements the impl.GrantHandler interface.
+func (s *Server) ListGrants(_ context.Context) ([]*pb.Grant, error) {
+	if s.grants == nil {
+		return nil, status.Error(codes.FailedPrecondition, "grants are not enabled")
+	}
+	var out []*pb.Grant
+	for _, g := range s.grants.List() {
+		out = append(out, g.ToProto())
+	}
+	return out, nil
+}
+
+// RevokeGrant implements the impl.GrantHandler interface.
+func (s *Server) RevokeGrant(_ context.Context, id string) (bool, error) {
+	if s.grants == nil {
+		return false, status.Error(codes.FailedPrecondition, "grants are not enabled")
+	}
+	return s.grants.Revoke(id)
+}
+
+// QueryHistory implements the impl.HistoryHandler interface.
+func (s *Server) QueryHistory(_ context.Context, req *pb.QueryHistoryRequest) ([]*pb.AuditEvent, error) {
+	if s.history == nil {
+		return nil, status.Error(codes.FailedPrecondition, "history is not enabled")
+	}
+	return s.history.Query(HistoryQueryFromProto(req))
 }
 
 // HandleAuditEvent implements the impl.AuditHandler interface.
 func (s *Server) HandleAuditEvent(ctx context.Context, event *pb.AuditEvent) error {
+	if cred := clientCredentials(ctx); cred != nil {
+		event.Peer = cred
+	}
 	return s.auditHandler.HandleAuditEvent(ctx, event)
 }
 
+// clientCredentials returns the credentials of the client of ctx, or nil if
+// it is not connected over a Unix socket.
+func clientCredentials(ctx context.Context) *pb.PeerCredentials {
+	if p, ok := peer.FromContext(ctx); ok {
+		if cred, ok := p.Addr.(*PeerCredentials); ok {
+			return &pb.PeerCredentials{Pid: cred.PID, Uid: cred.UID, Gid: cred.GID}
+		}
+	}
+	return nil
+}
+
 // Serve starts the server and blocks until stopped.
 // In TUI mode, gRPC runs in a background goroutine and bubbletea runs as the main loop.
 // In plain mode, gRPC runs directly.
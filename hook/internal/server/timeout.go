@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// ErrPromptTimeout is the cancellation cause seen by the Prompter when a
+// request is decided by its PromptTimeouts rule.
+var ErrPromptTimeout = errors.New("prompt timed out")
+
+// TimeoutRule decides a prompted request automatically if nobody answers it
+// within Timeout.
+type TimeoutRule struct {
+	// Timeout is how long the request is prompted. Zero means forever.
+	Timeout time.Duration
+	// Action is RuleActionAllow, RuleActionDeny or RuleActionAsk.
+	Action RuleAction
+}
+
+// ParseTimeoutRule parses "<duration>:<action>" (e.g. "2m:deny"). The action
+// defaults to deny; "0" or "off" disables the timeout.
+func ParseTimeoutRule(s string) (TimeoutRule, error) {
+	if s == "" || s == "0" || s == "off" {
+		return TimeoutRule{}, nil
+	}
+	d, action, ok := strings.Cut(s, ":")
+	if !ok {
+		action = string(RuleActionDeny)
+	}
+	timeout, err := time.ParseDuration(d)
+	if err != nil || timeout < 0 {
+		return TimeoutRule{}, fmt.Errorf("invalid timeout %q: want a duration like 2m or 30s", s)
+	}
+	switch a := RuleAction(strings.ToLower(action)); a {
+	case RuleActionAllow, RuleActionDeny, RuleActionAsk:
+		return TimeoutRule{Timeout: timeout, Action: a}, nil
+	default:
+		return TimeoutRule{}, fmt.Errorf("invalid timeout action %q (want allow, deny or ask)", action)
+	}
+}
+
+// String formats r as accepted by ParseTimeoutRule.
+func (r TimeoutRule) String() string {
+	if r.Timeout == 0 {
+		return "off"
+	}
+	return r.Timeout.String() + ":" + string(r.Action)
+}
+
+// PromptTimeouts bounds how long prompted requests wait for an answer.
+type PromptTimeouts struct {
+	// Default applies to requests without a tool or category override.
+	Default TimeoutRule
+	// Tools overrides Default per tool name (e.g. "Bash").
+	Tools map[string]TimeoutRule
+	// Categories overrides Default per tool category (e.g. "file_path").
+	Categories map[model.ToolCategory]TimeoutRule
+}
+
+// NewPromptTimeouts builds PromptTimeouts from the default rule and
+// overrides keyed by tool name or tool category name (e.g. "Bash=2m:deny",
+// "file_path=10s:ask"). Tool names take precedence over categories.
+func NewPromptTimeouts(defaultRule string, overrides map[string]string) (*PromptTimeouts, error) {
+	def, err := ParseTimeoutRule(defaultRule)
+	if err != nil {
+		return nil, err
+	}
+	t := &PromptTimeouts{
+		Default:    def,
+		Tools:      make(map[string]TimeoutRule),
+		Categories: make(map[model.ToolCategory]TimeoutRule),
+	}
+	for key, value := range overrides {
+		rule, err := ParseTimeoutRule(value)
+		if err != nil {
+			return nil, fmt.Errorf("timeout for %s: %w", key, err)
+		}
+		if category := model.ToolCategory(key); slices.Contains(toolCategories, category) {
+			t.Categories[category] = rule
+		} else {
+			t.Tools[key] = rule
+		}
+	}
+	return t, nil
+}
+
+// Rule returns the rule for toolName. ok is false if the request may wait
+// forever.
+//
+// AskUserQuestion and ExitPlanMode are never allowed by timeout: allowing
+// them would submit no answers or approve an unread plan. An allow rule is
+// turned into ask for them, deferring to Claude Code's own prompt.
+func (t *PromptTimeouts) Rule(toolName string) (rule TimeoutRule, ok bool) {
+	if t == nil {
+		return TimeoutRule{}, false
+	}
+	rule, found := t.Tools[toolName]
+	if !found {
+		rule, found = t.Categories[model.ToolName(toolName).Category()]
+	}
+	if !found {
+		rule = t.Default
+	}
+	if rule.Action == RuleActionAllow && !allowableByTimeout(model.ToolName(toolName)) {
+		rule.Action = RuleActionAsk
+	}
+	return rule, rule.Timeout > 0
+}
+
+// allowableByTimeout reports whether requests of tool may be allowed
+// without anyone looking at them.
+func allowableByTimeout(tool model.ToolName) bool {
+	switch tool {
+	case model.ToolNameAskUserQuestion, model.ToolNameExitPlanMode:
+		return false
+	default:
+		return true
+	}
+}
+
+// Decide builds the automatic response for req after rule timed out.
+func (r TimeoutRule) Decide(req *pb.PermissionRequest) *pb.PermissionResponse {
+	decision := pb.PermissionDecision_PERMISSION_DECISION_DENY
+	switch r.Action {
+	case RuleActionAllow:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ALLOW
+	case RuleActionAsk:
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ASK
+	}
+	reason := fmt.Sprintf("no answer within %s, %s by timeout", r.Timeout, r.Action)
+	return withSource(BuildPermissionResponse(req, decision, reason), pb.DecisionSource_DECISION_SOURCE_TIMEOUT)
+}
+
+// timeoutKey is the context key of the timeout rule applied to a prompt.
+type timeoutKey struct{}
+
+type appliedTimeout struct {
+	deadline time.Time
+	action   RuleAction
+}
+
+// withTimeout bounds ctx by rule, cancelling it with ErrPromptTimeout.
+func withTimeout(ctx context.Context, rule TimeoutRule) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(rule.Timeout)
+	ctx = context.WithValue(ctx, timeoutKey{}, appliedTimeout{deadline: deadline, action: rule.Action})
+	return context.WithDeadlineCause(ctx, deadline, ErrPromptTimeout)
+}
+
+// PromptDeadline returns when a Prompter called with ctx stops being waited
+// for, and the action then taken if it is a PromptTimeouts rule rather than
+// the hook client's own deadline. ok is false if there is no deadline.
+func PromptDeadline(ctx context.Context) (deadline time.Time, action RuleAction, ok bool) {
+	deadline, ok = ctx.Deadline()
+	if !ok {
+		return time.Time{}, "", false
+	}
+	if t, found := ctx.Value(timeoutKey{}).(appliedTimeout); found && t.deadline.Equal(deadline) {
+		action = t.action
+	}
+	return deadline, action, true
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+func TestParseTimeoutRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    TimeoutRule
+		wantErr bool
+	}{
+		{in: "", want: TimeoutRule{}},
+		{in: "off", want: TimeoutRule{}},
+		{in: "2m", want: TimeoutRule{Timeout: 2 * time.Minute, Action: RuleActionDeny}},
+		{in: "10s:ask", want: TimeoutRule{Timeout: 10 * time.Second, Action: RuleActionAsk}},
+		{in: "1m:ALLOW", want: TimeoutRule{Timeout: time.Minute, Action: RuleActionAllow}},
+		{in: "1m:prompt", wantErr: true},
+		{in: "soon:deny", wantErr: true},
+		{in: "-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeoutRule(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimeoutRule(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimeoutRule(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPromptTimeouts_Rule(t *testing.T) {
+	timeouts, err := NewPromptTimeouts("5m:deny", map[string]string{
+		"Bash":      "2m:deny",
+		"file_path": "10s:ask",
+		"Write":     "off",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tool   string
+		want   TimeoutRule
+		wantOK bool
+	}{
+		{"Bash", TimeoutRule{2 * time.Minute, RuleActionDeny}, true},
+		{"Read", TimeoutRule{10 * time.Second, RuleActionAsk}, true},
+		{"Write", TimeoutRule{}, false},
+		{"WebFetch", TimeoutRule{5 * time.Minute, RuleActionDeny}, true},
+	}
+	for _, tt := range tests {
+		got, ok := timeouts.Rule(tt.tool)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Rule(%q) = %v, %v, want %v, %v", tt.tool, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if _, ok := (*PromptTimeouts)(nil).Rule("Bash"); ok {
+		t.Error("nil PromptTimeouts should have no rule")
+	}
+	if _, err := NewPromptTimeouts("", map[string]string{"Bash": "later"}); err == nil {
+		t.Error("expected error for an invalid override")
+	}
+}
+
+func TestPromptTimeouts_NoAllowForQuestionsAndPlans(t *testing.T) {
+	timeouts, err := NewPromptTimeouts("1m:allow", map[string]string{
+		"ExitPlanMode": "2m:allow",
+		"Bash":         "3m:allow",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tool, want := range map[string]TimeoutRule{
+		"AskUserQuestion": {time.Minute, RuleActionAsk},
+		"ExitPlanMode":    {2 * time.Minute, RuleActionAsk},
+		"Bash":            {3 * time.Minute, RuleActionAllow},
+		"Read":            {time.Minute, RuleActionAllow},
+	} {
+		if got, _ := timeouts.Rule(tool); got != want {
+			t.Errorf("Rule(%q) = %v, want %v", tool, got, want)
+		}
+	}
+
+	// Deny stays deny.
+	timeouts, err = NewPromptTimeouts("1m:deny", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := timeouts.Rule("AskUserQuestion"); got.Action != RuleActionDeny {
+		t.Errorf("Rule(AskUserQuestion) action = %q, want deny", got.Action)
+	}
+
+	timeouts, err = NewPromptTimeouts("20ms:allow", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := New(Config{Address: "localhost:0", Prompter: &waitingPrompter{}, Timeouts: timeouts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+	resp, err := srv.HandlePermissionRequest(context.Background(), &pb.PermissionRequest{
+		ToolName:      "ExitPlanMode",
+		ToolInputJson: `{"plan":"rm -rf /"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetHookSpecificOutput().GetPermissionDecision(); got != pb.PermissionDecision_PERMISSION_DECISION_ASK {
+		t.Errorf("ExitPlanMode decision = %v, want ASK", got)
+	}
+}
+
+// waitingPrompter blocks until ctx is done and records the deadline it saw.
+type waitingPrompter struct {
+	deadline time.Time
+	action   RuleAction
+}
+
+func (p *waitingPrompter) Prompt(ctx context.Context, _ *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	p.deadline, p.action, _ = PromptDeadline(ctx)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestServer_PromptTimeout(t *testing.T) {
+	timeouts, err := NewPromptTimeouts("", map[string]string{"Bash": "20ms:ask"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompter := &waitingPrompter{}
+	srv, err := New(Config{Address: "localhost:0", Prompter: prompter, Timeouts: timeouts, PathGuardAction: RuleActionAsk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	start := time.Now()
+	resp, err := srv.HandlePermissionRequest(context.Background(), &pb.PermissionRequest{
+		ToolName:      "Bash",
+		ToolInputJson: `{"command":"make"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetHookSpecificOutput().GetPermissionDecision(); got != pb.PermissionDecision_PERMISSION_DECISION_ASK {
+		t.Errorf("decision = %v, want ASK", got)
+	}
+	if got := resp.GetDecisionSource(); got != pb.DecisionSource_DECISION_SOURCE_TIMEOUT {
+		t.Errorf("source = %v, want TIMEOUT", got)
+	}
+	if prompter.action != RuleActionAsk || prompter.deadline.Before(start) {
+		t.Errorf("PromptDeadline() = %v, %q", prompter.deadline, prompter.action)
+	}
+
+	// A request without a rule is not decided by timeout: the caller's own
+	// deadline ends it with an error.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = srv.HandlePermissionRequest(ctx, &pb.PermissionRequest{ToolName: "WebFetch"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	if prompter.action != "" {
+		t.Errorf("action = %q, want none for the client's deadline", prompter.action)
+	}
+}
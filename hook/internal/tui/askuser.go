@@ -20,6 +20,7 @@ type askUserModel struct {
 	customMode bool
 	completed  bool
 	textInput  textinput.Model
+	countdown  countdown
 	width      int
 	height     int
 }
@@ -175,11 +176,11 @@ func (m askUserModel) advanceQuestion() (askUserModel, tea.Cmd) {
 		resp, err := server.BuildAskUserResponse(m.req, m.input, m.answers)
 		if err != nil {
 			return m, func() tea.Msg {
-				return promptCompleteMsg{err: err}
+				return promptCompleteMsg{req: m.req, err: err}
 			}
 		}
 		return m, func() tea.Msg {
-			return promptCompleteMsg{response: resp}
+			return promptCompleteMsg{req: m.req, response: resp}
 		}
 	}
 
@@ -197,6 +198,7 @@ func (m askUserModel) View() string {
 	// Header
 	b.WriteString(headerStyle.Render("AskUserQuestion"))
 	b.WriteString("\n\n")
+	b.WriteString(m.countdown.View())
 
 	// Progress
 	b.WriteString(progressStyle.Render(fmt.Sprintf("  Question %d of %d", m.currentQ+1, len(m.input.Questions))))
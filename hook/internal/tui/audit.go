@@ -3,6 +3,8 @@ package tui
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
@@ -54,10 +56,32 @@ func formatAuditEvent(event *pb.AuditEvent) string {
 		return fmt.Sprintf("[%s] audit event (no request data)", ts)
 	}
 
-	return fmt.Sprintf("[%s] %-12s tool=%-10s session=%s",
+	line := fmt.Sprintf("[%s] %-12s tool=%-10s session=%s",
 		ts,
 		req.GetHookEventName(),
 		req.GetToolName(),
 		req.GetSessionId(),
 	)
+
+	if resp := event.GetResponse(); resp != nil {
+		if d := server.DecisionString(resp.GetHookSpecificOutput().GetPermissionDecision()); d != "" {
+			line += " decision=" + d
+		}
+		if src := server.DecisionSourceString(resp.GetDecisionSource()); src != "" {
+			line += " by=" + src
+		}
+	}
+	if latency := event.GetLatency(); latency != nil {
+		line += " latency=" + latency.AsDuration().Round(time.Millisecond).String()
+	}
+	if errMsg := event.GetError(); errMsg != "" {
+		line += " error=" + strconv.Quote(errMsg)
+	}
+	if peer := event.GetPeer(); peer != nil {
+		line += fmt.Sprintf(" pid=%d", peer.GetPid())
+	}
+	if reason := event.GetResponse().GetHookSpecificOutput().GetPermissionDecisionReason(); reason != "" {
+		line += " reason=" + strconv.Quote(reason)
+	}
+	return line
 }
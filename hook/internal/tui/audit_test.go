@@ -3,8 +3,11 @@ package tui
 import (
 	"strings"
 	"testing"
+	"time"
 
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -44,3 +47,23 @@ func TestFormatAuditEvent_NilRequest(t *testing.T) {
 		t.Errorf("expected fallback text for nil request, got: %s", line)
 	}
 }
+
+func TestFormatAuditEvent_Response(t *testing.T) {
+	req := &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: "Bash", SessionId: "sess-123"}
+	resp := server.BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_DENY, "no")
+	resp.DecisionSource = pb.DecisionSource_DECISION_SOURCE_HUMAN
+	event := &pb.AuditEvent{
+		Request:   req,
+		Timestamp: timestamppb.Now(),
+		Response:  resp,
+		Latency:   durationpb.New(2 * time.Second),
+	}
+
+	line := formatAuditEvent(event)
+
+	for _, want := range []string{"decision=deny", "by=human", "latency=2s", `reason="no"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected line to contain %q, got: %s", want, line)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ngicks/crabswarm/hook/internal/server"
+)
+
+// urgentRemaining is when the countdown is highlighted.
+const urgentRemaining = 10 * time.Second
+
+// countdown shows the time left before a prompt stops being waited for.
+type countdown struct {
+	deadline time.Time
+	// action is what the server's timeout rule decides at the deadline, or
+	// empty if the deadline is the hook client's own.
+	action server.RuleAction
+	now    func() time.Time
+}
+
+func countdownFromContext(ctx context.Context) countdown {
+	deadline, action, ok := server.PromptDeadline(ctx)
+	if !ok {
+		return countdown{}
+	}
+	return countdown{deadline: deadline, action: action}
+}
+
+// View renders the countdown followed by a blank line, or nothing if there
+// is no deadline.
+func (c countdown) View() string {
+	if c.deadline.IsZero() {
+		return ""
+	}
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	left := max(c.deadline.Sub(now()), 0)
+
+	text := fmt.Sprintf("Hook client gives up in %s", formatWait(left))
+	if c.action != "" {
+		text = fmt.Sprintf("Auto-%s in %s", c.action, formatWait(left))
+	}
+	style := unselectedStyle
+	if left <= urgentRemaining {
+		style = warningStyle
+	}
+	return style.Render("  "+text) + "\n\n"
+}
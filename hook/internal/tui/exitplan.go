@@ -17,6 +17,7 @@ type exitPlanModel struct {
 	choices     []string
 	inputReason bool
 	reasonInput textinput.Model
+	countdown   countdown
 	width       int
 	height      int
 }
@@ -88,14 +89,14 @@ func (m exitPlanModel) selectChoice() (exitPlanModel, tea.Cmd) {
 	switch m.choices[m.cursor] {
 	case "Allow":
 		resp := server.BuildPermissionResponse(m.req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "")
-		return m, func() tea.Msg { return promptCompleteMsg{response: resp} }
+		return m, func() tea.Msg { return promptCompleteMsg{req: m.req, response: resp} }
 	case "Deny":
 		m.inputReason = true
 		m.reasonInput.Focus()
 		return m, textinput.Blink
 	case "Ask":
 		resp := server.BuildPermissionResponse(m.req, pb.PermissionDecision_PERMISSION_DECISION_ASK, "")
-		return m, func() tea.Msg { return promptCompleteMsg{response: resp} }
+		return m, func() tea.Msg { return promptCompleteMsg{req: m.req, response: resp} }
 	}
 	return m, nil
 }
@@ -105,7 +106,7 @@ func (m exitPlanModel) updateReasonInput(msg tea.KeyMsg) (exitPlanModel, tea.Cmd
 	case tea.KeyEnter:
 		reason := m.reasonInput.Value()
 		resp := server.BuildPermissionResponse(m.req, pb.PermissionDecision_PERMISSION_DECISION_DENY, reason)
-		return m, func() tea.Msg { return promptCompleteMsg{response: resp} }
+		return m, func() tea.Msg { return promptCompleteMsg{req: m.req, response: resp} }
 	case tea.KeyEsc:
 		m.inputReason = false
 		m.reasonInput.Blur()
@@ -124,6 +125,7 @@ func (m exitPlanModel) View() string {
 	// Header
 	b.WriteString(headerStyle.Render("Plan Approval"))
 	b.WriteString("\n\n")
+	b.WriteString(m.countdown.View())
 
 	// Tool info
 	b.WriteString(fmt.Sprintf("  Tool:    %s\n", toolNameStyle.Render(m.req.ToolName)))
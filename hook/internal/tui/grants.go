@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+)
+
+// closeGrantsMsg is emitted by grantsModel when the pane is closed.
+type closeGrantsMsg struct{}
+
+// grantsModel lists the active grants and lets the user revoke them.
+type grantsModel struct {
+	store  *server.GrantStore
+	grants []server.Grant
+	cursor int
+	err    error
+}
+
+func newGrantsModel(store *server.GrantStore) grantsModel {
+	return grantsModel{store: store, grants: store.List()}
+}
+
+func (m grantsModel) Update(msg tea.Msg) (grantsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.Type {
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown:
+		if m.cursor < len(m.grants)-1 {
+			m.cursor++
+		}
+	case tea.KeyEsc, tea.KeyCtrlG:
+		return m, func() tea.Msg { return closeGrantsMsg{} }
+	case tea.KeyDelete:
+		return m.revoke(), nil
+	case tea.KeyRunes:
+		switch keyMsg.String() {
+		case "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "j":
+			if m.cursor < len(m.grants)-1 {
+				m.cursor++
+			}
+		case "r", "x":
+			return m.revoke(), nil
+		case "q":
+			return m, func() tea.Msg { return closeGrantsMsg{} }
+		}
+	}
+	return m, nil
+}
+
+// revoke revokes the grant under the cursor and refreshes the list.
+func (m grantsModel) revoke() grantsModel {
+	if len(m.grants) == 0 {
+		return m
+	}
+	_, m.err = m.store.Revoke(m.grants[m.cursor].ID)
+	m.grants = m.store.List()
+	if m.cursor >= len(m.grants) && m.cursor > 0 {
+		m.cursor = len(m.grants) - 1
+	}
+	return m
+}
+
+func (m grantsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Grants (%d)", len(m.grants))))
+	b.WriteString("\n\n")
+
+	if len(m.grants) == 0 {
+		b.WriteString(unselectedStyle.Render("  No active grants."))
+		b.WriteString("\n")
+	}
+	for i, g := range m.grants {
+		cursor := "  "
+		line := fmt.Sprintf("[%s] %s", g.Scope, g.Describe())
+		if m.cursor == i {
+			cursor = cursorStyle.Render("> ")
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(cursor + line + "\n")
+	}
+
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render(fmt.Sprintf("  %v", m.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(statusBarStyle.Render("  r: revoke  esc: close"))
+	return b.String()
+}
@@ -3,12 +3,17 @@ package tui
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/diffview"
 	"github.com/ngicks/crabswarm/hook/internal/server"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
 )
 
 type permissionModel struct {
@@ -18,6 +23,11 @@ type permissionModel struct {
 	inputReason bool
 	reasonInput textinput.Model
 	prettyJSON  string
+	diff        string
+	diffPending bool
+	target      *pathguard.Target
+	grants      *server.GrantStore
+	countdown   countdown
 	width       int
 	height      int
 }
@@ -50,6 +60,77 @@ func newPermissionModel(req *pb.PermissionRequest, width, height int) permission
 	}
 }
 
+// diffMsg carries the rendered diff of a file edit request.
+type diffMsg struct {
+	req  *pb.PermissionRequest
+	diff string
+}
+
+// showsDiff reports whether req is a file edit shown as a diff instead of
+// the raw input.
+func showsDiff(req *pb.PermissionRequest) bool {
+	switch model.ToolName(req.GetToolName()) {
+	case model.ToolNameEdit, model.ToolNameWrite, model.ToolNameNotebookEdit:
+		return true
+	}
+	return false
+}
+
+// computeDiff reads the edited file and renders the diff of req. It runs as
+// a command since large files take a while; the result is cached on the
+// pending request.
+func computeDiff(req *pb.PermissionRequest) tea.Cmd {
+	return func() tea.Msg {
+		var diff string
+		if d, ok := diffview.ForRequest(req); ok {
+			diff = d.Render(maxDiffLines, styleDiffLine)
+		}
+		return diffMsg{req: req, diff: diff}
+	}
+}
+
+// maxDiffLines bounds the diff lines shown for a file edit.
+const maxDiffLines = 40
+
+func styleDiffLine(op diffview.Op, line string) string {
+	switch op {
+	case diffview.Insert:
+		return "  " + diffInsertStyle.Render(line)
+	case diffview.Delete:
+		return "  " + diffDeleteStyle.Render(line)
+	case diffview.Equal:
+		return "  " + diffContextStyle.Render(line)
+	default:
+		return "  " + diffHunkStyle.Render(line)
+	}
+}
+
+// Grant choices offered in addition to Allow / Deny / Ask when a GrantStore is configured.
+const (
+	choiceAllowSession = "Allow for this session"
+	choiceAllowProject = "Always allow this exact input in this project"
+	choiceAllowTool    = "Allow this tool for 30 minutes"
+)
+
+// withGrants enables the grant choices, which remember the decision in store.
+// Only the scopes that may allow the request's tool are offered.
+func (m permissionModel) withGrants(store *server.GrantStore) permissionModel {
+	m.grants = store
+	for _, c := range []struct {
+		choice string
+		scope  server.GrantScope
+	}{
+		{choiceAllowSession, server.GrantScopeSession},
+		{choiceAllowProject, server.GrantScopeProjectCommand},
+		{choiceAllowTool, server.GrantScopeTool},
+	} {
+		if c.scope.AllowsTool(m.req.GetToolName()) {
+			m.choices = append(m.choices, c.choice)
+		}
+	}
+	return m
+}
+
 func (m permissionModel) Update(msg tea.Msg) (permissionModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -89,6 +170,12 @@ func (m permissionModel) updateChoiceSelection(msg tea.KeyMsg) (permissionModel,
 		case "d":
 			m.cursor = 1
 			return m.selectChoice()
+		case "s":
+			return m.selectNamed(choiceAllowSession)
+		case "p":
+			return m.selectNamed(choiceAllowProject)
+		case "t":
+			return m.selectNamed(choiceAllowTool)
 		case " ":
 			return m.selectChoice()
 		}
@@ -96,28 +183,58 @@ func (m permissionModel) updateChoiceSelection(msg tea.KeyMsg) (permissionModel,
 	return m, nil
 }
 
+// selectNamed selects choice if it is offered.
+func (m permissionModel) selectNamed(choice string) (permissionModel, tea.Cmd) {
+	i := slices.Index(m.choices, choice)
+	if i < 0 {
+		return m, nil
+	}
+	m.cursor = i
+	return m.selectChoice()
+}
+
 func (m permissionModel) selectChoice() (permissionModel, tea.Cmd) {
 	switch m.choices[m.cursor] {
 	case "Allow":
 		resp := server.BuildPermissionResponse(m.req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "")
-		return m, func() tea.Msg { return promptCompleteMsg{response: resp} }
+		return m, func() tea.Msg { return promptCompleteMsg{req: m.req, response: resp} }
 	case "Deny":
 		m.inputReason = true
 		m.reasonInput.Focus()
 		return m, textinput.Blink
 	case "Ask":
 		resp := server.BuildPermissionResponse(m.req, pb.PermissionDecision_PERMISSION_DECISION_ASK, "")
-		return m, func() tea.Msg { return promptCompleteMsg{response: resp} }
+		return m, func() tea.Msg { return promptCompleteMsg{req: m.req, response: resp} }
+	case choiceAllowSession:
+		return m, m.grant(server.GrantScopeSession, 0)
+	case choiceAllowProject:
+		return m, m.grant(server.GrantScopeProjectCommand, 0)
+	case choiceAllowTool:
+		return m, m.grant(server.GrantScopeTool, server.DefaultToolGrantTTL)
 	}
 	return m, nil
 }
 
+// grant stores a grant for the request and allows it.
+func (m permissionModel) grant(scope server.GrantScope, ttl time.Duration) tea.Cmd {
+	req, store := m.req, m.grants
+	return func() tea.Msg {
+		g, err := store.Add(server.NewGrant(scope, req, ttl))
+		reason := g.Reason()
+		if err != nil {
+			reason += fmt.Sprintf(" (not persisted: %v)", err)
+		}
+		resp := server.BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, reason)
+		return promptCompleteMsg{req: req, response: resp}
+	}
+}
+
 func (m permissionModel) updateReasonInput(msg tea.KeyMsg) (permissionModel, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyEnter:
 		reason := m.reasonInput.Value()
 		resp := server.BuildPermissionResponse(m.req, pb.PermissionDecision_PERMISSION_DECISION_DENY, reason)
-		return m, func() tea.Msg { return promptCompleteMsg{response: resp} }
+		return m, func() tea.Msg { return promptCompleteMsg{req: m.req, response: resp} }
 	case tea.KeyEsc:
 		m.inputReason = false
 		m.reasonInput.Blur()
@@ -130,12 +247,21 @@ func (m permissionModel) updateReasonInput(msg tea.KeyMsg) (permissionModel, tea
 	return m, cmd
 }
 
+// describePane formats the tmux pane of a request, e.g. "fix-bug (%3)".
+func describePane(pane *pb.TmuxPane) string {
+	if pane.GetMember() != "" {
+		return fmt.Sprintf("%s (%s)", pane.GetMember(), pane.GetPaneId())
+	}
+	return pane.GetPaneId()
+}
+
 func (m permissionModel) View() string {
 	var b strings.Builder
 
 	// Header
 	b.WriteString(headerStyle.Render(fmt.Sprintf("Permission Request: %s", m.req.HookEventName)))
 	b.WriteString("\n\n")
+	b.WriteString(m.countdown.View())
 
 	// Tool info
 	b.WriteString(fmt.Sprintf("  Tool:    %s\n", toolNameStyle.Render(m.req.ToolName)))
@@ -143,9 +269,24 @@ func (m permissionModel) View() string {
 	if m.req.Cwd != "" {
 		b.WriteString(fmt.Sprintf("  Cwd:     %s\n", m.req.Cwd))
 	}
+	if pane := m.req.GetTmux(); pane != nil {
+		b.WriteString(fmt.Sprintf("  Pane:    %s\n", describePane(pane)))
+	}
+	if m.target != nil {
+		path := diffview.Visible(m.target.Describe())
+		if m.target.Escalate() {
+			path = warningStyle.Render(path)
+		}
+		b.WriteString(fmt.Sprintf("  Path:    %s\n", path))
+	}
 
-	// JSON input
-	if m.prettyJSON != "" {
+	// Diff of a file edit, or the JSON input
+	if m.diffPending {
+		b.WriteString("\n  Computing diff...\n")
+	} else if m.diff != "" {
+		b.WriteString("\n")
+		b.WriteString(m.diff)
+	} else if m.prettyJSON != "" {
 		b.WriteString("\n")
 		b.WriteString(jsonStyle.Render(m.prettyJSON))
 		b.WriteString("\n")
@@ -172,6 +313,12 @@ func (m permissionModel) View() string {
 				shortcut = "(d)"
 			case "Ask":
 				shortcut = "(k)"
+			case choiceAllowSession:
+				shortcut = "(s)"
+			case choiceAllowProject:
+				shortcut = "(p)"
+			case choiceAllowTool:
+				shortcut = "(t)"
 			}
 
 			if m.cursor == i {
@@ -0,0 +1,265 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+	"github.com/ngicks/crabswarm/hook/model"
+	"github.com/ngicks/crabswarm/internal/tmux"
+)
+
+// replyTimeout bounds delivering a reply with tmux.
+const replyTimeout = 5 * time.Second
+
+// idleSessionMsg reports a Claude session waiting for user input.
+type idleSessionMsg struct {
+	session idleSession
+	pane    *pb.TmuxPane
+}
+
+// idleSession is a Claude session waiting for a follow-up prompt.
+type idleSession struct {
+	sessionID string
+	cwd       string
+	// reason is the notification message or "finished".
+	reason string
+	since  time.Time
+}
+
+// replySentMsg reports the outcome of delivering a reply.
+type replySentMsg struct {
+	sessionID string
+	text      string
+	err       error
+}
+
+// closeReplyMsg is emitted by replyModel when the pane is closed.
+type closeReplyMsg struct{}
+
+// sendFunc types text into pane.
+type sendFunc func(ctx context.Context, pane *pb.TmuxPane, text string) error
+
+// tmuxSender returns a sendFunc delivering text with tmux send-keys on the
+// swarm's tmux server. The pane comes from a hook request, so it is only
+// typed into if it is a swarm member's pane on that server.
+func tmuxSender(client *tmux.Client) sendFunc {
+	return func(ctx context.Context, pane *pb.TmuxPane, text string) error {
+		socketPath, err := client.ServerSocketPath(ctx)
+		if err != nil {
+			return err
+		}
+		panes, err := client.ListPanes(ctx, tmux.MemberOption)
+		if err != nil {
+			return err
+		}
+		if err := checkPane(pane, socketPath, panes); err != nil {
+			return err
+		}
+		return client.SendText(ctx, pane.GetPaneId(), text)
+	}
+}
+
+// checkPane reports an error unless pane is a live swarm member's pane of
+// the tmux server listening on socketPath, which has panes.
+func checkPane(pane *pb.TmuxPane, socketPath string, panes []tmux.Pane) error {
+	if filepath.Clean(pane.GetSocketPath()) != filepath.Clean(socketPath) {
+		return fmt.Errorf("pane %s is not on the swarm's tmux server", pane.GetPaneId())
+	}
+	for _, p := range panes {
+		if p.ID != pane.GetPaneId() {
+			continue
+		}
+		member := p.Options[tmux.MemberOption]
+		switch {
+		case member == "":
+			return fmt.Errorf("pane %s is not a swarm member", p.ID)
+		case pane.GetMember() != "" && pane.GetMember() != member:
+			return fmt.Errorf("pane %s belongs to member %q, not %q", p.ID, member, pane.GetMember())
+		case p.Dead:
+			return fmt.Errorf("pane %s is dead", p.ID)
+		}
+		return nil
+	}
+	return fmt.Errorf("pane %s not found", pane.GetPaneId())
+}
+
+// replyModel lets the user pick an idle session and type a prompt for it.
+type replyModel struct {
+	sessions []idleSession
+	panes    map[string]*pb.TmuxPane
+	send     sendFunc
+	cursor   int
+	input    textinput.Model
+	sending  bool
+	err      error
+}
+
+func newReplyModel(sessions []idleSession, panes map[string]*pb.TmuxPane, send sendFunc) replyModel {
+	ti := textinput.New()
+	ti.Placeholder = "Follow-up prompt"
+	ti.CharLimit = 4096
+	ti.Width = 60
+	ti.Focus()
+	return replyModel{sessions: sessions, panes: panes, send: send, input: ti}
+}
+
+func (m replyModel) Update(msg tea.Msg) (replyModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.sending {
+		return m, nil
+	}
+	switch keyMsg.Type {
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case tea.KeyDown, tea.KeyTab:
+		if m.cursor < len(m.sessions)-1 {
+			m.cursor++
+		}
+		return m, nil
+	case tea.KeyEsc:
+		return m, func() tea.Msg { return closeReplyMsg{} }
+	case tea.KeyEnter:
+		return m.submit()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submit sends the typed prompt to the selected session's pane.
+func (m replyModel) submit() (replyModel, tea.Cmd) {
+	text := strings.TrimSpace(m.input.Value())
+	if text == "" || len(m.sessions) == 0 {
+		return m, nil
+	}
+	s := m.sessions[m.cursor]
+	pane := m.panes[s.sessionID]
+	if pane == nil {
+		m.err = fmt.Errorf("no tmux pane known for session %s", s.sessionID)
+		return m, nil
+	}
+
+	m.sending = true
+	m.err = nil
+	send := m.send
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), replyTimeout)
+		defer cancel()
+		return replySentMsg{sessionID: s.sessionID, text: text, err: send(ctx, pane, text)}
+	}
+}
+
+func (m replyModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Reply to idle session (%d)", len(m.sessions))))
+	b.WriteString("\n\n")
+
+	if len(m.sessions) == 0 {
+		b.WriteString(unselectedStyle.Render("  No idle sessions."))
+		b.WriteString("\n")
+	}
+	for i, s := range m.sessions {
+		cursor := "  "
+		line := fmt.Sprintf("%s  %s  %s (%s)", s.sessionID, s.cwd, s.reason, formatWait(time.Since(s.since)))
+		if pane := m.panes[s.sessionID]; pane != nil {
+			line += "  [" + describePane(pane) + "]"
+		} else {
+			line += "  [no tmux pane]"
+		}
+		if m.cursor == i {
+			cursor = cursorStyle.Render("> ")
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(cursor + line + "\n")
+	}
+
+	b.WriteString("\n  " + m.input.View() + "\n")
+
+	if m.sending {
+		b.WriteString(progressStyle.Render("  Sending..."))
+		b.WriteString("\n")
+	}
+	if m.err != nil {
+		b.WriteString(warningStyle.Render(fmt.Sprintf("  %v", m.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(statusBarStyle.Render("  up/down: select session  enter: send  esc: close"))
+	return b.String()
+}
+
+// markIdle records s as waiting for input, replacing an earlier entry for
+// the same session.
+func markIdle(idle []idleSession, s idleSession) []idleSession {
+	idle = markActive(idle, s.sessionID)
+	return append(idle, s)
+}
+
+// markActive removes sessionID from the idle sessions.
+func markActive(idle []idleSession, sessionID string) []idleSession {
+	return slices.DeleteFunc(idle, func(s idleSession) bool { return s.sessionID == sessionID })
+}
+
+// TUIHookHandler reports idle Claude sessions (an idle_prompt Notification
+// or a Stop event) to the TUI, where the user can reply to them, and
+// delegates the hook response to another HookHandler.
+type TUIHookHandler struct {
+	program  *tea.Program
+	delegate server.HookHandler
+}
+
+// NewTUIHookHandler creates a TUIHookHandler. If delegate is nil, events are
+// answered with an empty output.
+func NewTUIHookHandler(program *tea.Program, delegate server.HookHandler) *TUIHookHandler {
+	return &TUIHookHandler{program: program, delegate: delegate}
+}
+
+func (h *TUIHookHandler) HandleHook(ctx context.Context, req *pb.HookRequest) (*pb.HookResponse, error) {
+	if msg, ok := idleSessionFromHook(req, time.Now()); ok {
+		h.program.Send(msg)
+	}
+	if h.delegate != nil {
+		return h.delegate.HandleHook(ctx, req)
+	}
+	return server.BuildHookResponse(req, ""), nil
+}
+
+// idleSessionFromHook reports whether req means its session is waiting for
+// user input.
+func idleSessionFromHook(req *pb.HookRequest, now time.Time) (idleSessionMsg, bool) {
+	input, err := server.ParseHookInput(req)
+	if err != nil {
+		return idleSessionMsg{}, false
+	}
+
+	var reason string
+	switch {
+	case input.HookEventName == model.HookEventStop && !input.StopHookActive:
+		reason = "finished"
+	case input.HookEventName == model.HookEventNotification && input.NotificationType == model.NotificationTypeIdlePrompt:
+		reason = input.Message
+		if reason == "" {
+			reason = "waiting for input"
+		}
+	default:
+		return idleSessionMsg{}, false
+	}
+
+	return idleSessionMsg{
+		session: idleSession{sessionID: req.GetSessionId(), cwd: req.GetCwd(), reason: reason, since: now},
+		pane:    req.GetTmux(),
+	}, true
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+// sessionsPaneWidth is the width of the session list left of the prompt.
+const sessionsPaneWidth = 30
+
+// pendingRequest is a request waiting for an answer.
+type pendingRequest struct {
+	msg     permissionRequestMsg
+	arrived time.Time
+	// diff is the rendered diff of a file edit, computed once by
+	// computeDiff. diffPending is set until it arrives.
+	diff        string
+	diffPending bool
+}
+
+// sessionGroup is the pending requests of one Claude session.
+type sessionGroup struct {
+	key      string
+	requests []pendingRequest
+}
+
+// oldest returns the arrival time of the longest waiting request.
+func (g sessionGroup) oldest() time.Time {
+	return g.requests[0].arrived
+}
+
+// sessionKey identifies the session of req: its session ID, or its working
+// directory if the ID is missing.
+func sessionKey(req *pb.PermissionRequest) string {
+	switch {
+	case req.GetSessionId() != "":
+		return req.GetSessionId()
+	case req.GetCwd() != "":
+		return req.GetCwd()
+	default:
+		return "(unknown session)"
+	}
+}
+
+// groupSessions groups pending (in arrival order) by session. The session
+// blocked longest comes first.
+func groupSessions(pending []pendingRequest) []sessionGroup {
+	var groups []sessionGroup
+	for _, p := range pending {
+		key := sessionKey(p.msg.req)
+		i := slices.IndexFunc(groups, func(g sessionGroup) bool { return g.key == key })
+		if i < 0 {
+			groups = append(groups, sessionGroup{key: key})
+			i = len(groups) - 1
+		}
+		groups[i].requests = append(groups[i].requests, p)
+	}
+	// Groups are created in order of their oldest request, so they are
+	// already sorted by how long they have been blocked.
+	return groups
+}
+
+// dashboardOrder returns the pending requests in the order they are listed
+// in the session pane.
+func dashboardOrder(pending []pendingRequest) []*pb.PermissionRequest {
+	var out []*pb.PermissionRequest
+	for _, g := range groupSessions(pending) {
+		for _, p := range g.requests {
+			out = append(out, p.msg.req)
+		}
+	}
+	return out
+}
+
+// tickMsg refreshes the waiting times shown in the session pane.
+type tickMsg struct{}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// formatWait formats a waiting duration compactly, e.g. "42s" or "3m05s".
+func formatWait(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// truncate shortens s to at most n runes, keeping its end, which is the
+// distinctive part of session IDs and paths.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return "…" + string(r[len(r)-n+1:])
+}
+
+// sessionsView renders the session list with the pending requests of each
+// session. active is the request shown in the prompt panel.
+func sessionsView(pending []pendingRequest, active *pb.PermissionRequest, now time.Time) string {
+	var b strings.Builder
+
+	groups := groupSessions(pending)
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Sessions (%d)", len(groups))))
+	b.WriteString("\n\n")
+
+	for _, g := range groups {
+		header := fmt.Sprintf("%s %s", truncate(g.key, sessionsPaneWidth-10), formatWait(now.Sub(g.oldest())))
+		b.WriteString(" " + toolNameStyle.Render(header) + "\n")
+		for _, p := range g.requests {
+			cursor := "   "
+			line := fmt.Sprintf("%s %s", truncate(p.msg.req.GetToolName(), sessionsPaneWidth-12), formatWait(now.Sub(p.arrived)))
+			if p.msg.req == active {
+				cursor = cursorStyle.Render("  >")
+				line = selectedStyle.Render(line)
+			} else {
+				line = unselectedStyle.Render(line)
+			}
+			b.WriteString(cursor + " " + line + "\n")
+		}
+	}
+
+	return b.String()
+}
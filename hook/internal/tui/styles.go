@@ -13,10 +13,26 @@ var (
 			Bold(true).
 			Foreground(lipgloss.Color("#FF6F61"))
 
+	warningStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FFD700"))
+
 	jsonStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#A8E6CF")).
 			Padding(0, 2)
 
+	diffInsertStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#00FF00"))
+
+	diffDeleteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF6F61"))
+
+	diffHunkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#5FD7FF"))
+
+	diffContextStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#888888"))
+
 	selectedStyle = lipgloss.NewStyle().
 			Bold(true).
 			Foreground(lipgloss.Color("#7D56F4"))
@@ -31,6 +47,11 @@ var (
 			Foreground(lipgloss.Color("#888888")).
 			Padding(1, 0)
 
+	noticeStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FFD700")).
+			Padding(1, 0)
+
 	questionHeaderStyle = lipgloss.NewStyle().
 				Bold(true).
 				Foreground(lipgloss.Color("#FFD700")).
@@ -2,13 +2,20 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
 	"github.com/ngicks/crabswarm/hook/internal/server"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
+	"github.com/ngicks/crabswarm/internal/tmux"
 )
 
 // State represents the current view state.
@@ -26,8 +33,9 @@ const promptAreaHeight = 16
 
 // permissionRequestMsg is sent from gRPC goroutines into bubbletea via Program.Send().
 type permissionRequestMsg struct {
-	req     *pb.PermissionRequest
-	replyCh chan<- permissionResult
+	req       *pb.PermissionRequest
+	replyCh   chan<- permissionResult
+	countdown countdown
 }
 
 // permissionResult carries the response back to the gRPC handler.
@@ -37,25 +45,66 @@ type permissionResult struct {
 }
 
 // promptCompleteMsg is emitted by sub-models when the user completes a prompt.
+// req identifies the answered request; if nil, the active request is meant.
 type promptCompleteMsg struct {
+	req      *pb.PermissionRequest
 	response *pb.PermissionResponse
 	err      error
 }
 
+// requestCancelledMsg is sent by TUIPrompter when the caller stops waiting
+// for req, e.g. because the hook client went away or another front-end
+// answered it first.
+type requestCancelledMsg struct {
+	req *pb.PermissionRequest
+	err error
+}
+
 // rootModel is the top-level bubbletea model.
 type rootModel struct {
-	state      state
-	permModel  permissionModel
-	askModel   askUserModel
-	exitModel  exitPlanModel
-	replyCh    chan<- permissionResult
-	queuedReqs []permissionRequestMsg
-	width      int
-	height     int
+	state     state
+	permModel permissionModel
+	askModel  askUserModel
+	exitModel exitPlanModel
+	width     int
+	height    int
+
+	// pending holds every unanswered request in arrival order; the one
+	// shown in the prompt panel is pending[active].
+	pending []pendingRequest
+	active  int
+	now     func() time.Time
 
 	viewport viewport.Model
 	logLines []string
 	vpReady  bool
+
+	pathGuard *pathguard.Guard
+
+	grants     *server.GrantStore
+	grantsPane grantsModel
+	showGrants bool
+
+	// idle holds the sessions waiting for a follow-up prompt, and panes the
+	// last known tmux pane of each session.
+	idle      []idleSession
+	panes     map[string]*pb.TmuxPane
+	send      sendFunc
+	tmux      *tmux.Client
+	replyPane replyModel
+	showReply bool
+
+	// notice tells why the prompt changed under the user (e.g. the active
+	// request was withdrawn). It is cleared by the next key press.
+	notice string
+}
+
+// tmuxClient returns the client of the swarm's tmux server.
+func (m rootModel) tmuxClient() *tmux.Client {
+	if m.tmux == nil {
+		return &tmux.Client{}
+	}
+	return m.tmux
 }
 
 func (m rootModel) Init() tea.Cmd {
@@ -71,6 +120,7 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport = viewport.New(msg.Width, m.viewportHeight())
 			m.viewport.MouseWheelEnabled = true
 			m.syncViewportContent()
+			m.viewport.GotoBottom()
 			m.vpReady = true
 		} else {
 			m.viewport.Width = msg.Width
@@ -79,28 +129,19 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case auditEventMsg:
-		m.logLines = append(m.logLines, msg.line)
-		atBottom := m.viewport.AtBottom()
-		m.syncViewportContent()
-		if atBottom {
-			m.viewport.GotoBottom()
-		}
-		return m, nil
+		return m.appendLog(msg.line), nil
 
 	case tea.KeyMsg:
 		// Global quit on ctrl+c
 		if msg.Type == tea.KeyCtrlC {
 			// Drain any pending requests with an error
-			if m.replyCh != nil {
-				m.replyCh <- permissionResult{err: fmt.Errorf("TUI terminated")}
-				m.replyCh = nil
-			}
-			for _, qr := range m.queuedReqs {
-				qr.replyCh <- permissionResult{err: fmt.Errorf("TUI terminated")}
+			for _, p := range m.pending {
+				p.msg.replyCh <- permissionResult{err: fmt.Errorf("TUI terminated")}
 			}
-			m.queuedReqs = nil
+			m.pending = nil
 			return m, tea.Quit
 		}
+		m.notice = ""
 
 		// Route PgUp/PgDn to viewport
 		if msg.Type == tea.KeyPgUp || msg.Type == tea.KeyPgDown {
@@ -109,6 +150,52 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		// ctrl+r opens the reply pane, ctrl+g the grants pane, over the
+		// active prompt
+		if m.showReply {
+			var cmd tea.Cmd
+			m.replyPane, cmd = m.replyPane.Update(msg)
+			return m, cmd
+		}
+		if msg.Type == tea.KeyCtrlR && len(m.idle) > 0 && !m.showGrants {
+			m.showReply = true
+			send := m.send
+			if send == nil {
+				send = tmuxSender(m.tmuxClient())
+			}
+			m.replyPane = newReplyModel(slices.Clone(m.idle), m.panes, send)
+			if m.vpReady {
+				m.viewport.Height = m.viewportHeight()
+			}
+			return m, textinput.Blink
+		}
+		if m.showGrants {
+			var cmd tea.Cmd
+			m.grantsPane, cmd = m.grantsPane.Update(msg)
+			return m, cmd
+		}
+		if msg.Type == tea.KeyCtrlG && m.grants != nil {
+			m.showGrants = true
+			m.grantsPane = newGrantsModel(m.grants)
+			if m.vpReady {
+				m.viewport.Height = m.viewportHeight()
+			}
+			return m, nil
+		}
+
+		// tab/shift+tab move between pending requests in dashboard order;
+		// ctrl+o jumps to the one waiting longest.
+		if len(m.pending) > 1 {
+			switch msg.Type {
+			case tea.KeyTab:
+				return m.cycle(1), nil
+			case tea.KeyShiftTab:
+				return m.cycle(-1), nil
+			case tea.KeyCtrlO:
+				return m.activate(0), nil
+			}
+		}
+
 		// Delegate to active sub-model
 		switch m.state {
 		case statePermission:
@@ -125,46 +212,212 @@ func (m rootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+	case idleSessionMsg:
+		m = m.observePane(msg.session.sessionID, msg.pane)
+		m.idle = markIdle(m.idle, msg.session)
+		return m, nil
+
+	case replySentMsg:
+		m.replyPane.sending = false
+		if msg.err != nil {
+			m.replyPane.err = fmt.Errorf("failed to send reply: %w", msg.err)
+			return m, nil
+		}
+		m.idle = markActive(m.idle, msg.sessionID)
+		m = m.appendLog(fmt.Sprintf("[%s] reply sent to session %s: %s", m.clock().Format("15:04:05"), msg.sessionID, msg.text))
+		return m.closeReply(), nil
+
+	case closeReplyMsg:
+		return m.closeReply(), nil
+
+	case closeGrantsMsg:
+		m.showGrants = false
+		if m.vpReady {
+			m.viewport.Height = m.viewportHeight()
+		}
+		return m, nil
+
 	case permissionRequestMsg:
+		// A session asking for permission is busy again.
+		m = m.observePane(msg.req.GetSessionId(), msg.req.GetTmux())
+		m.idle = markActive(m.idle, msg.req.GetSessionId())
+		p := pendingRequest{msg: msg, arrived: m.clock()}
+		var diff tea.Cmd
+		if showsDiff(msg.req) {
+			p.diffPending = true
+			diff = computeDiff(msg.req)
+		}
+		m.pending = append(m.pending, p)
 		if m.state == stateIdle {
-			return m.activateRequest(msg), nil
+			return m.activate(len(m.pending) - 1), tea.Batch(tick(), diff)
+		}
+		return m, diff
+
+	case diffMsg:
+		i := m.indexOf(msg.req)
+		if i < 0 {
+			// Answered before the diff was ready
+			return m, nil
+		}
+		m.pending[i].diff, m.pending[i].diffPending = msg.diff, false
+		if m.state == statePermission && i == m.active {
+			m.permModel.diff, m.permModel.diffPending = msg.diff, false
 		}
-		// Queue the request
-		m.queuedReqs = append(m.queuedReqs, msg)
 		return m, nil
 
-	case promptCompleteMsg:
-		// Send result back to gRPC handler
-		if m.replyCh != nil {
-			m.replyCh <- permissionResult{response: msg.response, err: msg.err}
-			m.replyCh = nil
+	case tickMsg:
+		// Keep the waiting times fresh while requests are pending.
+		if len(m.pending) > 0 {
+			return m, tick()
 		}
+		return m, nil
 
-		// Dequeue next request
-		if len(m.queuedReqs) > 0 {
-			next := m.queuedReqs[0]
-			m.queuedReqs = m.queuedReqs[1:]
-			return m.activateRequest(next), nil
+	case promptCompleteMsg:
+		i := m.active
+		if msg.req != nil {
+			i = m.indexOf(msg.req)
+		}
+		if m.state == stateIdle || i < 0 {
+			// Already answered
+			return m, nil
 		}
 
-		m.state = stateIdle
-		if m.vpReady {
-			m.viewport.Height = m.viewportHeight()
+		// Send result back to gRPC handler
+		m.pending[i].msg.replyCh <- permissionResult{response: msg.response, err: msg.err}
+		return m.remove(i), nil
+
+	case requestCancelledMsg:
+		// Nobody waits for the request anymore; drop it without replying.
+		i := m.indexOf(msg.req)
+		if i < 0 {
+			return m, nil
 		}
-		return m, nil
+		withdrawn := fmt.Sprintf("%s request withdrawn: %s", msg.req.GetToolName(), cancelReason(msg.err))
+		m = m.appendLog(fmt.Sprintf("[%s] %s  session=%s", m.clock().Format("15:04:05"), withdrawn, msg.req.GetSessionId()))
+		if m.state != stateIdle && i == m.active {
+			m.notice = withdrawn
+		}
+		return m.remove(i), nil
 	}
 
 	return m, nil
 }
 
-func (m rootModel) activateRequest(msg permissionRequestMsg) rootModel {
-	m.replyCh = msg.replyCh
+// cancelReason describes why a request was withdrawn.
+func cancelReason(err error) string {
+	switch {
+	case errors.Is(err, server.ErrAnsweredElsewhere):
+		return "answered in another front-end"
+	case errors.Is(err, server.ErrPromptTimeout):
+		return "decided by timeout"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "the hook client timed out"
+	default:
+		return "the hook client stopped waiting"
+	}
+}
+
+// remove drops pending[i]. If it was the active request, the prompt moves on
+// to the same session's next request, otherwise to the one waiting longest.
+func (m rootModel) remove(i int) rootModel {
+	removed := m.pending[i]
+	m.pending = slices.Delete(m.pending, i, i+1)
+
+	if m.state == stateIdle || i != m.active {
+		if i < m.active {
+			m.active--
+		}
+		return m
+	}
+
+	if len(m.pending) > 0 {
+		next := slices.IndexFunc(m.pending, func(p pendingRequest) bool {
+			return sessionKey(p.msg.req) == sessionKey(removed.msg.req)
+		})
+		return m.activate(max(next, 0))
+	}
+
+	m.state = stateIdle
+	if m.vpReady {
+		m.viewport.Height = m.viewportHeight()
+	}
+	return m
+}
+
+// appendLog adds line to the log panel, following it if scrolled to the bottom.
+func (m rootModel) appendLog(line string) rootModel {
+	m.logLines = append(m.logLines, line)
+	atBottom := m.viewport.AtBottom()
+	m.syncViewportContent()
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+	return m
+}
+
+// observePane remembers pane as the tmux pane of sessionID.
+func (m rootModel) observePane(sessionID string, pane *pb.TmuxPane) rootModel {
+	if sessionID == "" || pane == nil {
+		return m
+	}
+	if m.panes == nil {
+		m.panes = make(map[string]*pb.TmuxPane)
+	}
+	m.panes[sessionID] = pane
+	return m
+}
+
+func (m rootModel) closeReply() rootModel {
+	m.showReply = false
+	if m.vpReady {
+		m.viewport.Height = m.viewportHeight()
+	}
+	return m
+}
+
+func (m rootModel) clock() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+	return time.Now()
+}
+
+// indexOf returns the index of req in m.pending, or -1.
+func (m rootModel) indexOf(req *pb.PermissionRequest) int {
+	return slices.IndexFunc(m.pending, func(p pendingRequest) bool { return p.msg.req == req })
+}
+
+// activeRequest returns the request shown in the prompt panel, or nil.
+func (m rootModel) activeRequest() *pb.PermissionRequest {
+	if m.state == stateIdle || m.active >= len(m.pending) {
+		return nil
+	}
+	return m.pending[m.active].msg.req
+}
+
+// cycle activates the request delta positions away from the active one in
+// dashboard order.
+func (m rootModel) cycle(delta int) rootModel {
+	order := dashboardOrder(m.pending)
+	pos := slices.Index(order, m.activeRequest())
+	next := order[((pos+delta)%len(order)+len(order))%len(order)]
+	return m.activate(m.indexOf(next))
+}
+
+// activate shows pending[i] in the prompt panel. Partial input on the
+// previously active prompt is discarded.
+func (m rootModel) activate(i int) rootModel {
+	m.active = i
+	msg := m.pending[i].msg
+	timer := msg.countdown
+	timer.now = m.now
 
 	if msg.req.ToolName == "AskUserQuestion" && msg.req.ToolInputJson != "" {
-		input, err := server.ParseAskUserInput(msg.req.ToolInputJson)
+		input, err := server.AskUserInputFromRequest(msg.req)
 		if err == nil && len(input.Questions) > 0 {
 			m.state = stateAskUser
 			m.askModel = newAskUserModel(msg.req, input, m.width, m.height)
+			m.askModel.countdown = timer
 			if m.vpReady {
 				m.viewport.Height = m.viewportHeight()
 			}
@@ -173,10 +426,11 @@ func (m rootModel) activateRequest(msg permissionRequestMsg) rootModel {
 	}
 
 	if msg.req.ToolName == "ExitPlanMode" && msg.req.ToolInputJson != "" {
-		input, err := server.ParseExitPlanModeInput(msg.req.ToolInputJson)
+		input, err := server.ExitPlanModeInputFromRequest(msg.req)
 		if err == nil {
 			m.state = stateExitPlan
 			m.exitModel = newExitPlanModel(msg.req, input, m.width, m.height)
+			m.exitModel.countdown = timer
 			if m.vpReady {
 				m.viewport.Height = m.viewportHeight()
 			}
@@ -186,6 +440,14 @@ func (m rootModel) activateRequest(msg permissionRequestMsg) rootModel {
 
 	m.state = statePermission
 	m.permModel = newPermissionModel(msg.req, m.width, m.height)
+	m.permModel.diff, m.permModel.diffPending = m.pending[i].diff, m.pending[i].diffPending
+	m.permModel.countdown = timer
+	if target, ok := server.ClassifyPath(m.pathGuard, msg.req); ok {
+		m.permModel.target = &target
+	}
+	if m.grants != nil {
+		m.permModel = m.permModel.withGrants(m.grants)
+	}
 	if m.vpReady {
 		m.viewport.Height = m.viewportHeight()
 	}
@@ -193,7 +455,7 @@ func (m rootModel) activateRequest(msg permissionRequestMsg) rootModel {
 }
 
 func (m rootModel) viewportHeight() int {
-	if m.state == stateIdle {
+	if m.state == stateIdle && !m.showGrants && !m.showReply {
 		// Full screen minus header line and status line
 		h := m.height - 2
 		if h < 1 {
@@ -231,26 +493,49 @@ func (m rootModel) View() string {
 	b.WriteString(logSeparatorStyle.Render(sep))
 	b.WriteString("\n")
 
-	// Bottom panel: active prompt or idle message
-	switch m.state {
-	case statePermission:
-		b.WriteString(m.permModel.View())
-	case stateAskUser:
-		b.WriteString(m.askModel.View())
-	case stateExitPlan:
-		b.WriteString(m.exitModel.View())
+	// Bottom panel: grants pane, active prompt or idle message
+	switch {
+	case m.showReply:
+		b.WriteString(m.replyPane.View())
+	case m.showGrants:
+		b.WriteString(m.grantsPane.View())
+	case m.state == statePermission:
+		b.WriteString(m.promptView(m.permModel.View()))
+	case m.state == stateAskUser:
+		b.WriteString(m.promptView(m.askModel.View()))
+	case m.state == stateExitPlan:
+		b.WriteString(m.promptView(m.exitModel.View()))
 	default:
 		b.WriteString(statusBarStyle.Render("  Waiting for permission requests..."))
 	}
 
+	if m.notice != "" {
+		b.WriteString(noticeStyle.Render("  " + m.notice))
+	}
+
+	// Idle sessions
+	if len(m.idle) > 0 && !m.showReply {
+		b.WriteString(statusBarStyle.Render(fmt.Sprintf("  %d idle session(s)  ctrl+r: reply", len(m.idle))))
+	}
+
 	// Queue status
-	if len(m.queuedReqs) > 0 {
-		b.WriteString(statusBarStyle.Render(fmt.Sprintf("  %d request(s) queued", len(m.queuedReqs))))
+	if len(m.pending) > 1 {
+		sessions := len(groupSessions(m.pending))
+		b.WriteString(statusBarStyle.Render(fmt.Sprintf("  %d requests pending in %d session(s)  tab/shift+tab: switch  ctrl+o: oldest", len(m.pending), sessions)))
 	}
 
 	return b.String()
 }
 
+// promptView renders the active prompt, with the session list on its left.
+func (m rootModel) promptView(prompt string) string {
+	sessions := sessionsView(m.pending, m.activeRequest(), m.clock())
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(sessionsPaneWidth).Render(sessions),
+		prompt,
+	)
+}
+
 // TUIPrompter implements the Prompter interface using a bubbletea TUI.
 type TUIPrompter struct {
 	program *tea.Program
@@ -261,21 +546,76 @@ func (t *TUIPrompter) Prompt(ctx context.Context, req *pb.PermissionRequest) (*p
 	replyCh := make(chan permissionResult, 1)
 
 	t.program.Send(permissionRequestMsg{
-		req:     req,
-		replyCh: replyCh,
+		req:       req,
+		replyCh:   replyCh,
+		countdown: countdownFromContext(ctx),
 	})
 
 	select {
 	case <-ctx.Done():
+		t.program.Send(requestCancelledMsg{req: req, err: context.Cause(ctx)})
 		return nil, ctx.Err()
 	case result := <-replyCh:
+		if result.response != nil {
+			result.response.DecisionSource = pb.DecisionSource_DECISION_SOURCE_TUI
+		}
 		return result.response, result.err
 	}
 }
 
+// Option configures the TUI created by New.
+type Option func(*rootModel)
+
+// WithPathGuard shows the resolved path and its classification for file
+// tool requests.
+func WithPathGuard(g *pathguard.Guard) Option {
+	return func(m *rootModel) {
+		m.pathGuard = g
+	}
+}
+
+// WithGrants offers "remember this decision" choices stored in store and
+// enables the grants pane (ctrl+g).
+func WithGrants(store *server.GrantStore) Option {
+	return func(m *rootModel) {
+		m.grants = store
+	}
+}
+
+// WithTmux sets the tmux server of the swarm. Replies (ctrl+r) are only
+// typed into member panes of this server. Defaults to the default tmux
+// server.
+func WithTmux(client *tmux.Client) Option {
+	return func(m *rootModel) {
+		m.tmux = client
+	}
+}
+
+// historyPreload is the number of recent history events shown in the log
+// panel on startup.
+const historyPreload = 200
+
+// WithHistory fills the log panel with the most recent events recorded in
+// store, so it survives restarts.
+func WithHistory(store *server.HistoryStore) Option {
+	return func(m *rootModel) {
+		events, err := store.Query(server.HistoryQuery{Limit: historyPreload})
+		if err != nil {
+			m.logLines = append(m.logLines, fmt.Sprintf("failed to load history: %v", err))
+			return
+		}
+		for _, event := range events {
+			m.logLines = append(m.logLines, formatAuditEvent(event))
+		}
+	}
+}
+
 // New creates a TUIPrompter and the associated bubbletea Program.
-func New() (*TUIPrompter, *tea.Program) {
+func New(opts ...Option) (*TUIPrompter, *tea.Program) {
 	model := rootModel{}
+	for _, opt := range opts {
+		opt(&model)
+	}
 	program := tea.NewProgram(model, tea.WithAltScreen())
 
 	prompter := &TUIPrompter{
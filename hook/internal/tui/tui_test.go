@@ -1,12 +1,17 @@
 package tui
 
 import (
+	"context"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
 	"github.com/ngicks/crabswarm/hook/internal/server"
+	"github.com/ngicks/crabswarm/hook/sdk/pathguard"
+	"github.com/ngicks/crabswarm/internal/tmux"
 )
 
 type testReq struct {
@@ -60,8 +65,8 @@ func TestRootModel_PermissionRequest(t *testing.T) {
 	if m.state != statePermission {
 		t.Errorf("state = %d, want statePermission", m.state)
 	}
-	if m.replyCh == nil {
-		t.Error("replyCh should be set")
+	if m.activeRequest() != tr.msg.req {
+		t.Error("request should be active")
 	}
 
 	view := m.View()
@@ -100,8 +105,8 @@ func TestRootModel_Queueing(t *testing.T) {
 	result, _ = m.Update(tr2.msg)
 	m = result.(rootModel)
 
-	if len(m.queuedReqs) != 1 {
-		t.Errorf("queued = %d, want 1", len(m.queuedReqs))
+	if len(m.pending) != 2 {
+		t.Errorf("pending = %d, want 2", len(m.pending))
 	}
 
 	// Complete first request → dequeues second
@@ -123,8 +128,139 @@ func TestRootModel_Queueing(t *testing.T) {
 	if m.state != statePermission {
 		t.Errorf("state after dequeue = %d, want statePermission", m.state)
 	}
-	if len(m.queuedReqs) != 0 {
-		t.Errorf("queued after dequeue = %d, want 0", len(m.queuedReqs))
+	if m.activeRequest() != tr2.msg.req {
+		t.Error("second request should be active after dequeue")
+	}
+	if len(m.pending) != 1 {
+		t.Errorf("pending after dequeue = %d, want 1", len(m.pending))
+	}
+}
+
+func TestRootModel_SessionDashboard(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := initModel(120, 40)
+	m.now = func() time.Time { return now }
+
+	send := func(tr testReq, session string, age time.Duration) testReq {
+		tr.msg.req.SessionId = session
+		now = now.Add(age)
+		result, _ := m.Update(tr.msg)
+		m = result.(rootModel)
+		return tr
+	}
+	a1 := send(makeReq("Bash", `{"command":"ls"}`), "sess-a", 0)
+	b1 := send(makeReq("Write", `{"file_path":"/tmp/x"}`), "sess-b", 10*time.Second)
+	a2 := send(makeReq("Read", `{"file_path":"/tmp/y"}`), "sess-a", 10*time.Second)
+	now = now.Add(70 * time.Second)
+
+	// Sessions are listed oldest first, each with its requests.
+	if got := dashboardOrder(m.pending); !slices.Equal(got, []*pb.PermissionRequest{a1.msg.req, a2.msg.req, b1.msg.req}) {
+		t.Errorf("dashboard order = %v", got)
+	}
+	view := m.View()
+	for _, want := range []string{"Sessions (2)", "sess-a 1m30s", "sess-b 1m20s", "3 requests pending in 2 session(s)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
+
+	// tab walks the dashboard order; answer b1 out of order.
+	for _, want := range []*pb.PermissionRequest{a2.msg.req, b1.msg.req} {
+		result, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		m = result.(rootModel)
+		if m.activeRequest() != want {
+			t.Fatalf("active = %v, want %v", m.activeRequest(), want)
+		}
+	}
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = result.(rootModel)
+	result, _ = m.Update(cmd())
+	m = result.(rootModel)
+
+	select {
+	case r := <-b1.readyCh:
+		if r.response.GetHookSpecificOutput().GetPermissionDecision() != pb.PermissionDecision_PERMISSION_DECISION_ALLOW {
+			t.Error("expected ALLOW for the selected request")
+		}
+	default:
+		t.Fatal("expected reply on the selected request's channel")
+	}
+	select {
+	case <-a1.readyCh:
+		t.Error("first request should still be pending")
+	default:
+	}
+
+	// The session blocked longest is next.
+	if m.activeRequest() != a1.msg.req {
+		t.Errorf("active after answer = %v, want first request", m.activeRequest())
+	}
+
+	// A late answer for a request that is no longer active still reaches it.
+	result, _ = m.Update(promptCompleteMsg{req: a2.msg.req, response: &pb.PermissionResponse{}})
+	m = result.(rootModel)
+	select {
+	case <-a2.readyCh:
+	default:
+		t.Error("expected reply for the inactive request")
+	}
+	if m.activeRequest() != a1.msg.req || len(m.pending) != 1 {
+		t.Errorf("active = %v, pending = %d", m.activeRequest(), len(m.pending))
+	}
+}
+
+func TestRootModel_RequestCancelled(t *testing.T) {
+	m := initModel(120, 40)
+	first := makeReq("Bash", `{"command":"ls"}`)
+	second := makeReq("Write", `{"file_path":"/tmp/x"}`)
+	third := makeReq("Read", `{"file_path":"/tmp/y"}`)
+	for _, tr := range []testReq{first, second, third} {
+		result, _ := m.Update(tr.msg)
+		m = result.(rootModel)
+	}
+
+	// Cancelling a queued request drops it and logs why.
+	result, _ := m.Update(requestCancelledMsg{req: second.msg.req, err: server.ErrAnsweredElsewhere})
+	m = result.(rootModel)
+	if len(m.pending) != 2 || m.activeRequest() != first.msg.req {
+		t.Fatalf("pending = %d, active = %v", len(m.pending), m.activeRequest())
+	}
+	if last := m.logLines[len(m.logLines)-1]; !strings.Contains(last, "Write request withdrawn: answered in another front-end") {
+		t.Errorf("log line = %q", last)
+	}
+	if m.notice != "" {
+		t.Errorf("notice = %q, want none for a queued request", m.notice)
+	}
+
+	// Cancelling the active request moves on to the next one and says so.
+	result, _ = m.Update(requestCancelledMsg{req: first.msg.req, err: context.Canceled})
+	m = result.(rootModel)
+	if m.activeRequest() != third.msg.req || m.permModel.req != third.msg.req {
+		t.Fatalf("active = %v, want third request", m.activeRequest())
+	}
+	if !strings.Contains(m.View(), "Bash request withdrawn: the hook client stopped waiting") {
+		t.Error("view should show the withdrawn notice")
+	}
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = result.(rootModel)
+	if m.notice != "" {
+		t.Errorf("notice = %q, want cleared by a key press", m.notice)
+	}
+	for _, tr := range []testReq{first, second} {
+		select {
+		case <-tr.readyCh:
+			t.Error("cancelled request should not be answered")
+		default:
+		}
+	}
+
+	// Cancelling an unknown request is a no-op.
+	result, _ = m.Update(requestCancelledMsg{req: first.msg.req})
+	m = result.(rootModel)
+	result, _ = m.Update(requestCancelledMsg{req: third.msg.req})
+	m = result.(rootModel)
+	if m.state != stateIdle || len(m.pending) != 0 {
+		t.Errorf("state = %v, pending = %d, want idle", m.state, len(m.pending))
 	}
 }
 
@@ -360,6 +496,74 @@ func TestPermissionModel_View(t *testing.T) {
 	}
 }
 
+func TestRootModel_EditDiff(t *testing.T) {
+	m := initModel(120, 40)
+	edit := makeReq("Edit", `{"file_path":"/nonexistent/main.go","old_string":"foo()","new_string":"bar()"}`)
+	other := makeReq("Bash", `{"command":"ls"}`)
+
+	result, _ := m.Update(edit.msg)
+	m = result.(rootModel)
+	if view := m.View(); !strings.Contains(view, "Computing diff") {
+		t.Errorf("view should show a placeholder until the diff is computed:\n%s", view)
+	}
+	if _, cmd := m.Update(other.msg); cmd != nil {
+		t.Error("a request without a diff should not start a command")
+	}
+
+	result, _ = m.Update(computeDiff(edit.msg.req)())
+	m = result.(rootModel)
+	view := m.View()
+	for _, want := range []string{"/nonexistent/main.go", "-foo()", "+bar()"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, `"old_string"`) {
+		t.Errorf("view should show the diff instead of the JSON input:\n%s", view)
+	}
+
+	// Re-activating the request reuses the cached diff.
+	result, _ = m.Update(other.msg)
+	m = result.(rootModel)
+	for range 2 {
+		result, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		m = result.(rootModel)
+	}
+	if m.activeRequest() != edit.msg.req || !strings.Contains(m.View(), "+bar()") {
+		t.Errorf("re-activated view should show the cached diff:\n%s", m.View())
+	}
+}
+
+func TestRootModel_Countdown(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := initModel(120, 40)
+	m.now = func() time.Time { return now }
+
+	perm := makeReq("Bash", `{"command":"make"}`)
+	perm.msg.countdown = countdown{deadline: now.Add(2 * time.Minute), action: server.RuleActionDeny}
+	plan := makeReq("ExitPlanMode", `{"plan":"do it"}`)
+	plan.msg.countdown = countdown{deadline: now.Add(5 * time.Minute)}
+	ask := makeReq("AskUserQuestion", `{"questions":[{"question":"Which?","header":"H","options":[{"label":"A"}]}]}`)
+	ask.msg.countdown = countdown{deadline: now.Add(30 * time.Second), action: server.RuleActionAsk}
+	for _, tr := range []testReq{perm, plan, ask} {
+		result, _ := m.Update(tr.msg)
+		m = result.(rootModel)
+	}
+
+	now = now.Add(30 * time.Second)
+	for _, want := range []string{"Auto-deny in 1m30s", "Hook client gives up in 4m30s", "Auto-ask in 0s"} {
+		if view := m.View(); !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+		result, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		m = result.(rootModel)
+	}
+
+	if got := (countdown{}).View(); got != "" {
+		t.Errorf("countdown without deadline = %q, want empty", got)
+	}
+}
+
 func TestAskUserModel_View(t *testing.T) {
 	req := &pb.PermissionRequest{
 		HookEventName: "PreToolUse",
@@ -504,3 +708,221 @@ func TestRootModel_ViewportResizeOnStateChange(t *testing.T) {
 		t.Errorf("restored viewport height = %d, want %d", restoredHeight, idleHeight)
 	}
 }
+
+func TestRootModel_PathGuardTarget(t *testing.T) {
+	m := rootModel{pathGuard: pathguard.New()}
+	tr := makeReq("Write", `{"file_path":"/etc/passwd","content":"x"}`)
+	tr.msg.req.Cwd = t.TempDir()
+
+	result, _ := m.Update(tr.msg)
+	m = result.(rootModel)
+
+	if m.permModel.target == nil {
+		t.Fatal("expected path guard target")
+	}
+	if m.permModel.target.Location != pathguard.LocationOutside {
+		t.Errorf("location = %q, want outside", m.permModel.target.Location)
+	}
+	if !strings.Contains(m.View(), "Path:") {
+		t.Error("view should show the resolved path")
+	}
+}
+
+func TestPermissionModel_GrantChoice(t *testing.T) {
+	store, err := server.NewGrantStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &pb.PermissionRequest{
+		HookEventName: "PreToolUse",
+		ToolName:      "WebFetch",
+		SessionId:     "sess-1",
+		ToolInputJson: `{"url":"https://example.com"}`,
+	}
+	m := newPermissionModel(req, 80, 24).withGrants(store)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if cmd == nil {
+		t.Fatal("expected command from 's' shortcut")
+	}
+	complete, ok := cmd().(promptCompleteMsg)
+	if !ok {
+		t.Fatal("expected promptCompleteMsg")
+	}
+	if complete.response.HookSpecificOutput.PermissionDecision != pb.PermissionDecision_PERMISSION_DECISION_ALLOW {
+		t.Error("expected ALLOW decision")
+	}
+	grants := store.List()
+	if len(grants) != 1 || grants[0].Scope != server.GrantScopeSession {
+		t.Fatalf("grants = %+v, want one session grant", grants)
+	}
+	if _, ok := store.Match(req); !ok {
+		t.Error("grant should match the same request")
+	}
+
+	// Bash is only granted per exact command.
+	bash := &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: "Bash", SessionId: "sess-1", ToolInputJson: `{"command":"ls"}`}
+	m = newPermissionModel(bash, 80, 24).withGrants(store)
+	if slices.Contains(m.choices, choiceAllowSession) || slices.Contains(m.choices, choiceAllowTool) {
+		t.Errorf("choices = %q, want no session or tool grant for Bash", m.choices)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}); cmd != nil {
+		t.Error("expected no command from 's' for Bash")
+	}
+
+	// Without a store the grant shortcuts are ignored.
+	m = newPermissionModel(req, 80, 24)
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}); cmd != nil {
+		t.Error("expected no command without a grant store")
+	}
+}
+
+func TestRootModel_GrantsPane(t *testing.T) {
+	store, err := server.NewGrantStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(server.NewGrant(server.GrantScopeTool, &pb.PermissionRequest{ToolName: "Read"}, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initModel(80, 40)
+	m.grants = store
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = result.(rootModel)
+	if !m.showGrants {
+		t.Fatal("ctrl+g should open the grants pane")
+	}
+	if !strings.Contains(m.View(), "Read in any session") {
+		t.Error("grants pane should list the grant")
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = result.(rootModel)
+	if len(store.List()) != 0 {
+		t.Error("'r' should revoke the selected grant")
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = result.(rootModel)
+	result, _ = m.Update(cmd())
+	m = result.(rootModel)
+	if m.showGrants {
+		t.Error("esc should close the grants pane")
+	}
+}
+
+func TestIdleSessionFromHook(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"stop", `{"hook_event_name":"Stop"}`, "finished"},
+		{"stop hook active", `{"hook_event_name":"Stop","stop_hook_active":true}`, ""},
+		{"idle notification", `{"hook_event_name":"Notification","notification_type":"idle_prompt","message":"Claude is waiting"}`, "Claude is waiting"},
+		{"permission notification", `{"hook_event_name":"Notification","notification_type":"permission_prompt"}`, ""},
+		{"post tool use", `{"hook_event_name":"PostToolUse"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := idleSessionFromHook(&pb.HookRequest{SessionId: "s1", HookInputJson: tt.input}, time.Now())
+			if ok != (tt.want != "") || msg.session.reason != tt.want {
+				t.Errorf("idleSessionFromHook = %q, %v, want %q", msg.session.reason, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPane(t *testing.T) {
+	const socket = "/tmp/tmux-1000/crabswarm"
+	panes := []tmux.Pane{
+		{ID: "%1", Options: map[string]string{tmux.MemberOption: "fix-bug"}},
+		{ID: "%2", Options: map[string]string{tmux.MemberOption: ""}},
+		{ID: "%3", Dead: true, Options: map[string]string{tmux.MemberOption: "old"}},
+	}
+	tests := []struct {
+		name string
+		pane *pb.TmuxPane
+		ok   bool
+	}{
+		{"member", &pb.TmuxPane{SocketPath: socket, PaneId: "%1", Member: "fix-bug"}, true},
+		{"member without name", &pb.TmuxPane{SocketPath: socket + "/", PaneId: "%1"}, true},
+		{"other server", &pb.TmuxPane{SocketPath: "/tmp/evil", PaneId: "%1", Member: "fix-bug"}, false},
+		{"no socket", &pb.TmuxPane{PaneId: "%1"}, false},
+		{"not a member", &pb.TmuxPane{SocketPath: socket, PaneId: "%2"}, false},
+		{"wrong member", &pb.TmuxPane{SocketPath: socket, PaneId: "%1", Member: "other"}, false},
+		{"dead", &pb.TmuxPane{SocketPath: socket, PaneId: "%3"}, false},
+		{"unknown pane", &pb.TmuxPane{SocketPath: socket, PaneId: "%9"}, false},
+		{"target syntax", &pb.TmuxPane{SocketPath: socket, PaneId: "crabswarm:0"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPane(tt.pane, socket, panes)
+			if (err == nil) != tt.ok {
+				t.Errorf("checkPane = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRootModel_ReplyToIdleSession(t *testing.T) {
+	type sent struct {
+		pane *pb.TmuxPane
+		text string
+	}
+	var got []sent
+	m := initModel(80, 40)
+	m.send = func(_ context.Context, pane *pb.TmuxPane, text string) error {
+		got = append(got, sent{pane, text})
+		return nil
+	}
+
+	pane := &pb.TmuxPane{PaneId: "%3", Member: "fix-bug"}
+	result, _ := m.Update(idleSessionMsg{session: idleSession{sessionID: "s1", reason: "finished", since: time.Now()}, pane: pane})
+	m = result.(rootModel)
+	if !strings.Contains(m.View(), "1 idle session(s)") {
+		t.Error("view should show the idle session")
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = result.(rootModel)
+	if !m.showReply {
+		t.Fatal("ctrl+r should open the reply pane")
+	}
+	if !strings.Contains(m.View(), "fix-bug (%3)") {
+		t.Error("reply pane should show the session's pane")
+	}
+
+	for _, r := range "run the tests" {
+		result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(rootModel)
+	}
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(rootModel)
+	if cmd == nil {
+		t.Fatal("expected send command")
+	}
+	result, _ = m.Update(cmd())
+	m = result.(rootModel)
+
+	if len(got) != 1 || got[0].pane != pane || got[0].text != "run the tests" {
+		t.Fatalf("sent = %+v", got)
+	}
+	if m.showReply || len(m.idle) != 0 {
+		t.Errorf("showReply = %v, idle = %d, want closed and none idle", m.showReply, len(m.idle))
+	}
+	if !strings.Contains(strings.Join(m.logLines, "\n"), "reply sent to session s1") {
+		t.Error("log panel should record the reply")
+	}
+
+	// A permission request marks the session busy again.
+	result, _ = m.Update(idleSessionMsg{session: idleSession{sessionID: "test-session"}, pane: pane})
+	m = result.(rootModel)
+	result, _ = m.Update(makeReq("Bash", `{"command":"ls"}`).msg)
+	m = result.(rootModel)
+	if len(m.idle) != 0 {
+		t.Errorf("idle = %d, want 0 after a permission request", len(m.idle))
+	}
+}
@@ -0,0 +1,407 @@
+// Package web implements a Prompter answered from a browser. It serves a
+// small embedded single-page app that lists pending requests, receives
+// updates over Server-Sent Events and posts decisions back.
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Request kinds, selecting how the app renders a request.
+const (
+	kindPermission = "permission"
+	kindAskUser    = "askUser"
+	kindExitPlan   = "exitPlan"
+)
+
+// Request is a pending request as sent to the app.
+type Request struct {
+	ID            string               `json:"id"`
+	Kind          string               `json:"kind"`
+	HookEventName string               `json:"hookEventName"`
+	ToolName      string               `json:"toolName"`
+	SessionID     string               `json:"sessionId"`
+	Cwd           string               `json:"cwd,omitempty"`
+	ToolInput     json.RawMessage      `json:"toolInput,omitempty"`
+	Questions     []server.AskQuestion `json:"questions,omitempty"`
+	Since         time.Time            `json:"since"`
+}
+
+// pending is a request waiting for an answer.
+type pending struct {
+	view    Request
+	req     *pb.PermissionRequest
+	ask     server.AskUserQuestionInput
+	replyCh chan *pb.PermissionResponse
+}
+
+// Prompter is a server.Prompter answered through the web app. Its Handler
+// must be served over HTTP.
+type Prompter struct {
+	token string
+
+	mu          sync.Mutex
+	nextID      int
+	pending     []*pending
+	subscribers map[chan struct{}]struct{}
+}
+
+// Option configures a Prompter.
+type Option func(*Prompter)
+
+// WithToken sets the token required on every API request, either as a
+// bearer token or as the "token" query parameter (which the app forwards).
+// Without it, New generates a random token.
+func WithToken(token string) Option {
+	return func(p *Prompter) {
+		p.token = token
+	}
+}
+
+// New creates a Prompter.
+func New(opts ...Option) *Prompter {
+	p := &Prompter{subscribers: make(map[chan struct{}]struct{})}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.token == "" {
+		p.token = rand.Text()
+	}
+	return p
+}
+
+// Token returns the token required on API requests. Open the app as
+// /?token=<token> to let it authorize itself.
+func (p *Prompter) Token() string {
+	return p.token
+}
+
+// Prompt publishes req to the app and blocks until it is answered or ctx
+// is done.
+func (p *Prompter) Prompt(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	pr := p.add(req)
+	select {
+	case <-ctx.Done():
+		p.remove(pr.view.ID)
+		return nil, ctx.Err()
+	case resp := <-pr.replyCh:
+		return resp, nil
+	}
+}
+
+func (p *Prompter) add(req *pb.PermissionRequest) *pending {
+	pr := &pending{
+		req: req,
+		view: Request{
+			Kind:          kindPermission,
+			HookEventName: req.GetHookEventName(),
+			ToolName:      req.GetToolName(),
+			SessionID:     req.GetSessionId(),
+			Cwd:           req.GetCwd(),
+			Since:         time.Now(),
+		},
+		replyCh: make(chan *pb.PermissionResponse, 1),
+	}
+	if json.Valid([]byte(req.GetToolInputJson())) {
+		pr.view.ToolInput = json.RawMessage(req.GetToolInputJson())
+	}
+	switch model.ToolName(req.GetToolName()) {
+	case model.ToolNameAskUserQuestion:
+		if input, err := server.AskUserInputFromRequest(req); err == nil && len(input.Questions) > 0 {
+			pr.view.Kind = kindAskUser
+			pr.view.Questions = input.Questions
+			pr.ask = input
+		}
+	case model.ToolNameExitPlanMode:
+		if _, err := server.ExitPlanModeInputFromRequest(req); err == nil {
+			pr.view.Kind = kindExitPlan
+		}
+	}
+
+	p.mu.Lock()
+	p.nextID++
+	pr.view.ID = strconv.Itoa(p.nextID)
+	p.pending = append(p.pending, pr)
+	p.mu.Unlock()
+	p.notify()
+	return pr
+}
+
+// remove removes the request with id and returns it.
+func (p *Prompter) remove(id string) (*pending, bool) {
+	p.mu.Lock()
+	var found *pending
+	for i, pr := range p.pending {
+		if pr.view.ID == id {
+			found = pr
+			p.pending = append(p.pending[:i:i], p.pending[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+	if found == nil {
+		return nil, false
+	}
+	p.notify()
+	return found, true
+}
+
+// Requests returns the pending requests in arrival order.
+func (p *Prompter) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Request, len(p.pending))
+	for i, pr := range p.pending {
+		out[i] = pr.view
+	}
+	return out
+}
+
+func (p *Prompter) find(id string) (*pending, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pr := range p.pending {
+		if pr.view.ID == id {
+			return pr, true
+		}
+	}
+	return nil, false
+}
+
+// notify wakes up all event streams.
+func (p *Prompter) notify() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for ch := range p.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *Prompter) subscribe() (chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	p.mu.Lock()
+	p.subscribers[ch] = struct{}{}
+	p.mu.Unlock()
+	return ch, func() {
+		p.mu.Lock()
+		delete(p.subscribers, ch)
+		p.mu.Unlock()
+	}
+}
+
+// Handler returns the HTTP handler serving the app and its API:
+//
+//	GET  /                            the app
+//	GET  /api/requests                pending requests (JSON)
+//	GET  /api/events                  pending requests on every change (SSE)
+//	POST /api/requests/{id}/decision  {"decision": "allow|deny|ask", "reason": "..."}
+//	POST /api/requests/{id}/answers   {"answers": {"question": "answer", ...}}
+//	POST /api/requests/{id}/plan      {} (approves an ExitPlanMode plan)
+//
+// "allow" is rejected for AskUserQuestion and ExitPlanMode requests, which
+// take the answers and plan endpoints instead.
+func (p *Prompter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	static, _ := fs.Sub(staticFiles, "static")
+	mux.Handle("GET /", http.FileServerFS(static))
+	mux.HandleFunc("GET /api/requests", p.handleList)
+	mux.HandleFunc("GET /api/events", p.handleEvents)
+	mux.HandleFunc("POST /api/requests/{id}/decision", p.handleDecision)
+	mux.HandleFunc("POST /api/requests/{id}/answers", p.handleAnswers)
+	mux.HandleFunc("POST /api/requests/{id}/plan", p.handlePlan)
+	return p.authorize(mux)
+}
+
+// authorize rejects API calls without the token. The app itself is served
+// without it so that it can read the token from its URL.
+//
+// POSTs must also be JSON: a cross-site page cannot send that without a
+// CORS preflight, which is never granted, so it cannot forge an answer.
+func (p *Prompter) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			got := r.URL.Query().Get("token")
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				got = bearer
+			}
+			if subtle.ConstantTimeCompare([]byte(got), []byte(p.token)) != 1 {
+				http.Error(w, "invalid or missing token", http.StatusUnauthorized)
+				return
+			}
+			if r.Method == http.MethodPost {
+				if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+					http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p *Prompter) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, p.Requests())
+}
+
+func (p *Prompter) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch, unsubscribe := p.subscribe()
+	defer unsubscribe()
+
+	for {
+		data, err := json.Marshal(p.Requests())
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: requests\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+		}
+	}
+}
+
+type decisionBody struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+func (p *Prompter) handleDecision(w http.ResponseWriter, r *http.Request) {
+	var body decisionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var decision pb.PermissionDecision
+	switch body.Decision {
+	case "allow":
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ALLOW
+	case "deny":
+		decision = pb.PermissionDecision_PERMISSION_DECISION_DENY
+	case "ask":
+		decision = pb.PermissionDecision_PERMISSION_DECISION_ASK
+	default:
+		http.Error(w, fmt.Sprintf("invalid decision %q", body.Decision), http.StatusBadRequest)
+		return
+	}
+
+	p.answer(w, r.PathValue("id"), func(pr *pending) (*pb.PermissionResponse, error) {
+		if decision == pb.PermissionDecision_PERMISSION_DECISION_ALLOW && !approvable(pr.req) {
+			return nil, fmt.Errorf("request %s cannot be allowed without answering it", pr.view.ID)
+		}
+		return server.BuildPermissionResponse(pr.req, decision, body.Reason), nil
+	})
+}
+
+// approvable reports whether req can be allowed with a plain decision.
+// AskUserQuestion must be answered through the answers endpoint and an
+// ExitPlanMode plan approved through the plan endpoint, where it is shown.
+func approvable(req *pb.PermissionRequest) bool {
+	switch model.ToolName(req.GetToolName()) {
+	case model.ToolNameAskUserQuestion, model.ToolNameExitPlanMode:
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *Prompter) handlePlan(w http.ResponseWriter, r *http.Request) {
+	var body struct{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.answer(w, r.PathValue("id"), func(pr *pending) (*pb.PermissionResponse, error) {
+		if pr.view.Kind != kindExitPlan {
+			return nil, fmt.Errorf("request %s is not a plan", pr.view.ID)
+		}
+		return server.BuildPermissionResponse(pr.req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, ""), nil
+	})
+}
+
+type answersBody struct {
+	Answers map[string]string `json:"answers"`
+}
+
+func (p *Prompter) handleAnswers(w http.ResponseWriter, r *http.Request) {
+	var body answersBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.answer(w, r.PathValue("id"), func(pr *pending) (*pb.PermissionResponse, error) {
+		if pr.view.Kind != kindAskUser {
+			return nil, fmt.Errorf("request %s is not a question", pr.view.ID)
+		}
+		for _, q := range pr.ask.Questions {
+			if body.Answers[q.Question] == "" {
+				return nil, fmt.Errorf("missing answer to %q", q.Question)
+			}
+		}
+		return server.BuildAskUserResponse(pr.req, pr.ask, body.Answers)
+	})
+}
+
+// answer resolves the request id with the response built by build.
+func (p *Prompter) answer(w http.ResponseWriter, id string, build func(*pending) (*pb.PermissionResponse, error)) {
+	pr, ok := p.find(id)
+	if !ok {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+	resp, err := build(pr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Another client may have answered in the meantime.
+	if _, ok := p.remove(id); !ok {
+		http.Error(w, "request not found", http.StatusNotFound)
+		return
+	}
+	resp.DecisionSource = pb.DecisionSource_DECISION_SOURCE_WEB
+	pr.replyCh <- resp
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
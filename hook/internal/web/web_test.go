@@ -0,0 +1,351 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+	"google.golang.org/protobuf/proto"
+)
+
+type promptResult struct {
+	resp *pb.PermissionResponse
+	err  error
+}
+
+// startPrompt runs p.Prompt in the background and waits until req is
+// pending.
+func startPrompt(t *testing.T, ctx context.Context, p *Prompter, req *pb.PermissionRequest) (string, <-chan promptResult) {
+	t.Helper()
+	before := len(p.Requests())
+	ch := make(chan promptResult, 1)
+	go func() {
+		resp, err := p.Prompt(ctx, req)
+		ch <- promptResult{resp, err}
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if reqs := p.Requests(); len(reqs) > before {
+			return reqs[len(reqs)-1].ID, ch
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("request was not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// serve serves p, authorizing every request with p's token.
+func serve(p *Prompter) *httptest.Server {
+	h := p.Handler()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+p.Token())
+		h.ServeHTTP(w, r)
+	}))
+}
+
+func post(t *testing.T, srv *httptest.Server, path, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestPrompter_Decision(t *testing.T) {
+	p := New()
+	srv := serve(p)
+	defer srv.Close()
+
+	req := &pb.PermissionRequest{
+		HookEventName: "PreToolUse",
+		ToolName:      "Bash",
+		ToolInputJson: `{"command":"ls"}`,
+		SessionId:     "s1",
+	}
+	id, result := startPrompt(t, context.Background(), p, req)
+
+	resp, err := http.Get(srv.URL + "/api/requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []Request
+	if err := json.NewDecoder(resp.Body).Decode(&listed); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(listed) != 1 || listed[0].Kind != kindPermission || listed[0].ToolName != "Bash" ||
+		string(listed[0].ToolInput) != `{"command":"ls"}` {
+		t.Fatalf("listed = %+v", listed)
+	}
+
+	if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"maybe"}`); r.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid decision status = %d", r.StatusCode)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/answers", `{"answers":{}}`); r.StatusCode != http.StatusBadRequest {
+		t.Errorf("answers to a permission request status = %d", r.StatusCode)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"deny","reason":"no"}`); r.StatusCode != http.StatusNoContent {
+		t.Fatalf("decision status = %d", r.StatusCode)
+	}
+
+	got := <-result
+	want := server.BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_DENY, "no")
+	want.DecisionSource = pb.DecisionSource_DECISION_SOURCE_WEB
+	if got.err != nil || !proto.Equal(got.resp, want) {
+		t.Errorf("Prompt() = %v, %v, want %v", got.resp, got.err, want)
+	}
+	if len(p.Requests()) != 0 {
+		t.Error("answered request is still pending")
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"allow"}`); r.StatusCode != http.StatusNotFound {
+		t.Errorf("second decision status = %d", r.StatusCode)
+	}
+}
+
+func TestPrompter_Answers(t *testing.T) {
+	p := New()
+	srv := serve(p)
+	defer srv.Close()
+
+	req := &pb.PermissionRequest{
+		HookEventName: "PreToolUse",
+		ToolName:      "AskUserQuestion",
+		ToolInputJson: `{"questions":[{"question":"Which?","header":"Pick","options":[{"label":"A"},{"label":"B"}],"multiSelect":true}]}`,
+	}
+	id, result := startPrompt(t, context.Background(), p, req)
+
+	if reqs := p.Requests(); reqs[0].Kind != kindAskUser || len(reqs[0].Questions) != 1 {
+		t.Fatalf("requests = %+v", reqs)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"allow"}`); r.StatusCode != http.StatusBadRequest {
+		t.Errorf("allow without answers status = %d", r.StatusCode)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/plan", `{}`); r.StatusCode != http.StatusBadRequest {
+		t.Errorf("plan approval of a question status = %d", r.StatusCode)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/answers", `{"answers":{}}`); r.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing answer status = %d", r.StatusCode)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/answers", `{"answers":{"Which?":"A, B"}}`); r.StatusCode != http.StatusNoContent {
+		t.Fatalf("answers status = %d", r.StatusCode)
+	}
+
+	got := <-result
+	input, _ := server.ParseAskUserInput(req.GetToolInputJson())
+	want, err := server.BuildAskUserResponse(req, input, map[string]string{"Which?": "A, B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.DecisionSource = pb.DecisionSource_DECISION_SOURCE_WEB
+	if got.err != nil || !proto.Equal(got.resp, want) {
+		t.Errorf("Prompt() = %v, %v, want %v", got.resp, got.err, want)
+	}
+}
+
+func TestPrompter_Plan(t *testing.T) {
+	p := New()
+	srv := serve(p)
+	defer srv.Close()
+
+	req := &pb.PermissionRequest{
+		HookEventName: "PreToolUse",
+		ToolName:      "ExitPlanMode",
+		ToolInputJson: `{"plan":"1. do it"}`,
+	}
+	id, result := startPrompt(t, context.Background(), p, req)
+
+	if reqs := p.Requests(); reqs[0].Kind != kindExitPlan {
+		t.Fatalf("requests = %+v", reqs)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"allow"}`); r.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain allow of a plan status = %d", r.StatusCode)
+	}
+	if r := post(t, srv, "/api/requests/"+id+"/plan", `{}`); r.StatusCode != http.StatusNoContent {
+		t.Fatalf("plan status = %d", r.StatusCode)
+	}
+
+	got := <-result
+	want := server.BuildPermissionResponse(req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "")
+	want.DecisionSource = pb.DecisionSource_DECISION_SOURCE_WEB
+	if got.err != nil || !proto.Equal(got.resp, want) {
+		t.Errorf("Prompt() = %v, %v, want %v", got.resp, got.err, want)
+	}
+}
+
+func TestPrompter_PlanRequiresPlan(t *testing.T) {
+	p := New()
+	srv := serve(p)
+	defer srv.Close()
+
+	// Unparsable questions and plans can still be denied, but not allowed.
+	for _, tool := range []string{"AskUserQuestion", "ExitPlanMode"} {
+		id, result := startPrompt(t, context.Background(), p, &pb.PermissionRequest{ToolName: tool, ToolInputJson: "not json"})
+		if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"allow"}`); r.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: allow status = %d", tool, r.StatusCode)
+		}
+		if r := post(t, srv, "/api/requests/"+id+"/plan", `{}`); r.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: plan status = %d", tool, r.StatusCode)
+		}
+		if r := post(t, srv, "/api/requests/"+id+"/decision", `{"decision":"deny"}`); r.StatusCode != http.StatusNoContent {
+			t.Fatalf("%s: deny status = %d", tool, r.StatusCode)
+		}
+		if got := <-result; got.resp.GetHookSpecificOutput().GetPermissionDecision() != pb.PermissionDecision_PERMISSION_DECISION_DENY {
+			t.Errorf("%s: decision = %v", tool, got.resp.GetHookSpecificOutput().GetPermissionDecision())
+		}
+	}
+}
+
+func TestPrompter_Cancel(t *testing.T) {
+	p := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	_, result := startPrompt(t, ctx, p, &pb.PermissionRequest{ToolName: "ExitPlanMode", ToolInputJson: `{"plan":"x"}`})
+	if reqs := p.Requests(); reqs[0].Kind != kindExitPlan {
+		t.Errorf("kind = %q, want %q", reqs[0].Kind, kindExitPlan)
+	}
+
+	cancel()
+	if got := <-result; got.err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", got.err)
+	}
+	if len(p.Requests()) != 0 {
+		t.Error("cancelled request is still pending")
+	}
+}
+
+func TestPrompter_Events(t *testing.T) {
+	p := New()
+	srv := serve(p)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() []Request {
+		t.Helper()
+		for lines.Scan() {
+			if data, ok := strings.CutPrefix(lines.Text(), "data: "); ok {
+				var reqs []Request
+				if err := json.Unmarshal([]byte(data), &reqs); err != nil {
+					t.Fatal(err)
+				}
+				return reqs
+			}
+		}
+		t.Fatalf("event stream ended: %v", lines.Err())
+		return nil
+	}
+
+	if reqs := next(); len(reqs) != 0 {
+		t.Fatalf("initial event = %+v", reqs)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Prompt(ctx, &pb.PermissionRequest{ToolName: "Read"})
+	if reqs := next(); len(reqs) != 1 || reqs[0].ToolName != "Read" {
+		t.Fatalf("event = %+v", reqs)
+	}
+	cancel()
+	if reqs := next(); len(reqs) != 0 {
+		t.Fatalf("event after cancel = %+v", reqs)
+	}
+}
+
+func TestPrompter_Token(t *testing.T) {
+	p := New(WithToken("secret"))
+	srv := httptest.NewServer(p.Handler())
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"app", "/", "", http.StatusOK},
+		{"missing", "/api/requests", "", http.StatusUnauthorized},
+		{"wrong", "/api/requests?token=nope", "", http.StatusUnauthorized},
+		{"query", "/api/requests?token=secret", "", http.StatusOK},
+		{"bearer", "/api/requests", "Bearer secret", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrompter_GeneratedToken(t *testing.T) {
+	p, q := New(), New()
+	if p.Token() == "" || p.Token() == q.Token() {
+		t.Fatalf("tokens = %q, %q, want distinct random tokens", p.Token(), q.Token())
+	}
+	srv := httptest.NewServer(p.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestPrompter_ContentType(t *testing.T) {
+	p := New()
+	srv := serve(p)
+	defer srv.Close()
+
+	id, result := startPrompt(t, context.Background(), p, &pb.PermissionRequest{ToolName: "Bash"})
+
+	// A cross-site form can post text/plain without a preflight.
+	resp, err := http.Post(srv.URL+"/api/requests/"+id+"/decision", "text/plain", strings.NewReader(`{"decision":"allow"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain status = %d, want %d", resp.StatusCode, http.StatusUnsupportedMediaType)
+	}
+	if len(p.Requests()) != 1 {
+		t.Fatal("request answered by a text/plain post")
+	}
+
+	resp, err = http.Post(srv.URL+"/api/requests/"+id+"/decision", "application/json; charset=utf-8", strings.NewReader(`{"decision":"allow"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("application/json status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	<-result
+}
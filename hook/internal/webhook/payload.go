@@ -0,0 +1,139 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+)
+
+// Payload is the body posted in FormatGeneric.
+type Payload struct {
+	ID            string          `json:"id"`
+	Title         string          `json:"title"`
+	Message       string          `json:"message"`
+	HookEventName string          `json:"hookEventName"`
+	ToolName      string          `json:"toolName"`
+	SessionID     string          `json:"sessionId"`
+	Cwd           string          `json:"cwd,omitempty"`
+	ToolInput     json.RawMessage `json:"toolInput,omitempty"`
+	ApproveURL    string          `json:"approveUrl,omitempty"`
+	DenyURL       string          `json:"denyUrl"`
+	ExpiresAt     time.Time       `json:"expiresAt"`
+}
+
+// answerElsewhere is appended to the message of requests without an
+// approve link.
+const answerElsewhere = "Answer in the TUI or the web UI to approve."
+
+// newRequest builds the webhook request announcing req in format.
+func newRequest(ctx context.Context, format Format, url, id string, req *pb.PermissionRequest, links Links) (*http.Request, error) {
+	n := server.NewNotification(req)
+	if links.Approve == "" {
+		n.Message = strings.TrimSpace(n.Message + "\n\n" + answerElsewhere)
+	}
+
+	var (
+		body   any
+		header = http.Header{}
+	)
+	switch format {
+	case FormatSlack:
+		text := "*" + n.Title + "*"
+		if n.Message != "" {
+			text += "\n```" + n.Message + "```"
+		}
+		var buttons []any
+		if links.Approve != "" {
+			buttons = append(buttons, slackButton("Approve", links.Approve, "primary"))
+		}
+		buttons = append(buttons, slackButton("Deny", links.Deny, "danger"))
+		body = map[string]any{
+			"text": n.Title,
+			"blocks": []any{
+				map[string]any{
+					"type": "section",
+					"text": map[string]any{"type": "mrkdwn", "text": text},
+				},
+				map[string]any{
+					"type":     "actions",
+					"elements": buttons,
+				},
+			},
+		}
+	case FormatNtfy:
+		// ntfy takes the message as plain text and the rest as headers; the
+		// "http" actions post to the links directly from the app.
+		header.Set("Title", n.Title)
+		header.Set("Tags", "lock")
+		header.Set("Priority", "high")
+		var actions []string
+		if links.Approve != "" {
+			actions = append(actions, fmt.Sprintf("http, Approve, %s, method=POST, clear=true", links.Approve))
+		}
+		actions = append(actions, fmt.Sprintf("http, Deny, %s, method=POST, clear=true", links.Deny))
+		header.Set("Actions", strings.Join(actions, "; "))
+		return build(ctx, url, header, []byte(n.Message))
+	case FormatGotify:
+		buttons := fmt.Sprintf("[Deny](%s)", links.Deny)
+		if links.Approve != "" {
+			buttons = fmt.Sprintf("[Approve](%s) · %s", links.Approve, buttons)
+		}
+		body = map[string]any{
+			"title":    n.Title,
+			"message":  n.Message + "\n\n" + buttons,
+			"priority": 8,
+			"extras": map[string]any{
+				"client::display": map[string]any{"contentType": "text/markdown"},
+			},
+		}
+	default:
+		p := Payload{
+			ID:            id,
+			Title:         n.Title,
+			Message:       n.Message,
+			HookEventName: req.GetHookEventName(),
+			ToolName:      req.GetToolName(),
+			SessionID:     req.GetSessionId(),
+			Cwd:           req.GetCwd(),
+			ApproveURL:    links.Approve,
+			DenyURL:       links.Deny,
+			ExpiresAt:     links.Expires,
+		}
+		if json.Valid([]byte(req.GetToolInputJson())) {
+			p.ToolInput = json.RawMessage(req.GetToolInputJson())
+		}
+		body = p
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	header.Set("Content-Type", "application/json")
+	return build(ctx, url, header, data)
+}
+
+func slackButton(text, url, style string) map[string]any {
+	return map[string]any{
+		"type":  "button",
+		"text":  map[string]any{"type": "plain_text", "text": text},
+		"url":   url,
+		"style": style,
+	}
+}
+
+func build(ctx context.Context, url string, header http.Header, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header = header
+	return req, nil
+}
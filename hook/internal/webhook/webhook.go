@@ -0,0 +1,361 @@
+// Package webhook implements a Prompter that posts pending requests to a
+// webhook (generic JSON, Slack, ntfy or Gotify) with signed one-time
+// approve/deny links, and serves those links.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+	"github.com/ngicks/crabswarm/hook/internal/server"
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// Format selects the payload posted to the webhook.
+type Format string
+
+const (
+	// FormatGeneric posts the request and its links as JSON (see Payload).
+	FormatGeneric Format = "generic"
+	// FormatSlack posts a Slack incoming webhook message with link buttons.
+	FormatSlack Format = "slack"
+	// FormatNtfy publishes to an ntfy topic URL with HTTP action buttons.
+	FormatNtfy Format = "ntfy"
+	// FormatGotify posts a Gotify message (the URL must include ?token=).
+	FormatGotify Format = "gotify"
+)
+
+// ParseFormat parses a payload format name.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatGeneric, FormatSlack, FormatNtfy, FormatGotify:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid webhook format %q (want generic, slack, ntfy or gotify)", s)
+	}
+}
+
+// DefaultLinkTTL is how long approve/deny links stay valid by default.
+const DefaultLinkTTL = 15 * time.Minute
+
+// Config configures a Prompter.
+type Config struct {
+	// URL is the webhook receiving pending requests.
+	URL string
+	// Format selects the payload. Defaults to FormatGeneric.
+	Format Format
+	// PublicURL is the base URL under which Handler is reachable by whoever
+	// opens the links, e.g. "https://myhost.tailnet.ts.net:8090".
+	PublicURL string
+	// LinkTTL bounds the validity of links. Defaults to DefaultLinkTTL.
+	LinkTTL time.Duration
+	// Client posts to the webhook. Defaults to a client with a 10s timeout.
+	Client *http.Client
+}
+
+// pending is a request waiting for a link to be used.
+type pending struct {
+	req     *pb.PermissionRequest
+	replyCh chan *pb.PermissionResponse
+}
+
+// Prompter is a server.Prompter answered through webhook links. Its Handler
+// must be served at Config.PublicURL.
+type Prompter struct {
+	cfg Config
+	key []byte
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*pending
+}
+
+// New creates a Prompter. Links are signed with a random key, so they do
+// not survive a restart.
+func New(cfg Config) (*Prompter, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	if cfg.PublicURL == "" {
+		return nil, fmt.Errorf("public URL for webhook links is required")
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatGeneric
+	}
+	if _, err := ParseFormat(string(cfg.Format)); err != nil {
+		return nil, err
+	}
+	if cfg.LinkTTL <= 0 {
+		cfg.LinkTTL = DefaultLinkTTL
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate link key: %w", err)
+	}
+	return &Prompter{cfg: cfg, key: key, pending: make(map[string]*pending)}, nil
+}
+
+func (p *Prompter) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// Prompt posts req to the webhook and blocks until one of its links is used
+// or ctx is done, which invalidates the links.
+func (p *Prompter) Prompt(ctx context.Context, req *pb.PermissionRequest) (*pb.PermissionResponse, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	pr := &pending{req: req, replyCh: make(chan *pb.PermissionResponse, 1)}
+	p.mu.Lock()
+	p.pending[id] = pr
+	p.mu.Unlock()
+	defer p.take(id)
+
+	if err := p.post(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-pr.replyCh:
+		return resp, nil
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate request ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// take removes and returns the pending request id, so that each request is
+// answered at most once.
+func (p *Prompter) take(id string) (*pending, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pr, ok := p.pending[id]
+	delete(p.pending, id)
+	return pr, ok
+}
+
+func (p *Prompter) peek(id string) (*pending, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pr, ok := p.pending[id]
+	return pr, ok
+}
+
+// Links are the approve/deny links of a request.
+type Links struct {
+	// Approve is empty for requests that need more than a plain allow (see
+	// approvable).
+	Approve string
+	Deny    string
+	Expires time.Time
+}
+
+func (p *Prompter) links(id string, req *pb.PermissionRequest) Links {
+	expires := p.clock().Add(p.cfg.LinkTTL).Truncate(time.Second)
+	links := Links{
+		Deny:    p.link(id, "deny", expires),
+		Expires: expires,
+	}
+	if approvable(req) {
+		links.Approve = p.link(id, "allow", expires)
+	}
+	return links
+}
+
+// approvable reports whether req can be approved with a link. Approving
+// AskUserQuestion needs the answers and ExitPlanMode the plan reviewed,
+// which only the TUI and the web UI can do.
+func approvable(req *pb.PermissionRequest) bool {
+	switch model.ToolName(req.GetToolName()) {
+	case model.ToolNameAskUserQuestion, model.ToolNameExitPlanMode:
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *Prompter) link(id, decision string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{"exp": {exp}, "sig": {p.sign(id, decision, exp)}}
+	return p.cfg.PublicURL + "/reply/" + id + "/" + decision + "?" + q.Encode()
+}
+
+func (p *Prompter) sign(id, decision, exp string) string {
+	mac := hmac.New(sha256.New, p.key)
+	fmt.Fprintf(mac, "%s\n%s\n%s", id, decision, exp)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks the signature and expiry of a link.
+func (p *Prompter) verify(id, decision, exp, sig string) error {
+	if !hmac.Equal([]byte(sig), []byte(p.sign(id, decision, exp))) {
+		return fmt.Errorf("invalid link signature")
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || p.clock().After(time.Unix(unix, 0)) {
+		return fmt.Errorf("link expired")
+	}
+	return nil
+}
+
+// post sends the payload for req to the webhook.
+func (p *Prompter) post(ctx context.Context, id string, req *pb.PermissionRequest) error {
+	httpReq, err := newRequest(ctx, p.cfg.Format, p.cfg.URL, id, req, p.links(id, req))
+	if err != nil {
+		return err
+	}
+	resp, err := p.cfg.Client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to post to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler serving the links:
+//
+//	GET  /reply/{id}/{decision}  a page confirming the decision
+//	POST /reply/{id}/{decision}  applies the decision
+//
+// Opening a link only shows the confirmation page, so that link previews
+// of chat apps cannot decide requests.
+func (p *Prompter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /reply/{id}/{decision}", p.handleConfirm)
+	mux.HandleFunc("POST /reply/{id}/{decision}", p.handleReply)
+	return mux
+}
+
+var confirmPage = template.Must(template.New("confirm").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>crabhook</title>
+<style>
+  body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 1rem auto; padding: 0 0.75rem; }
+  pre { white-space: pre-wrap; word-break: break-word; background: rgba(127, 127, 127, 0.12); padding: 0.5rem; }
+  button { width: 100%; padding: 0.8rem; font-size: 1.1rem; border: none; border-radius: 0.4rem; color: #fff; }
+  .allow { background: #2b8a3e; } .deny { background: #c92a2a; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<pre>{{.Message}}</pre>
+{{if .Cwd}}<p>{{.Cwd}}</p>{{end}}
+<form method="post">
+  <button class="{{.Decision}}">{{if eq .Decision "allow"}}Approve{{else}}Deny{{end}}</button>
+</form>
+</body>
+</html>
+`))
+
+var resultPage = template.Must(template.New("result").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>crabhook</title>
+</head>
+<body style="font-family: system-ui, sans-serif; text-align: center; margin-top: 3rem">
+<p>{{.}}</p>
+</body>
+</html>
+`))
+
+// check validates the link of r and returns its pending request.
+func (p *Prompter) check(w http.ResponseWriter, r *http.Request) (id, decision string, pr *pending, ok bool) {
+	id, decision = r.PathValue("id"), r.PathValue("decision")
+	if decision != "allow" && decision != "deny" {
+		http.NotFound(w, r)
+		return "", "", nil, false
+	}
+	q := r.URL.Query()
+	if err := p.verify(id, decision, q.Get("exp"), q.Get("sig")); err != nil {
+		writePage(w, http.StatusForbidden, resultPage, err.Error()+".")
+		return "", "", nil, false
+	}
+	pr, ok = p.peek(id)
+	if !ok {
+		writePage(w, http.StatusGone, resultPage, "This request was already answered or withdrawn.")
+		return "", "", nil, false
+	}
+	return id, decision, pr, true
+}
+
+func (p *Prompter) handleConfirm(w http.ResponseWriter, r *http.Request) {
+	_, decision, pr, ok := p.check(w, r)
+	if !ok {
+		return
+	}
+	n := server.NewNotification(pr.req)
+	writePage(w, http.StatusOK, confirmPage, map[string]string{
+		"Title":    n.Title,
+		"Message":  n.Message,
+		"Cwd":      pr.req.GetCwd(),
+		"Decision": decision,
+	})
+}
+
+func (p *Prompter) handleReply(w http.ResponseWriter, r *http.Request) {
+	id, decision, _, ok := p.check(w, r)
+	if !ok {
+		return
+	}
+	pr, ok := p.take(id)
+	if !ok {
+		writePage(w, http.StatusGone, resultPage, "This request was already answered or withdrawn.")
+		return
+	}
+
+	resp := server.BuildPermissionResponse(pr.req, pb.PermissionDecision_PERMISSION_DECISION_ALLOW, "approved via webhook link")
+	text := "Approved."
+	if decision == "deny" {
+		resp = server.BuildPermissionResponse(pr.req, pb.PermissionDecision_PERMISSION_DECISION_DENY, "denied via webhook link")
+		text = "Denied."
+	}
+	resp.DecisionSource = pb.DecisionSource_DECISION_SOURCE_WEBHOOK
+	pr.replyCh <- resp
+	writePage(w, http.StatusOK, resultPage, text)
+}
+
+func writePage(w http.ResponseWriter, status int, t *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	t.Execute(w, data)
+}
@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/ngicks/crabswarm/hook/api/gen/go/permission/v1"
+)
+
+// received is a request captured by the fake webhook receiver.
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// setup starts a fake webhook receiver and the reply endpoint of a
+// Prompter posting to it.
+func setup(t *testing.T, format Format) (*Prompter, <-chan received) {
+	t.Helper()
+	ch := make(chan received, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ch <- received{header: r.Header, body: body}
+	}))
+	t.Cleanup(receiver.Close)
+
+	var handler http.Handler
+	reply := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(reply.Close)
+
+	p, err := New(Config{URL: receiver.URL, Format: format, PublicURL: reply.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler = p.Handler()
+	return p, ch
+}
+
+type promptResult struct {
+	resp *pb.PermissionResponse
+	err  error
+}
+
+func prompt(ctx context.Context, p *Prompter, req *pb.PermissionRequest) <-chan promptResult {
+	ch := make(chan promptResult, 1)
+	go func() {
+		resp, err := p.Prompt(ctx, req)
+		ch <- promptResult{resp, err}
+	}()
+	return ch
+}
+
+func receive(t *testing.T, ch <-chan received) received {
+	t.Helper()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+		return received{}
+	}
+}
+
+func do(t *testing.T, method, link string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, link, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+var testReq = &pb.PermissionRequest{
+	HookEventName: "PreToolUse",
+	ToolName:      "Bash",
+	ToolInputJson: `{"command":"make deploy"}`,
+	SessionId:     "s1",
+	Cwd:           "/work",
+}
+
+func TestPrompter_ApproveLink(t *testing.T) {
+	p, hooks := setup(t, FormatGeneric)
+	result := prompt(context.Background(), p, testReq)
+
+	r := receive(t, hooks)
+	var payload Payload
+	if err := json.Unmarshal(r.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ToolName != "Bash" || payload.Message != "make deploy\nsession s1" || string(payload.ToolInput) != `{"command":"make deploy"}` {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	// Opening the link (or a link preview) only shows a confirmation page.
+	status, body := do(t, http.MethodGet, payload.ApproveURL)
+	if status != http.StatusOK || !strings.Contains(body, "make deploy") || !strings.Contains(body, "Approve") {
+		t.Errorf("GET approve = %d %q", status, body)
+	}
+	select {
+	case <-result:
+		t.Fatal("GET must not decide the request")
+	default:
+	}
+
+	// A tampered link is rejected.
+	tampered := strings.Replace(payload.DenyURL, "/deny?", "/allow?", 1)
+	if status, _ := do(t, http.MethodPost, tampered); status != http.StatusForbidden {
+		t.Errorf("tampered link status = %d", status)
+	}
+
+	if status, _ := do(t, http.MethodPost, payload.ApproveURL); status != http.StatusOK {
+		t.Fatalf("POST approve status = %d", status)
+	}
+	got := <-result
+	if got.err != nil || got.resp.GetHookSpecificOutput().GetPermissionDecision() != pb.PermissionDecision_PERMISSION_DECISION_ALLOW ||
+		got.resp.GetDecisionSource() != pb.DecisionSource_DECISION_SOURCE_WEBHOOK {
+		t.Fatalf("Prompt() = %v, %v", got.resp, got.err)
+	}
+
+	// Links are one-time.
+	if status, _ := do(t, http.MethodPost, payload.DenyURL); status != http.StatusGone {
+		t.Errorf("second use status = %d, want 410", status)
+	}
+}
+
+func TestPrompter_ExpiredAndWithdrawnLinks(t *testing.T) {
+	p, hooks := setup(t, FormatGeneric)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := prompt(ctx, p, testReq)
+	var payload Payload
+	if err := json.Unmarshal(receive(t, hooks).body, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(DefaultLinkTTL + time.Second)
+	if status, _ := do(t, http.MethodPost, payload.DenyURL); status != http.StatusForbidden {
+		t.Errorf("expired link status = %d, want 403", status)
+	}
+
+	now = now.Add(-DefaultLinkTTL)
+	cancel()
+	if got := <-result; got.err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", got.err)
+	}
+	if status, _ := do(t, http.MethodPost, payload.DenyURL); status != http.StatusGone {
+		t.Errorf("withdrawn link status = %d, want 410", status)
+	}
+}
+
+func TestPrompter_Formats(t *testing.T) {
+	t.Run("slack", func(t *testing.T) {
+		p, hooks := setup(t, FormatSlack)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		prompt(ctx, p, testReq)
+
+		var msg struct {
+			Text   string
+			Blocks []struct {
+				Type     string
+				Elements []struct {
+					URL   string
+					Style string
+				}
+			}
+		}
+		if err := json.Unmarshal(receive(t, hooks).body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Text != "crabhook: Bash is waiting for approval" || len(msg.Blocks) != 2 {
+			t.Fatalf("message = %+v", msg)
+		}
+		buttons := msg.Blocks[1].Elements
+		if len(buttons) != 2 || !strings.Contains(buttons[0].URL, "/allow?") || buttons[1].Style != "danger" {
+			t.Errorf("buttons = %+v", buttons)
+		}
+	})
+
+	t.Run("ntfy", func(t *testing.T) {
+		p, hooks := setup(t, FormatNtfy)
+		result := prompt(context.Background(), p, testReq)
+
+		r := receive(t, hooks)
+		if string(r.body) != "make deploy\nsession s1" || r.header.Get("Title") == "" {
+			t.Errorf("ntfy message = %q, headers %v", r.body, r.header)
+		}
+		// The app posts to the link of the chosen action.
+		actions := strings.Split(r.header.Get("Actions"), "; ")
+		if len(actions) != 2 {
+			t.Fatalf("actions = %q", actions)
+		}
+		link := strings.Split(actions[1], ", ")[2]
+		if _, err := url.Parse(link); err != nil {
+			t.Fatal(err)
+		}
+		if status, _ := do(t, http.MethodPost, link); status != http.StatusOK {
+			t.Fatalf("deny status = %d", status)
+		}
+		if got := <-result; got.resp.GetHookSpecificOutput().GetPermissionDecision() != pb.PermissionDecision_PERMISSION_DECISION_DENY {
+			t.Errorf("decision = %v, want DENY", got.resp)
+		}
+	})
+
+	t.Run("gotify", func(t *testing.T) {
+		p, hooks := setup(t, FormatGotify)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		prompt(ctx, p, testReq)
+
+		var msg struct {
+			Title    string
+			Message  string
+			Priority int
+		}
+		if err := json.Unmarshal(receive(t, hooks).body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(msg.Message, "[Approve](") || !strings.Contains(msg.Message, "[Deny](") || msg.Priority == 0 {
+			t.Errorf("message = %+v", msg)
+		}
+	})
+}
+
+func TestPrompter_NoApproveLink(t *testing.T) {
+	for _, req := range []*pb.PermissionRequest{
+		{HookEventName: "PreToolUse", ToolName: "AskUserQuestion", ToolInputJson: `{"questions":[{"question":"Which?","header":"Q","options":[{"label":"A"}],"multiSelect":false}]}`},
+		{HookEventName: "PreToolUse", ToolName: "ExitPlanMode", ToolInputJson: `{"plan":"1. test"}`},
+	} {
+		t.Run(req.GetToolName(), func(t *testing.T) {
+			p, hooks := setup(t, FormatGeneric)
+			result := prompt(context.Background(), p, req)
+
+			var payload Payload
+			if err := json.Unmarshal(receive(t, hooks).body, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.ApproveURL != "" || payload.DenyURL == "" || !strings.Contains(payload.Message, answerElsewhere) {
+				t.Errorf("payload = %+v, want only a deny link", payload)
+			}
+			forged := strings.Replace(payload.DenyURL, "/deny?", "/allow?", 1)
+			if status, _ := do(t, http.MethodPost, forged); status != http.StatusForbidden {
+				t.Errorf("forged approve link status = %d, want 403", status)
+			}
+
+			if status, _ := do(t, http.MethodPost, payload.DenyURL); status != http.StatusOK {
+				t.Fatalf("deny status = %d", status)
+			}
+			if got := <-result; got.resp.GetHookSpecificOutput().GetPermissionDecision() != pb.PermissionDecision_PERMISSION_DECISION_DENY {
+				t.Errorf("decision = %v, want DENY", got.resp)
+			}
+		})
+	}
+
+	t.Run("slack", func(t *testing.T) {
+		p, hooks := setup(t, FormatSlack)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		prompt(ctx, p, &pb.PermissionRequest{HookEventName: "PreToolUse", ToolName: "ExitPlanMode", ToolInputJson: `{"plan":"p"}`})
+
+		var msg struct {
+			Blocks []struct {
+				Elements []struct{ URL string }
+			}
+		}
+		if err := json.Unmarshal(receive(t, hooks).body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if buttons := msg.Blocks[1].Elements; len(buttons) != 1 || !strings.Contains(buttons[0].URL, "/deny?") {
+			t.Errorf("buttons = %+v, want only Deny", buttons)
+		}
+	})
+}
+
+func TestPrompter_WebhookFailure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such topic", http.StatusNotFound)
+	}))
+	defer receiver.Close()
+
+	p, err := New(Config{URL: receiver.URL, PublicURL: "http://localhost:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Prompt(context.Background(), testReq)
+	if err == nil || !strings.Contains(err.Error(), "no such topic") {
+		t.Errorf("err = %v, want webhook error", err)
+	}
+	if len(p.pending) != 0 {
+		t.Error("failed request is still pending")
+	}
+
+	if _, err := New(Config{URL: receiver.URL, PublicURL: "http://x", Format: "teams"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
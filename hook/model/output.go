@@ -17,6 +17,9 @@ const (
 	PermissionAsk PermissionDecision = "ask"
 )
 
+// DecisionBlock is the HookOutput.Decision blocking the operation.
+const DecisionBlock = "block"
+
 // HookOutput represents the JSON output sent to Claude Code via stdout.
 // This matches the official Claude Agent SDK hooks protocol.
 // See https://platform.claude.com/docs/en/agent-sdk/hooks for the official protocol.
@@ -33,6 +36,12 @@ type HookOutput struct {
 	// SystemMessage is a message injected into the conversation for Claude to see.
 	SystemMessage string `json:"systemMessage,omitempty"`
 
+	// Decision is "block" to block the operation (PostToolUse, UserPromptSubmit, Stop, SubagentStop).
+	Decision string `json:"decision,omitempty"`
+
+	// Reason explains a "block" Decision to Claude.
+	Reason string `json:"reason,omitempty"`
+
 	// HookSpecificOutput contains hook-specific output data.
 	HookSpecificOutput *HookSpecificOutput `json:"hookSpecificOutput,omitempty"`
 }
@@ -57,6 +66,17 @@ type HookSpecificOutput struct {
 	AdditionalContext string `json:"additionalContext,omitempty"`
 }
 
+// AsyncOutput is written to stdout by a hook that continues in the background
+// instead of making Claude Code wait for it.
+// See https://platform.claude.com/docs/en/agent-sdk/typescript#async-hook-json-output
+type AsyncOutput struct {
+	// Async is always true.
+	Async bool `json:"async"`
+
+	// AsyncTimeout is how long, in milliseconds, the hook may keep running.
+	AsyncTimeout int `json:"asyncTimeout,omitempty"`
+}
+
 // EmptyOutput creates an empty HookOutput that allows the operation without changes.
 func EmptyOutput() HookOutput {
 	return HookOutput{}
@@ -109,6 +129,16 @@ func Ask(event HookEventName) HookOutput {
 	}
 }
 
+// Block creates a HookOutput that blocks the operation with a reason fed to
+// Claude (PostToolUse, UserPromptSubmit, Stop, SubagentStop). Blocking Stop
+// makes Claude keep working.
+func Block(reason string) HookOutput {
+	return HookOutput{
+		Decision: DecisionBlock,
+		Reason:   reason,
+	}
+}
+
 // Stop creates a HookOutput that stops the agent.
 func Stop(reason string) HookOutput {
 	f := false
@@ -69,6 +69,16 @@ func TestStopHelper(t *testing.T) {
 	}
 }
 
+func TestBlockHelper(t *testing.T) {
+	output := Block("tests are failing")
+	if output.Decision != DecisionBlock {
+		t.Errorf("Expected decision %q, got %q", DecisionBlock, output.Decision)
+	}
+	if output.Reason != "tests are failing" {
+		t.Errorf("Expected reason 'tests are failing', got '%s'", output.Reason)
+	}
+}
+
 func TestAllowWithUpdatedInput(t *testing.T) {
 	input := &BashInput{
 		Command:     "echo sanitized",
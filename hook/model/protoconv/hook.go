@@ -0,0 +1,312 @@
+// Package protoconv converts between the hand-written hook I/O types of
+// package model and their sdk_types protobuf counterparts.
+//
+// Fields without a counterpart on the other side (e.g. HookInput.MessageID,
+// or fields of an event the proto message for that event does not carry) are
+// dropped.
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ngicks/crabswarm/hook/model"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var (
+	sessionStartSources = map[model.SessionStartReason]sdkpb.SessionStartSource{
+		model.SessionStartReasonStartup: sdkpb.SessionStartSource_SESSION_START_SOURCE_STARTUP,
+		model.SessionStartReasonResume:  sdkpb.SessionStartSource_SESSION_START_SOURCE_RESUME,
+		model.SessionStartReasonClear:   sdkpb.SessionStartSource_SESSION_START_SOURCE_CLEAR,
+		model.SessionStartReasonCompact: sdkpb.SessionStartSource_SESSION_START_SOURCE_COMPACT,
+	}
+	compactTriggers = map[model.CompactTrigger]sdkpb.PreCompactTrigger{
+		model.CompactTriggerManual: sdkpb.PreCompactTrigger_PRE_COMPACT_TRIGGER_MANUAL,
+		model.CompactTriggerAuto:   sdkpb.PreCompactTrigger_PRE_COMPACT_TRIGGER_AUTO,
+	}
+)
+
+// HookInputToProto converts a hook input to the proto message of its event.
+func HookInputToProto(in *model.HookInput) (*sdkpb.HookInput, error) {
+	toolInput, err := ToolInputToProto(in.ToolName, in.ToolInput)
+	if err != nil {
+		return nil, err
+	}
+	mode := optString(in.PermissionMode)
+
+	out := &sdkpb.HookInput{}
+	switch in.HookEventName {
+	case model.HookEventPreToolUse:
+		out.Input = &sdkpb.HookInput_PreToolUse{PreToolUse: &sdkpb.PreToolUseHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			ToolName:       string(in.ToolName),
+			ToolInput:      toolInput,
+		}}
+	case model.HookEventPostToolUse:
+		response, err := toolResponseToProto(in.ToolName, in.ToolResponse)
+		if err != nil {
+			return nil, err
+		}
+		out.Input = &sdkpb.HookInput_PostToolUse{PostToolUse: &sdkpb.PostToolUseHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			ToolName:       string(in.ToolName),
+			ToolInput:      toolInput,
+			ToolResponse:   response,
+		}}
+	case model.HookEventPostToolUseFailure:
+		out.Input = &sdkpb.HookInput_PostToolUseFailure{PostToolUseFailure: &sdkpb.PostToolUseFailureHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			ToolName:       string(in.ToolName),
+			ToolInput:      toolInput,
+			Error:          in.Error,
+			IsInterrupt:    optBool(in.IsInterrupt),
+		}}
+	case model.HookEventPermissionRequest:
+		suggestions, err := PermissionUpdatesToProto(in.PermissionSuggestions)
+		if err != nil {
+			return nil, err
+		}
+		out.Input = &sdkpb.HookInput_PermissionRequest{PermissionRequest: &sdkpb.PermissionRequestHookInput{
+			SessionId:             in.SessionID,
+			TranscriptPath:        in.TranscriptPath,
+			Cwd:                   in.Cwd,
+			PermissionMode:        mode,
+			ToolName:              string(in.ToolName),
+			ToolInput:             toolInput,
+			PermissionSuggestions: suggestions,
+		}}
+	case model.HookEventNotification:
+		out.Input = &sdkpb.HookInput_Notification{Notification: &sdkpb.NotificationHookInput{
+			SessionId:        in.SessionID,
+			TranscriptPath:   in.TranscriptPath,
+			Cwd:              in.Cwd,
+			PermissionMode:   mode,
+			Message:          in.Message,
+			Title:            optString(in.Title),
+			NotificationType: string(in.NotificationType),
+		}}
+	case model.HookEventUserPromptSubmit:
+		out.Input = &sdkpb.HookInput_UserPromptSubmit{UserPromptSubmit: &sdkpb.UserPromptSubmitHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			Prompt:         in.Prompt,
+		}}
+	case model.HookEventSessionStart:
+		source, ok := sessionStartSources[in.Source]
+		if !ok && in.Source != "" {
+			return nil, fmt.Errorf("unknown SessionStart source %q", in.Source)
+		}
+		out.Input = &sdkpb.HookInput_SessionStart{SessionStart: &sdkpb.SessionStartHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			Source:         source,
+		}}
+	case model.HookEventSessionEnd:
+		out.Input = &sdkpb.HookInput_SessionEnd{SessionEnd: &sdkpb.SessionEndHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			Reason:         string(in.Reason),
+		}}
+	case model.HookEventStop:
+		out.Input = &sdkpb.HookInput_Stop{Stop: &sdkpb.StopHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			StopHookActive: in.StopHookActive,
+		}}
+	case model.HookEventSubagentStart:
+		out.Input = &sdkpb.HookInput_SubagentStart{SubagentStart: &sdkpb.SubagentStartHookInput{
+			SessionId:      in.SessionID,
+			TranscriptPath: in.TranscriptPath,
+			Cwd:            in.Cwd,
+			PermissionMode: mode,
+			AgentId:        in.AgentID,
+			AgentType:      in.AgentType,
+		}}
+	case model.HookEventSubagentStop:
+		out.Input = &sdkpb.HookInput_SubagentStop{SubagentStop: &sdkpb.SubagentStopHookInput{
+			SessionId:           in.SessionID,
+			TranscriptPath:      in.TranscriptPath,
+			Cwd:                 in.Cwd,
+			PermissionMode:      mode,
+			StopHookActive:      in.StopHookActive,
+			AgentId:             in.AgentID,
+			AgentType:           in.AgentType,
+			AgentTranscriptPath: in.AgentTranscriptPath,
+		}}
+	case model.HookEventPreCompact:
+		trigger, ok := compactTriggers[in.Trigger]
+		if !ok && in.Trigger != "" {
+			return nil, fmt.Errorf("unknown PreCompact trigger %q", in.Trigger)
+		}
+		out.Input = &sdkpb.HookInput_PreCompact{PreCompact: &sdkpb.PreCompactHookInput{
+			SessionId:          in.SessionID,
+			TranscriptPath:     in.TranscriptPath,
+			Cwd:                in.Cwd,
+			PermissionMode:     mode,
+			Trigger:            trigger,
+			CustomInstructions: optString(in.CustomInstructions),
+		}}
+	default:
+		return nil, fmt.Errorf("unknown hook event %q", in.HookEventName)
+	}
+	return out, nil
+}
+
+// hookInputBase holds the fields common to all hook input messages.
+type hookInputBase interface {
+	GetSessionId() string
+	GetTranscriptPath() string
+	GetCwd() string
+	GetPermissionMode() string
+}
+
+func newHookInput(event model.HookEventName, base hookInputBase) *model.HookInput {
+	return &model.HookInput{
+		HookEventName:  event,
+		SessionID:      base.GetSessionId(),
+		TranscriptPath: base.GetTranscriptPath(),
+		Cwd:            base.GetCwd(),
+		PermissionMode: base.GetPermissionMode(),
+	}
+}
+
+// HookInputFromProto converts a hook input message back to a HookInput.
+func HookInputFromProto(in *sdkpb.HookInput) (*model.HookInput, error) {
+	var (
+		out       *model.HookInput
+		toolInput *sdkpb.ToolInput
+	)
+	switch x := in.GetInput().(type) {
+	case *sdkpb.HookInput_PreToolUse:
+		out = newHookInput(model.HookEventPreToolUse, x.PreToolUse)
+		out.ToolName = model.ToolName(x.PreToolUse.GetToolName())
+		toolInput = x.PreToolUse.GetToolInput()
+	case *sdkpb.HookInput_PostToolUse:
+		out = newHookInput(model.HookEventPostToolUse, x.PostToolUse)
+		out.ToolName = model.ToolName(x.PostToolUse.GetToolName())
+		toolInput = x.PostToolUse.GetToolInput()
+		response, err := toolResponseFromProto(x.PostToolUse.GetToolResponse())
+		if err != nil {
+			return nil, err
+		}
+		out.ToolResponse = response
+	case *sdkpb.HookInput_PostToolUseFailure:
+		out = newHookInput(model.HookEventPostToolUseFailure, x.PostToolUseFailure)
+		out.ToolName = model.ToolName(x.PostToolUseFailure.GetToolName())
+		toolInput = x.PostToolUseFailure.GetToolInput()
+		out.Error = x.PostToolUseFailure.GetError()
+		out.IsInterrupt = x.PostToolUseFailure.GetIsInterrupt()
+	case *sdkpb.HookInput_PermissionRequest:
+		out = newHookInput(model.HookEventPermissionRequest, x.PermissionRequest)
+		out.ToolName = model.ToolName(x.PermissionRequest.GetToolName())
+		toolInput = x.PermissionRequest.GetToolInput()
+		suggestions, err := PermissionUpdatesFromProto(x.PermissionRequest.GetPermissionSuggestions())
+		if err != nil {
+			return nil, err
+		}
+		out.PermissionSuggestions = suggestions
+	case *sdkpb.HookInput_Notification:
+		out = newHookInput(model.HookEventNotification, x.Notification)
+		out.Message = x.Notification.GetMessage()
+		out.Title = x.Notification.GetTitle()
+		out.NotificationType = model.NotificationType(x.Notification.GetNotificationType())
+	case *sdkpb.HookInput_UserPromptSubmit:
+		out = newHookInput(model.HookEventUserPromptSubmit, x.UserPromptSubmit)
+		out.Prompt = x.UserPromptSubmit.GetPrompt()
+	case *sdkpb.HookInput_SessionStart:
+		out = newHookInput(model.HookEventSessionStart, x.SessionStart)
+		out.Source = keyOf(sessionStartSources, x.SessionStart.GetSource())
+	case *sdkpb.HookInput_SessionEnd:
+		out = newHookInput(model.HookEventSessionEnd, x.SessionEnd)
+		out.Reason = model.SessionEndReason(x.SessionEnd.GetReason())
+	case *sdkpb.HookInput_Stop:
+		out = newHookInput(model.HookEventStop, x.Stop)
+		out.StopHookActive = x.Stop.GetStopHookActive()
+	case *sdkpb.HookInput_SubagentStart:
+		out = newHookInput(model.HookEventSubagentStart, x.SubagentStart)
+		out.AgentID = x.SubagentStart.GetAgentId()
+		out.AgentType = x.SubagentStart.GetAgentType()
+	case *sdkpb.HookInput_SubagentStop:
+		out = newHookInput(model.HookEventSubagentStop, x.SubagentStop)
+		out.StopHookActive = x.SubagentStop.GetStopHookActive()
+		out.AgentID = x.SubagentStop.GetAgentId()
+		out.AgentType = x.SubagentStop.GetAgentType()
+		out.AgentTranscriptPath = x.SubagentStop.GetAgentTranscriptPath()
+	case *sdkpb.HookInput_PreCompact:
+		out = newHookInput(model.HookEventPreCompact, x.PreCompact)
+		out.Trigger = keyOf(compactTriggers, x.PreCompact.GetTrigger())
+		out.CustomInstructions = x.PreCompact.GetCustomInstructions()
+	default:
+		return nil, fmt.Errorf("unsupported hook input %T", x)
+	}
+
+	data, err := ToolInputFromProto(toolInput)
+	if err != nil {
+		return nil, err
+	}
+	out.ToolInput = data
+	return out, nil
+}
+
+// toolResponseToProto carries a tool response, which has no typed message
+// matching what Claude Code sends, as the mcp_tool Struct for MCP tools and
+// the other Struct for the rest.
+func toolResponseToProto(tool model.ToolName, response json.RawMessage) (*sdkpb.ToolOutput, error) {
+	if len(response) == 0 {
+		return nil, nil
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(response, &obj); err != nil {
+		return nil, fmt.Errorf("tool response is not a JSON object: %w", err)
+	}
+	if obj == nil {
+		return nil, nil
+	}
+	s, err := structpb.NewStruct(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert tool response: %w", err)
+	}
+	if tool.IsMCP() {
+		return &sdkpb.ToolOutput{Output: &sdkpb.ToolOutput_McpTool{McpTool: s}}, nil
+	}
+	return &sdkpb.ToolOutput{Output: &sdkpb.ToolOutput_Other{Other: s}}, nil
+}
+
+func toolResponseFromProto(response *sdkpb.ToolOutput) (json.RawMessage, error) {
+	if response == nil {
+		return nil, nil
+	}
+	var s *structpb.Struct
+	switch x := response.GetOutput().(type) {
+	case *sdkpb.ToolOutput_McpTool:
+		s = x.McpTool
+	case *sdkpb.ToolOutput_Other:
+		s = x.Other
+	default:
+		return nil, fmt.Errorf("unsupported tool response %T", x)
+	}
+	data, err := json.Marshal(s.AsMap())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool response: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,238 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ngicks/crabswarm/hook/model"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// assertSameJSON fails if want and got do not encode to the same JSON value.
+func assertSameJSON(t *testing.T, want, got any) {
+	t.Helper()
+	norm := func(v any) any {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out any
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	if w, g := norm(want), norm(got); !reflect.DeepEqual(w, g) {
+		wj, _ := json.Marshal(w)
+		gj, _ := json.Marshal(g)
+		t.Errorf("round trip mismatch\nwant: %s\n got: %s", wj, gj)
+	}
+}
+
+// Hook inputs as sent by Claude Code.
+var hookPayloads = map[string]string{
+	"PreToolUse": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/00893aaf-19fa-41d2-8238-13269b9b3ca0.jsonl",
+		"cwd": "/home/user/app",
+		"permission_mode": "default",
+		"hook_event_name": "PreToolUse",
+		"tool_name": "Bash",
+		"tool_input": {"command": "npm test", "description": "Run the test suite", "timeout": 120000}
+	}`,
+	"PostToolUse": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"permission_mode": "acceptEdits",
+		"hook_event_name": "PostToolUse",
+		"tool_name": "Write",
+		"tool_input": {"file_path": "/home/user/app/notes.txt", "content": "hello\n"},
+		"tool_response": {"filePath": "/home/user/app/notes.txt", "success": true}
+	}`,
+	"PostToolUseFailure": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "PostToolUseFailure",
+		"tool_name": "Bash",
+		"tool_input": {"command": "make lint"},
+		"error": "Command exited with non-zero status code 2",
+		"is_interrupt": true
+	}`,
+	"PermissionRequest": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"permission_mode": "default",
+		"hook_event_name": "PermissionRequest",
+		"tool_name": "Edit",
+		"tool_input": {"file_path": "/home/user/app/main.go", "old_string": "foo()", "new_string": "bar()", "replace_all": true},
+		"permission_suggestions": [
+			{"type": "addRules", "rules": [{"toolName": "Edit"}], "behavior": "allow", "destination": "session"},
+			{"type": "setMode", "mode": "acceptEdits", "destination": "session"},
+			{"type": "addDirectories", "directories": ["/home/user/lib"], "destination": "localSettings"}
+		]
+	}`,
+	"PreToolUse MCP": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "PreToolUse",
+		"tool_name": "mcp__memory__create_entities",
+		"tool_input": {"entities": [{"name": "Alice", "entityType": "person", "observations": ["likes Go", "uses vim"]}], "limit": 3}
+	}`,
+	"Notification": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "Notification",
+		"message": "Claude needs your permission to use Bash",
+		"title": "Permission needed",
+		"notification_type": "permission_prompt"
+	}`,
+	"UserPromptSubmit": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"permission_mode": "plan",
+		"hook_event_name": "UserPromptSubmit",
+		"prompt": "Write a function to calculate the factorial of a number"
+	}`,
+	"SessionStart": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "SessionStart",
+		"source": "resume"
+	}`,
+	"SessionEnd": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "SessionEnd",
+		"reason": "logout"
+	}`,
+	"Stop": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"permission_mode": "default",
+		"hook_event_name": "Stop",
+		"stop_hook_active": true
+	}`,
+	"SubagentStart": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "SubagentStart",
+		"agent_id": "agent-def456",
+		"agent_type": "Explore"
+	}`,
+	"SubagentStop": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "SubagentStop",
+		"stop_hook_active": false,
+		"agent_id": "agent-def456",
+		"agent_type": "Explore",
+		"agent_transcript_path": "/home/user/.claude/projects/app/abc123/subagents/agent-def456.jsonl"
+	}`,
+	"PreCompact": `{
+		"session_id": "abc123",
+		"transcript_path": "/home/user/.claude/projects/app/abc123.jsonl",
+		"cwd": "/home/user/app",
+		"hook_event_name": "PreCompact",
+		"trigger": "manual",
+		"custom_instructions": "Keep the API design discussion"
+	}`,
+}
+
+func TestHookInput_RoundTrip(t *testing.T) {
+	for name, payload := range hookPayloads {
+		t.Run(name, func(t *testing.T) {
+			var in model.HookInput
+			if err := json.Unmarshal([]byte(payload), &in); err != nil {
+				t.Fatal(err)
+			}
+			p, err := HookInputToProto(&in)
+			if err != nil {
+				t.Fatalf("HookInputToProto() error: %v", err)
+			}
+
+			// The message must survive the wire.
+			data, err := protojson.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded sdkpb.HookInput
+			if err := protojson.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := HookInputFromProto(&decoded)
+			if err != nil {
+				t.Fatalf("HookInputFromProto() error: %v", err)
+			}
+			assertSameJSON(t, in, got)
+		})
+	}
+}
+
+func TestHookInputToProto_TypedToolInput(t *testing.T) {
+	var in model.HookInput
+	if err := json.Unmarshal([]byte(hookPayloads["PreToolUse"]), &in); err != nil {
+		t.Fatal(err)
+	}
+	p, err := HookInputToProto(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pre := p.GetPreToolUse()
+	if pre.GetPermissionMode() != "default" || pre.GetToolName() != "Bash" {
+		t.Errorf("PreToolUse = %v", pre)
+	}
+	bash := pre.GetToolInput().GetBash()
+	if bash.GetCommand() != "npm test" || bash.GetTimeout() != 120000 {
+		t.Errorf("tool input = %v, want typed Bash input", pre.GetToolInput())
+	}
+}
+
+func TestHookInputToProto_ToolResponse(t *testing.T) {
+	for tool, want := range map[model.ToolName]string{
+		"Write":                     "*sdk_typesv1.ToolOutput_Other",
+		"mcp__github__create_issue": "*sdk_typesv1.ToolOutput_McpTool",
+	} {
+		in := &model.HookInput{
+			HookEventName: model.HookEventPostToolUse,
+			ToolName:      tool,
+			ToolResponse:  json.RawMessage(`{"success":true}`),
+		}
+		p, err := HookInputToProto(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprintf("%T", p.GetPostToolUse().GetToolResponse().GetOutput()); got != want {
+			t.Errorf("%s: tool response variant = %s, want %s", tool, got, want)
+		}
+	}
+}
+
+func TestHookInputToProto_Errors(t *testing.T) {
+	for name, in := range map[string]model.HookInput{
+		"unknown event":     {HookEventName: "Teleport"},
+		"unknown source":    {HookEventName: model.HookEventSessionStart, Source: "reboot"},
+		"non-object input":  {HookEventName: model.HookEventPreToolUse, ToolName: "Bash", ToolInput: json.RawMessage(`"ls"`)},
+		"unknown update":    {HookEventName: model.HookEventPermissionRequest, PermissionSuggestions: json.RawMessage(`[{"type":"teleport"}]`)},
+		"unknown behavior":  {HookEventName: model.HookEventPermissionRequest, PermissionSuggestions: json.RawMessage(`[{"type":"addRules","behavior":"maybe"}]`)},
+		"non-object output": {HookEventName: model.HookEventPostToolUse, ToolResponse: json.RawMessage(`[1]`)},
+	} {
+		if _, err := HookInputToProto(&in); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package protoconv
+
+import (
+	"fmt"
+
+	"github.com/ngicks/crabswarm/hook/model"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+)
+
+// HookOutputToProto converts a hook output. tool is the tool of the hook
+// input being answered and selects the typed message of UpdatedInput.
+//
+// PermissionRequest outputs use the PreToolUse fields and convert back as
+// PreToolUse ones. Hook-specific output of other events without a proto
+// counterpart is an error.
+func HookOutputToProto(out *model.HookOutput, tool model.ToolName) (*sdkpb.SyncHookJSONOutput, error) {
+	p := &sdkpb.SyncHookJSONOutput{
+		Continue:       out.Continue,
+		SuppressOutput: optBool(out.SuppressOutput),
+		StopReason:     optString(out.StopReason),
+		Decision:       optString(out.Decision),
+		SystemMessage:  optString(out.SystemMessage),
+		Reason:         optString(out.Reason),
+	}
+
+	hso := out.HookSpecificOutput
+	if hso == nil {
+		return p, nil
+	}
+	context := optString(hso.AdditionalContext)
+	switch hso.HookEventName {
+	case model.HookEventPreToolUse, model.HookEventPermissionRequest:
+		updated, err := ToolInputToProto(tool, hso.UpdatedInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert updated input: %w", err)
+		}
+		p.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_PreToolUse{
+			PreToolUse: &sdkpb.PreToolUseHookSpecificOutput{
+				PermissionDecision:       optString(string(hso.PermissionDecision)),
+				PermissionDecisionReason: optString(hso.PermissionDecisionReason),
+				UpdatedInput:             updated,
+				AdditionalContext:        context,
+			},
+		}}
+	case model.HookEventUserPromptSubmit:
+		p.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_UserPromptSubmit{
+			UserPromptSubmit: &sdkpb.UserPromptSubmitHookSpecificOutput{AdditionalContext: context},
+		}}
+	case model.HookEventSessionStart:
+		p.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_SessionStart{
+			SessionStart: &sdkpb.SessionStartHookSpecificOutput{AdditionalContext: context},
+		}}
+	case model.HookEventPostToolUse:
+		p.HookSpecificOutput = &sdkpb.HookSpecificOutput{Output: &sdkpb.HookSpecificOutput_PostToolUse{
+			PostToolUse: &sdkpb.PostToolUseHookSpecificOutput{AdditionalContext: context},
+		}}
+	default:
+		return nil, fmt.Errorf("hook-specific output for %q has no proto counterpart", hso.HookEventName)
+	}
+	return p, nil
+}
+
+// HookOutputFromProto converts a hook output message back to a HookOutput.
+func HookOutputFromProto(p *sdkpb.SyncHookJSONOutput) (*model.HookOutput, error) {
+	out := &model.HookOutput{
+		Continue:       p.Continue,
+		StopReason:     p.GetStopReason(),
+		SuppressOutput: p.GetSuppressOutput(),
+		SystemMessage:  p.GetSystemMessage(),
+		Decision:       p.GetDecision(),
+		Reason:         p.GetReason(),
+	}
+
+	switch x := p.GetHookSpecificOutput().GetOutput().(type) {
+	case nil:
+	case *sdkpb.HookSpecificOutput_PreToolUse:
+		updated, err := ToolInputFromProto(x.PreToolUse.GetUpdatedInput())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert updated input: %w", err)
+		}
+		out.HookSpecificOutput = &model.HookSpecificOutput{
+			HookEventName:            model.HookEventPreToolUse,
+			PermissionDecision:       model.PermissionDecision(x.PreToolUse.GetPermissionDecision()),
+			PermissionDecisionReason: x.PreToolUse.GetPermissionDecisionReason(),
+			UpdatedInput:             updated,
+			AdditionalContext:        x.PreToolUse.GetAdditionalContext(),
+		}
+	case *sdkpb.HookSpecificOutput_UserPromptSubmit:
+		out.HookSpecificOutput = &model.HookSpecificOutput{
+			HookEventName:     model.HookEventUserPromptSubmit,
+			AdditionalContext: x.UserPromptSubmit.GetAdditionalContext(),
+		}
+	case *sdkpb.HookSpecificOutput_SessionStart:
+		out.HookSpecificOutput = &model.HookSpecificOutput{
+			HookEventName:     model.HookEventSessionStart,
+			AdditionalContext: x.SessionStart.GetAdditionalContext(),
+		}
+	case *sdkpb.HookSpecificOutput_PostToolUse:
+		out.HookSpecificOutput = &model.HookSpecificOutput{
+			HookEventName:     model.HookEventPostToolUse,
+			AdditionalContext: x.PostToolUse.GetAdditionalContext(),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported hook-specific output %T", x)
+	}
+	return out, nil
+}
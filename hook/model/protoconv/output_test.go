@@ -0,0 +1,60 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+func TestHookOutput_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   model.ToolName
+		output string
+	}{
+		{"empty", "", `{}`},
+		{"stop", "", `{"continue":false,"stopReason":"Build failed","suppressOutput":true,"systemMessage":"see logs"}`},
+		{"block", "", `{"decision":"block","reason":"Tests must pass first"}`},
+		{"deny", "Bash", `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"deny","permissionDecisionReason":"rm is not allowed"}}`},
+		{"updated input", "Bash", `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"allow","updatedInput":{"command":"ls -la","description":"List files"},"additionalContext":"listing"}}`},
+		{"updated mcp input", "mcp__db__query", `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"allow","updatedInput":{"sql":"SELECT 1","readonly":true}}}`},
+		{"prompt context", "", `{"hookSpecificOutput":{"hookEventName":"UserPromptSubmit","additionalContext":"Current branch: main"}}`},
+		{"session context", "", `{"hookSpecificOutput":{"hookEventName":"SessionStart","additionalContext":"Open issues: 3"}}`},
+		{"post tool context", "Write", `{"decision":"block","reason":"lint failed","hookSpecificOutput":{"hookEventName":"PostToolUse","additionalContext":"2 lint errors"}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out model.HookOutput
+			if err := json.Unmarshal([]byte(tc.output), &out); err != nil {
+				t.Fatal(err)
+			}
+			p, err := HookOutputToProto(&out, tc.tool)
+			if err != nil {
+				t.Fatalf("HookOutputToProto() error: %v", err)
+			}
+			got, err := HookOutputFromProto(p)
+			if err != nil {
+				t.Fatalf("HookOutputFromProto() error: %v", err)
+			}
+			assertSameJSON(t, json.RawMessage(tc.output), got)
+		})
+	}
+}
+
+func TestHookOutputToProto_PermissionRequest(t *testing.T) {
+	out := model.AllowWithReason(model.HookEventPermissionRequest, "ok")
+	p, err := HookOutputToProto(&out, "Bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pre := p.GetHookSpecificOutput().GetPreToolUse()
+	if pre.GetPermissionDecision() != "allow" || pre.GetPermissionDecisionReason() != "ok" {
+		t.Errorf("PreToolUse output = %v", pre)
+	}
+
+	out = model.HookOutput{HookSpecificOutput: &model.HookSpecificOutput{HookEventName: model.HookEventStop}}
+	if _, err := HookOutputToProto(&out, ""); err == nil {
+		t.Error("expected error for Stop hook-specific output")
+	}
+}
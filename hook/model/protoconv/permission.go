@@ -0,0 +1,186 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+)
+
+// permissionUpdate is the JSON form of a permission update, as found in
+// HookInput.PermissionSuggestions.
+type permissionUpdate struct {
+	Type        string           `json:"type"`
+	Rules       []permissionRule `json:"rules,omitempty"`
+	Behavior    string           `json:"behavior,omitempty"`
+	Mode        string           `json:"mode,omitempty"`
+	Directories []string         `json:"directories,omitempty"`
+	Destination string           `json:"destination,omitempty"`
+}
+
+type permissionRule struct {
+	ToolName    string  `json:"toolName"`
+	RuleContent *string `json:"ruleContent,omitempty"`
+}
+
+var (
+	permissionBehaviors = map[string]sdkpb.PermissionBehavior{
+		"allow": sdkpb.PermissionBehavior_PERMISSION_BEHAVIOR_ALLOW,
+		"deny":  sdkpb.PermissionBehavior_PERMISSION_BEHAVIOR_DENY,
+		"ask":   sdkpb.PermissionBehavior_PERMISSION_BEHAVIOR_ASK,
+	}
+	permissionDestinations = map[string]sdkpb.PermissionUpdateDestination{
+		"userSettings":    sdkpb.PermissionUpdateDestination_PERMISSION_UPDATE_DESTINATION_USER_SETTINGS,
+		"projectSettings": sdkpb.PermissionUpdateDestination_PERMISSION_UPDATE_DESTINATION_PROJECT_SETTINGS,
+		"localSettings":   sdkpb.PermissionUpdateDestination_PERMISSION_UPDATE_DESTINATION_LOCAL_SETTINGS,
+		"session":         sdkpb.PermissionUpdateDestination_PERMISSION_UPDATE_DESTINATION_SESSION,
+	}
+	permissionModes = map[string]sdkpb.PermissionMode{
+		"default":           sdkpb.PermissionMode_PERMISSION_MODE_DEFAULT,
+		"acceptEdits":       sdkpb.PermissionMode_PERMISSION_MODE_ACCEPT_EDITS,
+		"bypassPermissions": sdkpb.PermissionMode_PERMISSION_MODE_BYPASS_PERMISSIONS,
+		"plan":              sdkpb.PermissionMode_PERMISSION_MODE_PLAN,
+	}
+)
+
+// lookup returns m[key], treating an empty key as the zero value.
+func lookup[V any](m map[string]V, kind, key string) (V, error) {
+	v, ok := m[key]
+	if !ok && key != "" {
+		return v, fmt.Errorf("unknown permission %s %q", kind, key)
+	}
+	return v, nil
+}
+
+// PermissionUpdatesToProto converts the permission_suggestions JSON of a
+// PermissionRequest hook input.
+func PermissionUpdatesToProto(data json.RawMessage) ([]*sdkpb.PermissionUpdate, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var updates []permissionUpdate
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return nil, fmt.Errorf("failed to parse permission suggestions: %w", err)
+	}
+
+	out := make([]*sdkpb.PermissionUpdate, 0, len(updates))
+	for _, u := range updates {
+		behavior, err := lookup(permissionBehaviors, "behavior", u.Behavior)
+		if err != nil {
+			return nil, err
+		}
+		destination, err := lookup(permissionDestinations, "destination", u.Destination)
+		if err != nil {
+			return nil, err
+		}
+		var rules []*sdkpb.PermissionRuleValue
+		for _, r := range u.Rules {
+			rules = append(rules, &sdkpb.PermissionRuleValue{ToolName: r.ToolName, RuleContent: r.RuleContent})
+		}
+
+		pu := &sdkpb.PermissionUpdate{}
+		switch u.Type {
+		case "addRules":
+			pu.Update = &sdkpb.PermissionUpdate_AddRules{AddRules: &sdkpb.AddRulesUpdate{
+				Rules: rules, Behavior: behavior, Destination: destination,
+			}}
+		case "replaceRules":
+			pu.Update = &sdkpb.PermissionUpdate_ReplaceRules{ReplaceRules: &sdkpb.ReplaceRulesUpdate{
+				Rules: rules, Behavior: behavior, Destination: destination,
+			}}
+		case "removeRules":
+			pu.Update = &sdkpb.PermissionUpdate_RemoveRules{RemoveRules: &sdkpb.RemoveRulesUpdate{
+				Rules: rules, Behavior: behavior, Destination: destination,
+			}}
+		case "setMode":
+			mode, err := lookup(permissionModes, "mode", u.Mode)
+			if err != nil {
+				return nil, err
+			}
+			pu.Update = &sdkpb.PermissionUpdate_SetMode{SetMode: &sdkpb.SetModeUpdate{
+				Mode: mode, Destination: destination,
+			}}
+		case "addDirectories":
+			pu.Update = &sdkpb.PermissionUpdate_AddDirectories{AddDirectories: &sdkpb.AddDirectoriesUpdate{
+				Directories: u.Directories, Destination: destination,
+			}}
+		case "removeDirectories":
+			pu.Update = &sdkpb.PermissionUpdate_RemoveDirectories{RemoveDirectories: &sdkpb.RemoveDirectoriesUpdate{
+				Directories: u.Directories, Destination: destination,
+			}}
+		default:
+			return nil, fmt.Errorf("unknown permission update type %q", u.Type)
+		}
+		out = append(out, pu)
+	}
+	return out, nil
+}
+
+// PermissionUpdatesFromProto converts permission updates back to JSON.
+func PermissionUpdatesFromProto(updates []*sdkpb.PermissionUpdate) (json.RawMessage, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	rules := func(in []*sdkpb.PermissionRuleValue) []permissionRule {
+		var out []permissionRule
+		for _, r := range in {
+			out = append(out, permissionRule{ToolName: r.GetToolName(), RuleContent: r.RuleContent})
+		}
+		return out
+	}
+
+	out := make([]permissionUpdate, 0, len(updates))
+	for _, pu := range updates {
+		var u permissionUpdate
+		switch x := pu.GetUpdate().(type) {
+		case *sdkpb.PermissionUpdate_AddRules:
+			u = permissionUpdate{
+				Type:        "addRules",
+				Rules:       rules(x.AddRules.GetRules()),
+				Behavior:    keyOf(permissionBehaviors, x.AddRules.GetBehavior()),
+				Destination: keyOf(permissionDestinations, x.AddRules.GetDestination()),
+			}
+		case *sdkpb.PermissionUpdate_ReplaceRules:
+			u = permissionUpdate{
+				Type:        "replaceRules",
+				Rules:       rules(x.ReplaceRules.GetRules()),
+				Behavior:    keyOf(permissionBehaviors, x.ReplaceRules.GetBehavior()),
+				Destination: keyOf(permissionDestinations, x.ReplaceRules.GetDestination()),
+			}
+		case *sdkpb.PermissionUpdate_RemoveRules:
+			u = permissionUpdate{
+				Type:        "removeRules",
+				Rules:       rules(x.RemoveRules.GetRules()),
+				Behavior:    keyOf(permissionBehaviors, x.RemoveRules.GetBehavior()),
+				Destination: keyOf(permissionDestinations, x.RemoveRules.GetDestination()),
+			}
+		case *sdkpb.PermissionUpdate_SetMode:
+			u = permissionUpdate{
+				Type:        "setMode",
+				Mode:        keyOf(permissionModes, x.SetMode.GetMode()),
+				Destination: keyOf(permissionDestinations, x.SetMode.GetDestination()),
+			}
+		case *sdkpb.PermissionUpdate_AddDirectories:
+			u = permissionUpdate{
+				Type:        "addDirectories",
+				Directories: x.AddDirectories.GetDirectories(),
+				Destination: keyOf(permissionDestinations, x.AddDirectories.GetDestination()),
+			}
+		case *sdkpb.PermissionUpdate_RemoveDirectories:
+			u = permissionUpdate{
+				Type:        "removeDirectories",
+				Directories: x.RemoveDirectories.GetDirectories(),
+				Destination: keyOf(permissionDestinations, x.RemoveDirectories.GetDestination()),
+			}
+		default:
+			return nil, fmt.Errorf("unsupported permission update %T", x)
+		}
+		out = append(out, u)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode permission suggestions: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,534 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/ngicks/crabswarm/hook/model"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ToolInputToProto converts the tool_input JSON of tool.
+//
+// Inputs of tools with a typed message are converted to it when that is
+// lossless. MCP tool inputs are carried as the mcp_tool Struct; everything
+// else (tools without a typed message, and inputs with fields the typed
+// message cannot hold) as the other Struct. Empty input yields nil.
+func ToolInputToProto(tool model.ToolName, input json.RawMessage) (*sdkpb.ToolInput, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(input, &obj); err != nil {
+		return nil, fmt.Errorf("%s input is not a JSON object: %w", tool, err)
+	}
+	if obj == nil {
+		return nil, nil
+	}
+
+	if typed := typedToolInput(tool, input); typed != nil {
+		if back, err := ToolInputFromProto(typed); err == nil && sameJSON(obj, back) {
+			return typed, nil
+		}
+	}
+
+	s, err := structpb.NewStruct(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s input: %w", tool, err)
+	}
+	if tool.IsMCP() {
+		return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_McpTool{McpTool: s}}, nil
+	}
+	return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_Other{Other: s}}, nil
+}
+
+// typedToolInput converts input to the typed message of tool, or returns nil
+// if there is none or input does not decode.
+func typedToolInput(tool model.ToolName, input json.RawMessage) *sdkpb.ToolInput {
+	switch tool {
+	case model.ToolNameBash:
+		if in, ok := decode[model.BashInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_Bash{Bash: BashInputToProto(in)}}
+		}
+	case model.ToolNameRead:
+		if in, ok := decode[model.ReadInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_FileRead{FileRead: ReadInputToProto(in)}}
+		}
+	case model.ToolNameWrite:
+		if in, ok := decode[model.WriteInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_FileWrite{FileWrite: WriteInputToProto(in)}}
+		}
+	case model.ToolNameEdit:
+		if in, ok := decode[model.EditInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_FileEdit{FileEdit: EditInputToProto(in)}}
+		}
+	case model.ToolNameGlob:
+		if in, ok := decode[model.GlobInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_Glob{Glob: GlobInputToProto(in)}}
+		}
+	case model.ToolNameGrep:
+		if in, ok := decode[model.GrepInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_Grep{Grep: GrepInputToProto(in)}}
+		}
+	case model.ToolNameWebFetch:
+		if in, ok := decode[model.WebFetchInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_WebFetch{WebFetch: WebFetchInputToProto(in)}}
+		}
+	case model.ToolNameWebSearch:
+		if in, ok := decode[model.WebSearchInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_WebSearch{WebSearch: WebSearchInputToProto(in)}}
+		}
+	case model.ToolNameTask:
+		if in, ok := decode[model.TaskInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_Agent{Agent: TaskInputToProto(in)}}
+		}
+	case model.ToolNameTaskStop:
+		if in, ok := decode[model.TaskStopInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_KillShell{KillShell: TaskStopInputToProto(in)}}
+		}
+	case model.ToolNameAskUserQuestion:
+		if in, ok := decode[model.AskUserQuestionInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_AskUserQuestion{AskUserQuestion: AskUserQuestionInputToProto(in)}}
+		}
+	case model.ToolNameNotebookEdit:
+		if in, ok := decode[model.NotebookEditInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_NotebookEdit{NotebookEdit: NotebookEditInputToProto(in)}}
+		}
+	case model.ToolNameTodoWrite:
+		if in, ok := decode[model.TodoWriteInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_TodoWrite{TodoWrite: TodoWriteInputToProto(in)}}
+		}
+	case model.ToolNameExitPlanMode:
+		if in, ok := decode[model.ExitPlanModeInput](input); ok {
+			return &sdkpb.ToolInput{Input: &sdkpb.ToolInput_ExitPlanMode{ExitPlanMode: ExitPlanModeInputToProto(in)}}
+		}
+	}
+	return nil
+}
+
+func decode[T any](input json.RawMessage) (*T, bool) {
+	var v T
+	if err := json.Unmarshal(input, &v); err != nil {
+		return nil, false
+	}
+	return &v, true
+}
+
+// sameJSON reports whether data encodes the same value as obj.
+func sameJSON(obj map[string]any, data json.RawMessage) bool {
+	var other map[string]any
+	if err := json.Unmarshal(data, &other); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(obj, other)
+}
+
+// ToolInputFromProto converts in back to tool_input JSON. A nil in yields
+// nil.
+func ToolInputFromProto(in *sdkpb.ToolInput) (json.RawMessage, error) {
+	var v any
+	switch x := in.GetInput().(type) {
+	case nil:
+		return nil, nil
+	case *sdkpb.ToolInput_Bash:
+		v = BashInputFromProto(x.Bash)
+	case *sdkpb.ToolInput_FileRead:
+		v = ReadInputFromProto(x.FileRead)
+	case *sdkpb.ToolInput_FileWrite:
+		v = WriteInputFromProto(x.FileWrite)
+	case *sdkpb.ToolInput_FileEdit:
+		v = EditInputFromProto(x.FileEdit)
+	case *sdkpb.ToolInput_Glob:
+		v = GlobInputFromProto(x.Glob)
+	case *sdkpb.ToolInput_Grep:
+		v = GrepInputFromProto(x.Grep)
+	case *sdkpb.ToolInput_WebFetch:
+		v = WebFetchInputFromProto(x.WebFetch)
+	case *sdkpb.ToolInput_WebSearch:
+		v = WebSearchInputFromProto(x.WebSearch)
+	case *sdkpb.ToolInput_Agent:
+		v = TaskInputFromProto(x.Agent)
+	case *sdkpb.ToolInput_KillShell:
+		v = TaskStopInputFromProto(x.KillShell)
+	case *sdkpb.ToolInput_AskUserQuestion:
+		v = AskUserQuestionInputFromProto(x.AskUserQuestion)
+	case *sdkpb.ToolInput_NotebookEdit:
+		v = NotebookEditInputFromProto(x.NotebookEdit)
+	case *sdkpb.ToolInput_TodoWrite:
+		v = TodoWriteInputFromProto(x.TodoWrite)
+	case *sdkpb.ToolInput_ExitPlanMode:
+		v = ExitPlanModeInputFromProto(x.ExitPlanMode)
+	case *sdkpb.ToolInput_McpTool:
+		v = x.McpTool.AsMap()
+	case *sdkpb.ToolInput_Other:
+		v = x.Other.AsMap()
+	default:
+		return nil, fmt.Errorf("unsupported tool input %T", x)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool input: %w", err)
+	}
+	return data, nil
+}
+
+// BashInputToProto converts a Bash tool input.
+func BashInputToProto(in *model.BashInput) *sdkpb.BashInput {
+	return &sdkpb.BashInput{
+		Command:                   in.Command,
+		Timeout:                   optInt32(in.Timeout),
+		Description:               optString(in.Description),
+		RunInBackground:           optBool(in.RunInBackground),
+		DangerouslyDisableSandbox: optBool(in.DangerouslyDisableSandbox),
+	}
+}
+
+// BashInputFromProto converts a Bash tool input.
+func BashInputFromProto(in *sdkpb.BashInput) *model.BashInput {
+	return &model.BashInput{
+		Command:                   in.GetCommand(),
+		Timeout:                   int(in.GetTimeout()),
+		Description:               in.GetDescription(),
+		RunInBackground:           in.GetRunInBackground(),
+		DangerouslyDisableSandbox: in.GetDangerouslyDisableSandbox(),
+	}
+}
+
+// ReadInputToProto converts a Read tool input.
+func ReadInputToProto(in *model.ReadInput) *sdkpb.FileReadInput {
+	return &sdkpb.FileReadInput{
+		FilePath: in.FilePath,
+		Offset:   optInt32(in.Offset),
+		Limit:    optInt32(in.Limit),
+	}
+}
+
+// ReadInputFromProto converts a Read tool input.
+func ReadInputFromProto(in *sdkpb.FileReadInput) *model.ReadInput {
+	return &model.ReadInput{
+		FilePath: in.GetFilePath(),
+		Offset:   int(in.GetOffset()),
+		Limit:    int(in.GetLimit()),
+	}
+}
+
+// WriteInputToProto converts a Write tool input.
+func WriteInputToProto(in *model.WriteInput) *sdkpb.FileWriteInput {
+	return &sdkpb.FileWriteInput{FilePath: in.FilePath, Content: in.Content}
+}
+
+// WriteInputFromProto converts a Write tool input.
+func WriteInputFromProto(in *sdkpb.FileWriteInput) *model.WriteInput {
+	return &model.WriteInput{FilePath: in.GetFilePath(), Content: in.GetContent()}
+}
+
+// EditInputToProto converts an Edit tool input.
+func EditInputToProto(in *model.EditInput) *sdkpb.FileEditInput {
+	return &sdkpb.FileEditInput{
+		FilePath:   in.FilePath,
+		OldString:  in.OldString,
+		NewString:  in.NewString,
+		ReplaceAll: optBool(in.ReplaceAll),
+	}
+}
+
+// EditInputFromProto converts an Edit tool input.
+func EditInputFromProto(in *sdkpb.FileEditInput) *model.EditInput {
+	return &model.EditInput{
+		FilePath:   in.GetFilePath(),
+		OldString:  in.GetOldString(),
+		NewString:  in.GetNewString(),
+		ReplaceAll: in.GetReplaceAll(),
+	}
+}
+
+// GlobInputToProto converts a Glob tool input.
+func GlobInputToProto(in *model.GlobInput) *sdkpb.GlobInput {
+	return &sdkpb.GlobInput{Pattern: in.Pattern, Path: optString(in.Path)}
+}
+
+// GlobInputFromProto converts a Glob tool input.
+func GlobInputFromProto(in *sdkpb.GlobInput) *model.GlobInput {
+	return &model.GlobInput{Pattern: in.GetPattern(), Path: in.GetPath()}
+}
+
+var grepOutputModes = map[string]sdkpb.GrepOutputMode{
+	"content":            sdkpb.GrepOutputMode_GREP_OUTPUT_MODE_CONTENT,
+	"files_with_matches": sdkpb.GrepOutputMode_GREP_OUTPUT_MODE_FILES_WITH_MATCHES,
+	"count":              sdkpb.GrepOutputMode_GREP_OUTPUT_MODE_COUNT,
+}
+
+// GrepInputToProto converts a Grep tool input. The "-C" alias has no field
+// of its own and is carried as context.
+func GrepInputToProto(in *model.GrepInput) *sdkpb.GrepInput {
+	context := in.Context
+	if context == 0 {
+		context = in.ContextC
+	}
+	return &sdkpb.GrepInput{
+		Pattern:         in.Pattern,
+		Path:            optString(in.Path),
+		Glob:            optString(in.Glob),
+		Type:            optString(in.Type),
+		OutputMode:      grepOutputModes[in.OutputMode],
+		CaseInsensitive: optBool(in.CaseInsensitive),
+		ShowLineNumbers: optBool(in.ShowLineNumbers),
+		BeforeContext:   optInt32(in.ContextBefore),
+		AfterContext:    optInt32(in.ContextAfter),
+		Context:         optInt32(context),
+		HeadLimit:       optInt32(in.HeadLimit),
+		Multiline:       optBool(in.Multiline),
+		Offset:          optInt32(in.Offset),
+	}
+}
+
+// GrepInputFromProto converts a Grep tool input.
+func GrepInputFromProto(in *sdkpb.GrepInput) *model.GrepInput {
+	return &model.GrepInput{
+		Pattern:         in.GetPattern(),
+		Path:            in.GetPath(),
+		Glob:            in.GetGlob(),
+		Type:            in.GetType(),
+		OutputMode:      keyOf(grepOutputModes, in.GetOutputMode()),
+		Context:         int(in.GetContext()),
+		ContextBefore:   int(in.GetBeforeContext()),
+		ContextAfter:    int(in.GetAfterContext()),
+		CaseInsensitive: in.GetCaseInsensitive(),
+		ShowLineNumbers: in.GetShowLineNumbers(),
+		Multiline:       in.GetMultiline(),
+		HeadLimit:       int(in.GetHeadLimit()),
+		Offset:          int(in.GetOffset()),
+	}
+}
+
+// WebFetchInputToProto converts a WebFetch tool input.
+func WebFetchInputToProto(in *model.WebFetchInput) *sdkpb.WebFetchInput {
+	return &sdkpb.WebFetchInput{Url: in.URL, Prompt: in.Prompt}
+}
+
+// WebFetchInputFromProto converts a WebFetch tool input.
+func WebFetchInputFromProto(in *sdkpb.WebFetchInput) *model.WebFetchInput {
+	return &model.WebFetchInput{URL: in.GetUrl(), Prompt: in.GetPrompt()}
+}
+
+// WebSearchInputToProto converts a WebSearch tool input.
+func WebSearchInputToProto(in *model.WebSearchInput) *sdkpb.WebSearchInput {
+	return &sdkpb.WebSearchInput{
+		Query:          in.Query,
+		AllowedDomains: in.AllowedDomains,
+		BlockedDomains: in.BlockedDomains,
+	}
+}
+
+// WebSearchInputFromProto converts a WebSearch tool input.
+func WebSearchInputFromProto(in *sdkpb.WebSearchInput) *model.WebSearchInput {
+	return &model.WebSearchInput{
+		Query:          in.GetQuery(),
+		AllowedDomains: in.GetAllowedDomains(),
+		BlockedDomains: in.GetBlockedDomains(),
+	}
+}
+
+// TaskInputToProto converts a Task tool input.
+func TaskInputToProto(in *model.TaskInput) *sdkpb.AgentInput {
+	return &sdkpb.AgentInput{
+		Description:     in.Description,
+		Prompt:          in.Prompt,
+		SubagentType:    in.SubagentType,
+		Model:           optString(in.Model),
+		MaxTurns:        optInt32(in.MaxTurns),
+		Resume:          optString(in.Resume),
+		RunInBackground: optBool(in.RunInBackground),
+	}
+}
+
+// TaskInputFromProto converts a Task tool input.
+func TaskInputFromProto(in *sdkpb.AgentInput) *model.TaskInput {
+	return &model.TaskInput{
+		Description:     in.GetDescription(),
+		Prompt:          in.GetPrompt(),
+		SubagentType:    in.GetSubagentType(),
+		Model:           in.GetModel(),
+		MaxTurns:        int(in.GetMaxTurns()),
+		Resume:          in.GetResume(),
+		RunInBackground: in.GetRunInBackground(),
+	}
+}
+
+// TaskStopInputToProto converts a TaskStop tool input to the message of
+// KillShell, its former name.
+func TaskStopInputToProto(in *model.TaskStopInput) *sdkpb.KillShellInput {
+	id := in.TaskID
+	if id == "" {
+		id = in.ShellID
+	}
+	return &sdkpb.KillShellInput{ShellId: id}
+}
+
+// TaskStopInputFromProto converts a TaskStop tool input.
+func TaskStopInputFromProto(in *sdkpb.KillShellInput) *model.TaskStopInput {
+	return &model.TaskStopInput{TaskID: in.GetShellId()}
+}
+
+// AskUserQuestionInputToProto converts an AskUserQuestion tool input.
+func AskUserQuestionInputToProto(in *model.AskUserQuestionInput) *sdkpb.AskUserQuestionInput {
+	out := &sdkpb.AskUserQuestionInput{Answers: in.Answers}
+	for _, q := range in.Questions {
+		pq := &sdkpb.Question{Question: q.Question, Header: q.Header, MultiSelect: q.MultiSelect}
+		for _, o := range q.Options {
+			pq.Options = append(pq.Options, &sdkpb.QuestionOption{Label: o.Label, Description: o.Description})
+		}
+		out.Questions = append(out.Questions, pq)
+	}
+	return out
+}
+
+// AskUserQuestionInputFromProto converts an AskUserQuestion tool input.
+func AskUserQuestionInputFromProto(in *sdkpb.AskUserQuestionInput) *model.AskUserQuestionInput {
+	out := &model.AskUserQuestionInput{Answers: in.GetAnswers()}
+	for _, pq := range in.GetQuestions() {
+		q := model.Question{Question: pq.GetQuestion(), Header: pq.GetHeader(), MultiSelect: pq.GetMultiSelect()}
+		for _, o := range pq.GetOptions() {
+			q.Options = append(q.Options, model.QuestionOption{Label: o.GetLabel(), Description: o.GetDescription()})
+		}
+		out.Questions = append(out.Questions, q)
+	}
+	return out
+}
+
+var (
+	notebookCellTypes = map[string]sdkpb.NotebookCellType{
+		"code":     sdkpb.NotebookCellType_NOTEBOOK_CELL_TYPE_CODE,
+		"markdown": sdkpb.NotebookCellType_NOTEBOOK_CELL_TYPE_MARKDOWN,
+	}
+	notebookEditModes = map[string]sdkpb.NotebookEditMode{
+		"replace": sdkpb.NotebookEditMode_NOTEBOOK_EDIT_MODE_REPLACE,
+		"insert":  sdkpb.NotebookEditMode_NOTEBOOK_EDIT_MODE_INSERT,
+		"delete":  sdkpb.NotebookEditMode_NOTEBOOK_EDIT_MODE_DELETE,
+	}
+)
+
+// NotebookEditInputToProto converts a NotebookEdit tool input.
+func NotebookEditInputToProto(in *model.NotebookEditInput) *sdkpb.NotebookEditInput {
+	return &sdkpb.NotebookEditInput{
+		NotebookPath: in.NotebookPath,
+		CellId:       optString(in.CellID),
+		NewSource:    in.NewSource,
+		CellType:     notebookCellTypes[in.CellType],
+		EditMode:     notebookEditModes[in.EditMode],
+	}
+}
+
+// NotebookEditInputFromProto converts a NotebookEdit tool input.
+func NotebookEditInputFromProto(in *sdkpb.NotebookEditInput) *model.NotebookEditInput {
+	return &model.NotebookEditInput{
+		NotebookPath: in.GetNotebookPath(),
+		NewSource:    in.GetNewSource(),
+		CellID:       in.GetCellId(),
+		CellType:     keyOf(notebookCellTypes, in.GetCellType()),
+		EditMode:     keyOf(notebookEditModes, in.GetEditMode()),
+	}
+}
+
+var todoStatuses = map[string]sdkpb.TodoStatus{
+	"pending":     sdkpb.TodoStatus_TODO_STATUS_PENDING,
+	"in_progress": sdkpb.TodoStatus_TODO_STATUS_IN_PROGRESS,
+	"completed":   sdkpb.TodoStatus_TODO_STATUS_COMPLETED,
+}
+
+// TodoWriteInputToProto converts a TodoWrite tool input.
+func TodoWriteInputToProto(in *model.TodoWriteInput) *sdkpb.TodoWriteInput {
+	out := &sdkpb.TodoWriteInput{}
+	for _, t := range in.Todos {
+		out.Todos = append(out.Todos, &sdkpb.TodoItem{
+			Content:    t.Content,
+			Status:     todoStatuses[t.Status],
+			ActiveForm: t.ActiveForm,
+		})
+	}
+	return out
+}
+
+// TodoWriteInputFromProto converts a TodoWrite tool input.
+func TodoWriteInputFromProto(in *sdkpb.TodoWriteInput) *model.TodoWriteInput {
+	out := &model.TodoWriteInput{Todos: []model.TodoItem{}}
+	for _, t := range in.GetTodos() {
+		out.Todos = append(out.Todos, model.TodoItem{
+			Content:    t.GetContent(),
+			Status:     keyOf(todoStatuses, t.GetStatus()),
+			ActiveForm: t.GetActiveForm(),
+		})
+	}
+	return out
+}
+
+// ExitPlanModeInputToProto converts an ExitPlanMode tool input.
+func ExitPlanModeInputToProto(in *model.ExitPlanModeInput) *sdkpb.ExitPlanModeInput {
+	out := &sdkpb.ExitPlanModeInput{Plan: in.Plan}
+	for _, p := range in.AllowedPrompts {
+		out.AllowedPrompts = append(out.AllowedPrompts, &sdkpb.AllowedPrompt{Tool: p.Tool, Prompt: p.Prompt})
+	}
+	return out
+}
+
+// ExitPlanModeInputFromProto converts an ExitPlanMode tool input.
+func ExitPlanModeInputFromProto(in *sdkpb.ExitPlanModeInput) *model.ExitPlanModeInput {
+	out := &model.ExitPlanModeInput{Plan: in.GetPlan()}
+	for _, p := range in.GetAllowedPrompts() {
+		out.AllowedPrompts = append(out.AllowedPrompts, model.AllowedPrompt{Tool: p.GetTool(), Prompt: p.GetPrompt()})
+	}
+	return out
+}
+
+// MCPToolInputToProto converts the parameters of an MCP tool input.
+func MCPToolInputToProto(in *model.MCPToolInput) (*structpb.Struct, error) {
+	s, err := structpb.NewStruct(in.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert mcp__%s__%s input: %w", in.Server, in.Tool, err)
+	}
+	return s, nil
+}
+
+// MCPToolInputFromProto converts the parameters of an input to tool, which
+// must be an MCP tool.
+func MCPToolInputFromProto(tool model.ToolName, in *structpb.Struct) (*model.MCPToolInput, error) {
+	info := tool.ParseMCP()
+	if info == nil {
+		return nil, fmt.Errorf("tool %s is not an MCP tool", tool)
+	}
+	return &model.MCPToolInput{Server: info.Server, Tool: info.Tool, Parameters: in.AsMap()}, nil
+}
+
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optInt32(n int) *int32 {
+	if n == 0 {
+		return nil
+	}
+	v := int32(n)
+	return &v
+}
+
+func optBool(b bool) *bool {
+	if !b {
+		return nil
+	}
+	return &b
+}
+
+// keyOf returns the key of v in m, or "" if there is none.
+func keyOf[K ~string, V comparable](m map[K]V, v V) K {
+	for k, mv := range m {
+		if mv == v {
+			return k
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package protoconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ngicks/crabswarm/hook/model"
+	sdkpb "github.com/ngicks/crabswarm/pkg/api/gen/proto/go/sdk_types/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestToolInput_RoundTrip(t *testing.T) {
+	tests := []struct {
+		tool    model.ToolName
+		input   string
+		variant string // type of the oneof wrapper
+	}{
+		{"Bash", `{"command":"go test ./...","description":"Run tests","timeout":60000,"run_in_background":true}`, "*sdk_typesv1.ToolInput_Bash"},
+		{"Bash", `{"command":"curl example.com","dangerouslyDisableSandbox":true}`, "*sdk_typesv1.ToolInput_Bash"},
+		{"Read", `{"file_path":"/src/main.go","offset":10,"limit":50}`, "*sdk_typesv1.ToolInput_FileRead"},
+		{"Write", `{"file_path":"/src/new.go","content":"package main\n"}`, "*sdk_typesv1.ToolInput_FileWrite"},
+		{"Write", `{"file_path":"/src/empty.go","content":""}`, "*sdk_typesv1.ToolInput_FileWrite"},
+		{"Edit", `{"file_path":"/src/main.go","old_string":"a","new_string":"b","replace_all":true}`, "*sdk_typesv1.ToolInput_FileEdit"},
+		{"Glob", `{"pattern":"**/*.go","path":"/src"}`, "*sdk_typesv1.ToolInput_Glob"},
+		{"Grep", `{"pattern":"func \\w+","path":"/src","glob":"*.go","output_mode":"content","-i":true,"-n":true,"-B":2,"-A":3,"head_limit":20,"offset":5,"multiline":true}`, "*sdk_typesv1.ToolInput_Grep"},
+		{"Grep", `{"pattern":"TODO","-C":2}`, "*sdk_typesv1.ToolInput_Other"}, // -C comes back as context
+		{"WebFetch", `{"url":"https://example.com","prompt":"Summarize"}`, "*sdk_typesv1.ToolInput_WebFetch"},
+		{"WebSearch", `{"query":"go generics","allowed_domains":["go.dev"],"blocked_domains":["example.com"]}`, "*sdk_typesv1.ToolInput_WebSearch"},
+		{"Task", `{"description":"Find callers","prompt":"Find all callers of Foo","subagent_type":"Explore","model":"haiku","run_in_background":true}`, "*sdk_typesv1.ToolInput_Agent"},
+		{"TaskStop", `{"task_id":"b12"}`, "*sdk_typesv1.ToolInput_KillShell"},
+		{"TaskStop", `{"shell_id":"b12"}`, "*sdk_typesv1.ToolInput_Other"}, // deprecated field
+		{"AskUserQuestion", `{"questions":[{"question":"Which DB?","header":"DB","options":[{"label":"Postgres","description":"SQL"},{"label":"Bolt"}],"multiSelect":false}],"answers":{"Which DB?":"Bolt"}}`, "*sdk_typesv1.ToolInput_AskUserQuestion"},
+		{"AskUserQuestion", `{"questions":[{"question":"Which DB?","header":"DB","options":[{"label":"Bolt"}],"multiSelect":true}],"metadata":{"source":"remember"}}`, "*sdk_typesv1.ToolInput_Other"},
+		{"NotebookEdit", `{"notebook_path":"/nb/a.ipynb","cell_id":"c1","new_source":"x = 1","cell_type":"code","edit_mode":"insert"}`, "*sdk_typesv1.ToolInput_NotebookEdit"},
+		{"NotebookEdit", `{"notebook_path":"/nb/a.ipynb","new_source":"x","cell_type":"raw"}`, "*sdk_typesv1.ToolInput_Other"}, // no such cell type
+		{"TodoWrite", `{"todos":[{"content":"Write tests","status":"in_progress","activeForm":"Writing tests"},{"content":"Ship","status":"pending","activeForm":"Shipping"}]}`, "*sdk_typesv1.ToolInput_TodoWrite"},
+		{"ExitPlanMode", `{"plan":"1. Refactor\n2. Test","allowedPrompts":[{"tool":"Bash","prompt":"run tests"}]}`, "*sdk_typesv1.ToolInput_ExitPlanMode"},
+		{"ExitPlanMode", `{"plan":"p","pushToRemote":true}`, "*sdk_typesv1.ToolInput_Other"},
+		{"Skill", `{"skill":"pdf","args":"report.pdf"}`, "*sdk_typesv1.ToolInput_Other"},
+		{"TaskCreate", `{"subject":"Fix","description":"Fix the bug","metadata":{"priority":2}}`, "*sdk_typesv1.ToolInput_Other"},
+		{"EnterPlanMode", `{}`, "*sdk_typesv1.ToolInput_Other"},
+		{"mcp__github__create_issue", `{"owner":"o","repo":"r","title":"Bug","labels":["bug"],"draft":false,"extra":null}`, "*sdk_typesv1.ToolInput_McpTool"},
+		{"Unknown", `{"anything":{"nested":[1,2.5,"x"]}}`, "*sdk_typesv1.ToolInput_Other"},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.tool), func(t *testing.T) {
+			p, err := ToolInputToProto(tc.tool, json.RawMessage(tc.input))
+			if err != nil {
+				t.Fatalf("ToolInputToProto() error: %v", err)
+			}
+			if got := fmt.Sprintf("%T", p.GetInput()); got != tc.variant {
+				t.Errorf("variant = %s, want %s", got, tc.variant)
+			}
+
+			data, err := proto.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded sdkpb.ToolInput
+			if err := proto.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ToolInputFromProto(&decoded)
+			if err != nil {
+				t.Fatalf("ToolInputFromProto() error: %v", err)
+			}
+			assertSameJSON(t, json.RawMessage(tc.input), got)
+		})
+	}
+}
+
+func TestToolInputToProto_Empty(t *testing.T) {
+	for _, input := range []string{"", "null"} {
+		p, err := ToolInputToProto("Bash", json.RawMessage(input))
+		if p != nil || err != nil {
+			t.Errorf("ToolInputToProto(%q) = %v, %v; want nil, nil", input, p, err)
+		}
+	}
+	if data, err := ToolInputFromProto(nil); data != nil || err != nil {
+		t.Errorf("ToolInputFromProto(nil) = %s, %v", data, err)
+	}
+}
+
+func TestMCPToolInput(t *testing.T) {
+	in := &model.MCPToolInput{Server: "memory", Tool: "search", Parameters: map[string]any{"query": "go", "limit": 5.0}}
+	s, err := MCPToolInputToProto(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := MCPToolInputFromProto("mcp__memory__search", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameJSON(t, in, got)
+	if got.Server != "memory" || got.Tool != "search" || got.Parameters["query"] != "go" {
+		t.Errorf("MCPToolInputFromProto() = %+v", got)
+	}
+
+	if _, err := MCPToolInputFromProto("Bash", s); err == nil {
+		t.Error("expected error for non-MCP tool")
+	}
+}
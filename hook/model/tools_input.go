@@ -242,6 +242,8 @@ type EnterPlanModeInput struct{}
 
 // ExitPlanModeInput represents the input for the ExitPlanMode tool.
 type ExitPlanModeInput struct {
+	// Plan is the plan to run by the user for approval.
+	Plan string `json:"plan,omitempty"`
 	// AllowedPrompts are prompt-based permissions needed to implement the plan.
 	AllowedPrompts []AllowedPrompt `json:"allowedPrompts,omitempty"`
 	// PushToRemote indicates whether to push the plan to a remote session.
@@ -262,6 +264,22 @@ type AllowedPrompt struct {
 	Prompt string `json:"prompt"`
 }
 
+// TodoWriteInput represents the input for the TodoWrite tool.
+type TodoWriteInput struct {
+	// Todos is the updated todo list.
+	Todos []TodoItem `json:"todos"`
+}
+
+// TodoItem represents a single task in the todo list.
+type TodoItem struct {
+	// Content is the task description.
+	Content string `json:"content"`
+	// Status is "pending", "in_progress", or "completed".
+	Status string `json:"status"`
+	// ActiveForm is present continuous form shown in spinner.
+	ActiveForm string `json:"activeForm"`
+}
+
 // TaskListInput represents the input for the TaskList tool.
 // It has no parameters.
 type TaskListInput struct{}
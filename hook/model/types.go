@@ -22,6 +22,9 @@ type HookInput struct {
 	// Cwd is the current working directory.
 	Cwd string `json:"cwd,omitempty"`
 
+	// PermissionMode is the session's permission mode (e.g., "default", "plan").
+	PermissionMode string `json:"permission_mode,omitempty"`
+
 	// MessageID is the unique identifier for the current message.
 	MessageID string `json:"message_id,omitempty"`
 
@@ -143,6 +146,8 @@ func (h *HookInput) ParseToolInput() (any, error) {
 		target = &EnterPlanModeInput{}
 	case ToolNameExitPlanMode:
 		target = &ExitPlanModeInput{}
+	case ToolNameTodoWrite:
+		target = &TodoWriteInput{}
 	default:
 		// Check if it's an MCP tool
 		if h.ToolName.IsMCP() {
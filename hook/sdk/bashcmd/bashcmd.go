@@ -0,0 +1,427 @@
+// Package bashcmd analyzes Bash command lines, such as model.BashInput.Command,
+// and breaks them down into the program invocations they would run.
+//
+// Pipelines, lists (;, &&, ||), subshells, command and process substitutions,
+// function bodies, environment prefixes and wrapper programs (sudo, env, xargs,
+// ...) are all taken into account, so allowlist checks cannot be bypassed with
+// commands like "ls; rm -rf /" or "echo $(curl ...) | sh".
+//
+// It can be used from server-side policies as well as from sdk.Matcher handlers:
+//
+//	matcher.WithCommand(func(input *model.HookInput, toolInput any) model.HookOutput {
+//		bash, ok := toolInput.(*model.BashInput)
+//		if !ok {
+//			return model.Ask(input.HookEventName)
+//		}
+//		a, err := bashcmd.Analyze(bash.Command)
+//		if err != nil {
+//			return model.Ask(input.HookEventName)
+//		}
+//		if ok, _ := a.Allows([]string{"git status", "go test", "ls"}); ok {
+//			return model.AllowWithEvent(input.HookEventName)
+//		}
+//		return model.Ask(input.HookEventName)
+//	})
+package bashcmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Invocation is a single program invocation found in a command line.
+type Invocation struct {
+	// Args is the argument vector after wrappers have been stripped.
+	// Args[0] is the program name. Arguments that cannot be resolved
+	// statically are kept as their source text.
+	Args []string
+	// Env holds environment assignments applied to the invocation,
+	// from "FOO=bar cmd" prefixes or env wrapper arguments.
+	Env []string
+	// Wrappers lists the wrapper programs that were stripped, outermost first.
+	Wrappers []string
+	// Dynamic reports, per element of Args, whether the argument contains an
+	// expansion ($VAR, $(...), $((...)), <(...)) whose value is unknown
+	// before execution.
+	Dynamic []bool
+	// Substituted is true if the invocation runs inside a command or process
+	// substitution, or inside the script passed to "sh -c".
+	Substituted bool
+}
+
+// Program returns the program name, or "" if there is none
+// (e.g. "xargs" with no command).
+func (i Invocation) Program() string {
+	if len(i.Args) == 0 {
+		return ""
+	}
+	return i.Args[0]
+}
+
+// String returns the invocation as a space-joined argument list.
+func (i Invocation) String() string {
+	return strings.Join(i.Args, " ")
+}
+
+// Redirect is a file redirection found in a command line.
+type Redirect struct {
+	// Op is the redirection operator (e.g. ">", ">>", "<", "&>").
+	Op string
+	// Target is the redirection target. For here-documents it is empty.
+	Target string
+	// Dynamic is true if Target contains an expansion.
+	Dynamic bool
+}
+
+// IsWrite reports whether the redirection writes to a file.
+// Duplicating file descriptors (">&2") and writing to /dev/null are not
+// considered writes.
+func (r Redirect) IsWrite() bool {
+	switch r.Op {
+	case ">", ">>", ">|", "<>", "&>", "&>>":
+		return r.Dynamic || r.Target != "/dev/null"
+	case ">&":
+		if r.Dynamic {
+			return true
+		}
+		return !isFD(r.Target) && r.Target != "-" && r.Target != "/dev/null"
+	}
+	return false
+}
+
+// Analysis is the result of analyzing a command line.
+type Analysis struct {
+	// Invocations lists every program invocation in source order.
+	Invocations []Invocation
+	// Redirects lists every redirection in source order.
+	Redirects []Redirect
+	// Opaque lists invocations whose command could not be analyzed: shell
+	// invocations ("sh -c ...") with a script that is not static, and env
+	// wrappers splitting a string into a command (env -S ...).
+	Opaque []Invocation
+}
+
+// Analyze parses command as Bash and returns the invocations it contains.
+func Analyze(command string) (*Analysis, error) {
+	return analyze(command, false, 0)
+}
+
+// maxShellDepth bounds recursion into nested "sh -c" scripts.
+const maxShellDepth = 8
+
+func analyze(command string, substituted bool, depth int) (*Analysis, error) {
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse command: %w", err)
+	}
+
+	a := &Analysis{}
+	substDepth := 0
+	var stack []syntax.Node
+	var walkErr error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if walkErr != nil {
+			return false
+		}
+		if node == nil {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			switch top.(type) {
+			case *syntax.CmdSubst, *syntax.ProcSubst:
+				substDepth--
+			}
+			return true
+		}
+		stack = append(stack, node)
+
+		switch n := node.(type) {
+		case *syntax.CmdSubst, *syntax.ProcSubst:
+			substDepth++
+		case *syntax.Redirect:
+			a.Redirects = append(a.Redirects, newRedirect(n))
+		case *syntax.CallExpr:
+			inv, opaque, ok := newInvocation(n)
+			if !ok {
+				return true
+			}
+			inv.Substituted = substituted || substDepth > 0
+			a.Invocations = append(a.Invocations, inv)
+			if opaque {
+				a.Opaque = append(a.Opaque, inv)
+				return true
+			}
+
+			if script, found, static := shellScript(inv); found {
+				if !static || depth >= maxShellDepth {
+					a.Opaque = append(a.Opaque, inv)
+					return true
+				}
+				inner, err := analyze(script, true, depth+1)
+				if err != nil {
+					walkErr = err
+					return true
+				}
+				a.Invocations = append(a.Invocations, inner.Invocations...)
+				a.Redirects = append(a.Redirects, inner.Redirects...)
+				a.Opaque = append(a.Opaque, inner.Opaque...)
+			}
+		}
+		return true
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return a, nil
+}
+
+// Programs returns the distinct program names in a, in order of first appearance.
+func (a *Analysis) Programs() []string {
+	seen := make(map[string]bool)
+	var programs []string
+	for _, inv := range a.Invocations {
+		p := inv.Program()
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		programs = append(programs, p)
+	}
+	return programs
+}
+
+// Allows reports whether every invocation in a matches one of patterns, no
+// redirection writes to a file and no command is opaque. If not, reason
+// describes the first offending invocation or redirection.
+//
+// Each pattern is a whitespace-separated list of argument globs matched
+// against the leading arguments of an invocation, so "go test" allows
+// "go test ./..." and "ls" allows "ls -la". In globs, '*' matches any
+// sequence of characters and '?' matches any single character.
+// Arguments compared against a pattern must be static.
+//
+// Invocations with environment assignments or privilege wrappers (sudo,
+// doas, pkexec) only match patterns naming them in leading tokens: every
+// assignment must match a NAME=VALUE glob and every privilege wrapper must
+// be listed, e.g. "GOFLAGS=* go test" or "sudo systemctl status".
+func (a *Analysis) Allows(patterns []string) (ok bool, reason string) {
+	for _, r := range a.Redirects {
+		if r.IsWrite() {
+			return false, fmt.Sprintf("redirection %s %s writes to a file", r.Op, r.Target)
+		}
+	}
+	if len(a.Opaque) > 0 {
+		return false, fmt.Sprintf("command %q cannot be analyzed", a.Opaque[0].String())
+	}
+	for _, inv := range a.Invocations {
+		if !matchAny(patterns, inv) {
+			return false, fmt.Sprintf("command %q is not allowed", strings.Join(slices.Concat(inv.Env, privileges(inv), inv.Args), " "))
+		}
+	}
+	return true, ""
+}
+
+func matchAny(patterns []string, inv Invocation) bool {
+	if len(inv.Args) == 0 {
+		return false
+	}
+	for _, p := range patterns {
+		if matchPattern(strings.Fields(p), inv) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(tokens []string, inv Invocation) bool {
+	// Leading assignments and privilege wrappers of the pattern.
+	var assigns, privileged []string
+	for len(tokens) > 0 {
+		if isAssign(tokens[0]) {
+			assigns = append(assigns, tokens[0])
+		} else if isPrivileged(tokens[0]) {
+			privileged = append(privileged, tokens[0])
+		} else {
+			break
+		}
+		tokens = tokens[1:]
+	}
+	for _, env := range inv.Env {
+		if !slices.ContainsFunc(assigns, func(glob string) bool { return MatchGlob(glob, env) }) {
+			return false
+		}
+	}
+	for _, w := range privileges(inv) {
+		if !slices.Contains(privileged, w) {
+			return false
+		}
+	}
+
+	if len(tokens) == 0 || len(tokens) > len(inv.Args) {
+		return false
+	}
+	for i, tok := range tokens {
+		if inv.Dynamic[i] || !MatchGlob(tok, inv.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// privileges returns the privilege wrappers of inv.
+func privileges(inv Invocation) []string {
+	var names []string
+	for _, w := range inv.Wrappers {
+		if isPrivileged(w) {
+			names = append(names, w)
+		}
+	}
+	return names
+}
+
+func newRedirect(r *syntax.Redirect) Redirect {
+	rd := Redirect{Op: r.Op.String()}
+	if r.Word != nil && r.Hdoc == nil {
+		rd.Target, rd.Dynamic = wordString(r.Word)
+	}
+	return rd
+}
+
+// newInvocation returns the invocation of call. opaque reports that a
+// wrapper runs a command that cannot be analyzed; ok is false for
+// assignment-only statements.
+func newInvocation(call *syntax.CallExpr) (inv Invocation, opaque, ok bool) {
+	if len(call.Args) == 0 {
+		// Assignment-only statement such as "FOO=bar".
+		return Invocation{}, false, false
+	}
+
+	for _, assign := range call.Assigns {
+		if assign.Name == nil {
+			continue
+		}
+		value, _ := wordString(assign.Value)
+		inv.Env = append(inv.Env, assign.Name.Value+"="+value)
+	}
+	for _, w := range call.Args {
+		s, dyn := wordString(w)
+		inv.Args = append(inv.Args, s)
+		inv.Dynamic = append(inv.Dynamic, dyn)
+	}
+
+	opaque = unwrap(&inv)
+	return inv, opaque, true
+}
+
+// wordString resolves w to its literal value. If w contains an expansion,
+// its source text is returned along with dynamic set to true.
+func wordString(w *syntax.Word) (s string, dynamic bool) {
+	if w == nil {
+		return "", false
+	}
+	if isDynamic(w) {
+		var b strings.Builder
+		syntax.NewPrinter().Print(&b, w)
+		return b.String(), true
+	}
+	var b strings.Builder
+	for _, part := range w.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			b.WriteString(unescape(p.Value, ""))
+		case *syntax.SglQuoted:
+			b.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, dp := range p.Parts {
+				if lit, ok := dp.(*syntax.Lit); ok {
+					b.WriteString(unescape(lit.Value, "$`\"\\\n"))
+				}
+			}
+		}
+	}
+	return b.String(), false
+}
+
+// unescape removes backslash escapes from s. If escapable is non-empty, only
+// backslashes preceding one of its characters are removed, as inside double
+// quotes. An escaped newline is a line continuation and is dropped entirely.
+func unescape(s, escapable string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		next := s[i+1]
+		switch {
+		case next == '\n':
+			i++
+		case escapable == "" || strings.IndexByte(escapable, next) >= 0:
+			b.WriteByte(next)
+			i++
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func isDynamic(w *syntax.Word) bool {
+	dynamic := false
+	syntax.Walk(w, func(node syntax.Node) bool {
+		switch node.(type) {
+		case *syntax.ParamExp, *syntax.CmdSubst, *syntax.ArithmExp, *syntax.ProcSubst, *syntax.ExtGlob:
+			dynamic = true
+			return false
+		}
+		return !dynamic
+	})
+	return dynamic
+}
+
+func isFD(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchGlob reports whether s matches pattern, where '*' matches any
+// sequence of characters and '?' matches any single character.
+func MatchGlob(pattern, s string) bool {
+	p := []rune(pattern)
+	str := []rune(s)
+	pi, si := 0, 0
+	starP, starS := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			starP = pi
+			starS = si
+			pi++
+		case starP >= 0:
+			pi = starP + 1
+			starS++
+			si = starS
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,171 @@
+package bashcmd
+
+import (
+	"path"
+	"strings"
+)
+
+// wrapper describes a program that runs another program given as its
+// trailing arguments.
+type wrapper struct {
+	// argOpts lists short options that consume the following argument.
+	argOpts string
+	// longArgOpts lists long options that consume the following argument
+	// when not written as --opt=value.
+	longArgOpts []string
+	// cmdOpts and longCmdOpts list the options whose argument is itself
+	// split into a command (env -S), which is not analyzed.
+	cmdOpts     string
+	longCmdOpts []string
+	// positional is the number of non-option arguments before the command
+	// (e.g. the duration of timeout).
+	positional int
+	// assigns is true if NAME=VALUE arguments before the command are
+	// environment assignments (env).
+	assigns bool
+	// defaultCmd is run when no command is given (xargs runs echo).
+	defaultCmd string
+	// privileged is true if the command runs as another user (sudo).
+	privileged bool
+}
+
+var wrappers = map[string]wrapper{
+	"sudo":    {argOpts: "CDghpRrTtUu", longArgOpts: []string{"--chdir", "--close-from", "--group", "--host", "--prompt", "--role", "--type", "--other-user", "--user", "--chroot", "--command-timeout"}, privileged: true},
+	"doas":    {argOpts: "Cu", privileged: true},
+	"pkexec":  {longArgOpts: []string{"--user"}, privileged: true},
+	"env":     {argOpts: "CSu", longArgOpts: []string{"--chdir", "--split-string", "--unset"}, cmdOpts: "S", longCmdOpts: []string{"--split-string"}, assigns: true},
+	"xargs":   {argOpts: "adEeIiLlnPs", longArgOpts: []string{"--arg-file", "--delimiter", "--eof", "--replace", "--max-lines", "--max-args", "--max-procs", "--max-chars", "--process-slot-var"}, defaultCmd: "echo"},
+	"nohup":   {},
+	"time":    {argOpts: "fo", longArgOpts: []string{"--format", "--output"}},
+	"nice":    {argOpts: "n", longArgOpts: []string{"--adjustment"}},
+	"ionice":  {argOpts: "cnp", longArgOpts: []string{"--class", "--classdata", "--pid"}},
+	"timeout": {argOpts: "ks", longArgOpts: []string{"--kill-after", "--signal"}, positional: 1},
+	"stdbuf":  {argOpts: "ioe", longArgOpts: []string{"--input", "--output", "--error"}},
+	"command": {},
+	"builtin": {},
+	"exec":    {argOpts: "a"},
+}
+
+// unwrap strips wrapper programs from inv.Args, recording them in
+// inv.Wrappers and any env assignments in inv.Env. It reports opaque, and
+// keeps the wrapper in inv.Args, if a wrapper option takes a command line
+// that cannot be analyzed (env -S).
+func unwrap(inv *Invocation) (opaque bool) {
+	for len(inv.Args) > 0 && !inv.Dynamic[0] {
+		name := path.Base(inv.Args[0])
+		w, ok := wrappers[name]
+		if !ok {
+			return false
+		}
+
+		i := 1
+		// Options.
+		for i < len(inv.Args) {
+			arg := inv.Args[i]
+			if arg == "--" {
+				i++
+				break
+			}
+			if !strings.HasPrefix(arg, "-") || arg == "-" {
+				break
+			}
+			i++
+			if strings.HasPrefix(arg, "--") {
+				opt, _, hasValue := strings.Cut(arg, "=")
+				if contains(w.longCmdOpts, opt) {
+					return true
+				}
+				if !hasValue && contains(w.longArgOpts, arg) {
+					i++
+				}
+				continue
+			}
+			// Short option cluster: the first option taking an argument
+			// consumes the rest of the cluster or the next argument.
+			for j, c := range arg[1:] {
+				if strings.ContainsRune(w.cmdOpts, c) {
+					return true
+				}
+				if strings.ContainsRune(w.argOpts, c) {
+					if j == len(arg)-2 {
+						i++
+					}
+					break
+				}
+			}
+		}
+		// Environment assignments.
+		for w.assigns && i < len(inv.Args) && isAssign(inv.Args[i]) {
+			inv.Env = append(inv.Env, inv.Args[i])
+			i++
+		}
+		// Positional arguments.
+		i += w.positional
+		if i > len(inv.Args) {
+			i = len(inv.Args)
+		}
+
+		inv.Wrappers = append(inv.Wrappers, name)
+		inv.Args = inv.Args[i:]
+		inv.Dynamic = inv.Dynamic[i:]
+		if len(inv.Args) == 0 && w.defaultCmd != "" {
+			inv.Args = []string{w.defaultCmd}
+			inv.Dynamic = []bool{false}
+		}
+	}
+	return false
+}
+
+// isPrivileged reports whether the wrapper name runs its command as
+// another user.
+func isPrivileged(name string) bool {
+	return wrappers[name].privileged
+}
+
+// shellScript returns the script of a "sh -c script" style invocation.
+// found reports whether inv is a shell invoked with -c; static reports
+// whether the script could be resolved.
+func shellScript(inv Invocation) (script string, found, static bool) {
+	switch path.Base(inv.Program()) {
+	case "sh", "bash", "zsh", "dash", "ksh":
+	default:
+		return "", false, false
+	}
+	for i := 1; i < len(inv.Args)-1; i++ {
+		arg := inv.Args[i]
+		if arg == "-o" || arg == "+o" {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+			return "", false, false
+		}
+		if strings.Contains(arg, "c") {
+			return inv.Args[i+1], true, !inv.Dynamic[i+1]
+		}
+	}
+	return "", false, false
+}
+
+func isAssign(s string) bool {
+	eq := strings.IndexByte(s, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, r := range s[:eq] {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -10,7 +10,14 @@ import (
 // Returns the hook output to send back to Claude Code.
 type ToolHandler func(input *model.HookInput, toolInput any) model.HookOutput
 
-// Matcher routes hook inputs to appropriate handlers based on tool category.
+// Matcher routes hook inputs to appropriate handlers based on the tool.
+//
+// The first handler found in this order handles an input:
+//  1. Input handlers registered with OnInput, in registration order.
+//  2. The handler registered for the tool name with OnTool.
+//  3. Handlers registered with OnToolMatch, in registration order.
+//  4. The handler of the tool's category (OnCommand, OnFilePath, ...).
+//  5. Default.
 type Matcher struct {
 	// OnCommand handles Bash tool invocations.
 	OnCommand ToolHandler
@@ -39,6 +46,64 @@ type Matcher struct {
 	// Default is the fallback handler when no category-specific handler is set.
 	// If nil, defaults to allowing the operation.
 	Default ToolHandler
+
+	inputRoutes   []toolRoute
+	toolHandlers  map[model.ToolName]ToolHandler
+	patternRoutes []toolRoute
+	middlewares   []Middleware
+}
+
+// toolRoute is a handler registered for the inputs match reports true for.
+type toolRoute struct {
+	match   func(input *model.HookInput, toolInput any) bool
+	handler ToolHandler
+}
+
+// OnTool registers handler for the tool with exactly the given name,
+// replacing any handler registered for it before.
+func (m *Matcher) OnTool(name model.ToolName, handler ToolHandler) *Matcher {
+	if m.toolHandlers == nil {
+		m.toolHandlers = make(map[model.ToolName]ToolHandler)
+	}
+	m.toolHandlers[name] = handler
+	return m
+}
+
+// OnToolMatch registers handler for the tools matched by pattern, a Claude
+// Code hook matcher such as "Edit|Write" or "mcp__github__.*" (see
+// ToolMatcher). It panics if pattern is not a valid regular expression.
+func (m *Matcher) OnToolMatch(pattern string, handler ToolHandler) *Matcher {
+	tm := MustParseToolMatcher(pattern)
+	m.patternRoutes = append(m.patternRoutes, toolRoute{
+		match: func(input *model.HookInput, _ any) bool {
+			return tm.Match(input.ToolName)
+		},
+		handler: handler,
+	})
+	return m
+}
+
+// OnInput registers handler for inputs whose parsed tool input (see
+// model.HookInput.ParseToolInput) is a T for which pred returns true. A nil
+// pred accepts every T.
+//
+// For example, to handle git commands:
+//
+//	sdk.OnInput(m,
+//		func(in *model.BashInput) bool { return strings.HasPrefix(in.Command, "git ") },
+//		func(input *model.HookInput, in *model.BashInput) model.HookOutput { ... },
+//	)
+func OnInput[T any](m *Matcher, pred func(toolInput T) bool, handler func(input *model.HookInput, toolInput T) model.HookOutput) *Matcher {
+	m.inputRoutes = append(m.inputRoutes, toolRoute{
+		match: func(_ *model.HookInput, toolInput any) bool {
+			t, ok := toolInput.(T)
+			return ok && (pred == nil || pred(t))
+		},
+		handler: func(input *model.HookInput, toolInput any) model.HookOutput {
+			return handler(input, toolInput.(T))
+		},
+	})
+	return m
 }
 
 // NewMatcher creates a new Matcher with a default allow handler.
@@ -50,8 +115,20 @@ func NewMatcher() *Matcher {
 	}
 }
 
+// Use appends middlewares wrapping Handle. The first middleware is the
+// outermost.
+func (m *Matcher) Use(middlewares ...Middleware) *Matcher {
+	m.middlewares = append(m.middlewares, middlewares...)
+	return m
+}
+
 // Handle processes a hook input and returns the appropriate output.
 func (m *Matcher) Handle(input *model.HookInput) model.HookOutput {
+	return chain(m.route, m.middlewares)(input)
+}
+
+// route parses the tool input and calls the handler of its category.
+func (m *Matcher) route(input *model.HookInput) model.HookOutput {
 	if input == nil {
 		return m.handleDefault(nil, nil)
 	}
@@ -68,11 +145,37 @@ func (m *Matcher) Handle(input *model.HookInput) model.HookOutput {
 		}
 	}
 
+	if handler, ok := m.routeByTool(input, toolInput); ok {
+		return handler(input, toolInput)
+	}
+
 	// Route based on category
 	category := input.Category()
 	return m.routeByCategory(input, toolInput, category)
 }
 
+// routeByTool finds the handler registered with OnInput, OnTool or
+// OnToolMatch for the input. Inputs without a tool are not routed by tool.
+func (m *Matcher) routeByTool(input *model.HookInput, toolInput any) (ToolHandler, bool) {
+	if input.ToolName == "" {
+		return nil, false
+	}
+	for _, r := range m.inputRoutes {
+		if r.match(input, toolInput) {
+			return r.handler, true
+		}
+	}
+	if handler, ok := m.toolHandlers[input.ToolName]; ok {
+		return handler, true
+	}
+	for _, r := range m.patternRoutes {
+		if r.match(input, toolInput) {
+			return r.handler, true
+		}
+	}
+	return nil, false
+}
+
 // routeByCategory routes to the appropriate handler based on tool category.
 func (m *Matcher) routeByCategory(input *model.HookInput, toolInput any, category model.ToolCategory) model.HookOutput {
 	switch category {
@@ -241,6 +344,25 @@ func (b *MatcherBuilder) Default(handler ToolHandler) *MatcherBuilder {
 	return b
 }
 
+// OnTool sets the handler for the tool with exactly the given name.
+func (b *MatcherBuilder) OnTool(name model.ToolName, handler ToolHandler) *MatcherBuilder {
+	b.matcher.OnTool(name, handler)
+	return b
+}
+
+// OnToolMatch adds a handler for the tools matched by a Claude Code hook
+// matcher. It panics if pattern is invalid.
+func (b *MatcherBuilder) OnToolMatch(pattern string, handler ToolHandler) *MatcherBuilder {
+	b.matcher.OnToolMatch(pattern, handler)
+	return b
+}
+
+// Use appends middlewares wrapping the matcher.
+func (b *MatcherBuilder) Use(middlewares ...Middleware) *MatcherBuilder {
+	b.matcher.Use(middlewares...)
+	return b
+}
+
 // Build returns the configured Matcher.
 func (b *MatcherBuilder) Build() *Matcher {
 	return b.matcher
@@ -254,6 +376,8 @@ type EventMatcher struct {
 	handlers map[model.HookEventName]EventHandler
 	// Default handler for unmatched events.
 	Default EventHandler
+
+	middlewares []Middleware
 }
 
 // NewEventMatcher creates a new EventMatcher.
@@ -338,8 +462,20 @@ func (m *EventMatcher) WithDefault(handler EventHandler) *EventMatcher {
 	return m
 }
 
+// Use appends middlewares wrapping Handle. The first middleware is the
+// outermost.
+func (m *EventMatcher) Use(middlewares ...Middleware) *EventMatcher {
+	m.middlewares = append(m.middlewares, middlewares...)
+	return m
+}
+
 // Handle processes a hook input and returns the appropriate output.
 func (m *EventMatcher) Handle(input *model.HookInput) model.HookOutput {
+	return chain(m.route, m.middlewares)(input)
+}
+
+// route calls the handler registered for the event, or Default.
+func (m *EventMatcher) route(input *model.HookInput) model.HookOutput {
 	if input == nil {
 		if m.Default != nil {
 			return m.Default(nil)
@@ -358,9 +494,15 @@ func (m *EventMatcher) Handle(input *model.HookInput) model.HookOutput {
 }
 
 // CombinedMatcher combines event-level and tool-level matching.
+//
+// Its own middlewares wrap every input. Inputs handled by the event matcher
+// (registered events and the default) also pass through the event matcher's
+// middlewares, and inputs routed to the tool matcher through the tool
+// matcher's.
 type CombinedMatcher struct {
 	eventMatcher *EventMatcher
 	toolMatcher  *Matcher
+	middlewares  []Middleware
 }
 
 // NewCombinedMatcher creates a new CombinedMatcher.
@@ -381,15 +523,28 @@ func (m *CombinedMatcher) Tools() *Matcher {
 	return m.toolMatcher
 }
 
+// Use appends middlewares wrapping Handle. The first middleware is the
+// outermost.
+func (m *CombinedMatcher) Use(middlewares ...Middleware) *CombinedMatcher {
+	m.middlewares = append(m.middlewares, middlewares...)
+	return m
+}
+
 // Handle processes a hook input, first checking event handlers, then tool handlers.
 func (m *CombinedMatcher) Handle(input *model.HookInput) model.HookOutput {
+	return chain(m.route, m.middlewares)(input)
+}
+
+// route hands input to the event matcher if it has a handler for the event
+// or the event is not about a tool, and to the tool matcher otherwise.
+func (m *CombinedMatcher) route(input *model.HookInput) model.HookOutput {
 	if input == nil {
 		return model.Allow()
 	}
 
 	// Check if there's a specific event handler
-	if handler, ok := m.eventMatcher.handlers[input.HookEventName]; ok {
-		return handler(input)
+	if _, ok := m.eventMatcher.handlers[input.HookEventName]; ok {
+		return m.eventMatcher.Handle(input)
 	}
 
 	// For tool-related events, use the tool matcher
@@ -398,9 +553,5 @@ func (m *CombinedMatcher) Handle(input *model.HookInput) model.HookOutput {
 	}
 
 	// Fall back to event matcher's default
-	if m.eventMatcher.Default != nil {
-		return m.eventMatcher.Default(input)
-	}
-
-	return model.Allow()
+	return m.eventMatcher.Handle(input)
 }
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/ngicks/crabswarm/hook/model"
@@ -330,3 +331,90 @@ func TestMatcherBuilder(t *testing.T) {
 		t.Errorf("Expected filepath, got %s", routed)
 	}
 }
+
+func TestMatcherToolRouting(t *testing.T) {
+	route := func(name string, routed *string) ToolHandler {
+		return func(input *model.HookInput, toolInput any) model.HookOutput {
+			*routed = name
+			return model.Allow()
+		}
+	}
+
+	var routed string
+	matcher := NewMatcher().
+		WithCommand(route("command", &routed)).
+		WithFilePath(route("filepath", &routed)).
+		WithMCP(route("mcp", &routed)).
+		OnToolMatch("Edit|Write", route("edit|write", &routed)).
+		OnToolMatch("mcp__github__.*", route("github", &routed)).
+		OnToolMatch("mcp__.*", route("any mcp", &routed)).
+		OnTool(model.ToolNameWrite, route("write", &routed))
+	OnInput(matcher,
+		func(in *model.BashInput) bool { return strings.HasPrefix(in.Command, "git ") },
+		func(input *model.HookInput, in *model.BashInput) model.HookOutput {
+			routed = "git: " + in.Command
+			return model.Allow()
+		})
+	OnInput(matcher, nil, func(input *model.HookInput, in *model.WriteInput) model.HookOutput {
+		if in.FilePath == "/etc/passwd" {
+			routed = "write passwd"
+		} else {
+			routed = "write input"
+		}
+		return model.Allow()
+	})
+
+	tests := []struct {
+		name      string
+		toolName  model.ToolName
+		toolInput string
+		want      string
+	}{
+		{name: "predicate before category", toolName: model.ToolNameBash, toolInput: `{"command":"git status"}`, want: "git: git status"},
+		{name: "predicate not matching", toolName: model.ToolNameBash, toolInput: `{"command":"ls"}`, want: "command"},
+		{name: "nil predicate before name", toolName: model.ToolNameWrite, toolInput: `{"file_path":"/etc/passwd","content":""}`, want: "write passwd"},
+		{name: "unparsable input skips predicates", toolName: model.ToolNameWrite, toolInput: `[]`, want: "write"},
+		{name: "pattern before category", toolName: model.ToolNameEdit, toolInput: `{"file_path":"/a","old_string":"a","new_string":"b"}`, want: "edit|write"},
+		{name: "category", toolName: model.ToolNameRead, toolInput: `{"file_path":"/a"}`, want: "filepath"},
+		{name: "first pattern wins", toolName: "mcp__github__create_issue", toolInput: `{}`, want: "github"},
+		{name: "later pattern", toolName: "mcp__gitlab__create_issue", toolInput: `{}`, want: "any mcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routed = ""
+			matcher.Handle(&model.HookInput{
+				HookEventName: model.HookEventPreToolUse,
+				ToolName:      tt.toolName,
+				ToolInput:     json.RawMessage(tt.toolInput),
+			})
+			if routed != tt.want {
+				t.Errorf("routed = %q, want %q", routed, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherToolRouting_NoTool(t *testing.T) {
+	called := false
+	matcher := NewMatcher().OnToolMatch("*", func(input *model.HookInput, toolInput any) model.HookOutput {
+		called = true
+		return model.Allow()
+	})
+
+	matcher.Handle(&model.HookInput{HookEventName: model.HookEventStop})
+	if called {
+		t.Error("tool matcher handled an input without a tool")
+	}
+}
+
+func TestMatcherOnToolMatch_InvalidPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("OnToolMatch() did not panic")
+		}
+	}()
+	NewMatcher().OnToolMatch("mcp__(", func(input *model.HookInput, toolInput any) model.HookOutput {
+		return model.Allow()
+	})
+}
@@ -0,0 +1,25 @@
+package sdk
+
+import (
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// Handler handles a hook input. It is what middleware wraps: the routing of
+// a Matcher, EventMatcher or CombinedMatcher, including the handler it ends
+// up calling.
+type Handler func(input *model.HookInput) model.HookOutput
+
+// Middleware wraps a Handler to add behavior around it, such as logging,
+// timing, panic recovery, pre-checks that answer without calling next, or
+// post-processing of the output.
+//
+// The input may be nil if the matcher's Handle was called with nil.
+type Middleware func(next Handler) Handler
+
+// chain wraps h with middlewares so that the first one is the outermost.
+func chain(h Handler, middlewares []Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
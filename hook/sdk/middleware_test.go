@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// tracing returns a middleware appending name to trace before and after next.
+func tracing(trace *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return func(input *model.HookInput) model.HookOutput {
+			*trace = append(*trace, name)
+			out := next(input)
+			*trace = append(*trace, "/"+name)
+			return out
+		}
+	}
+}
+
+func TestMatcherUse_Order(t *testing.T) {
+	var trace []string
+	m := NewMatcher().
+		WithCommand(func(input *model.HookInput, toolInput any) model.HookOutput {
+			trace = append(trace, "command")
+			return model.Allow()
+		}).
+		Use(tracing(&trace, "a"), tracing(&trace, "b"))
+	m.Use(tracing(&trace, "c"))
+
+	m.Handle(&model.HookInput{
+		HookEventName: model.HookEventPreToolUse,
+		ToolName:      model.ToolNameBash,
+		ToolInput:     json.RawMessage(`{"command":"ls"}`),
+	})
+
+	want := []string{"a", "b", "c", "command", "/c", "/b", "/a"}
+	if !slices.Equal(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestMatcherUse_ShortCircuit(t *testing.T) {
+	called := false
+	m := NewMatcherBuilder().
+		OnCommand(func(input *model.HookInput, toolInput any) model.HookOutput {
+			called = true
+			return model.Allow()
+		}).
+		Use(func(next Handler) Handler {
+			return func(input *model.HookInput) model.HookOutput {
+				if input != nil && input.ToolName == model.ToolNameBash {
+					return model.Deny(input.HookEventName, "blocked")
+				}
+				return next(input)
+			}
+		}).
+		Build()
+
+	out := m.Handle(&model.HookInput{HookEventName: model.HookEventPreToolUse, ToolName: model.ToolNameBash})
+	if called {
+		t.Error("handler called despite the middleware answering")
+	}
+	if out.HookSpecificOutput == nil || out.HookSpecificOutput.PermissionDecision != model.PermissionDeny {
+		t.Errorf("output = %+v, want deny", out)
+	}
+}
+
+func TestEventMatcherUse_PostProcess(t *testing.T) {
+	m := NewEventMatcher().
+		OnStop(func(input *model.HookInput) model.HookOutput {
+			return model.Allow()
+		}).
+		Use(func(next Handler) Handler {
+			return func(input *model.HookInput) model.HookOutput {
+				return next(input).WithSystemMessage("seen")
+			}
+		})
+
+	for _, input := range []*model.HookInput{
+		{HookEventName: model.HookEventStop},
+		{HookEventName: model.HookEventNotification},
+		nil,
+	} {
+		if out := m.Handle(input); out.SystemMessage != "seen" {
+			t.Errorf("Handle(%v).SystemMessage = %q, want %q", input, out.SystemMessage, "seen")
+		}
+	}
+}
+
+func TestCombinedMatcherUse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *model.HookInput
+		want  []string
+	}{
+		{
+			name:  "event handler",
+			input: &model.HookInput{HookEventName: model.HookEventSessionStart},
+			want:  []string{"combined", "events", "/events", "/combined"},
+		},
+		{
+			name:  "tool handler",
+			input: &model.HookInput{HookEventName: model.HookEventPreToolUse, ToolName: model.ToolNameBash},
+			want:  []string{"combined", "tools", "/tools", "/combined"},
+		},
+		{
+			name:  "event default",
+			input: &model.HookInput{HookEventName: model.HookEventStop},
+			want:  []string{"combined", "events", "/events", "/combined"},
+		},
+		{
+			name:  "nil input",
+			input: nil,
+			want:  []string{"combined", "/combined"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trace []string
+			cm := NewCombinedMatcher().Use(tracing(&trace, "combined"))
+			cm.Events().
+				OnSessionStart(func(input *model.HookInput) model.HookOutput { return model.Allow() }).
+				Use(tracing(&trace, "events"))
+			cm.Tools().Use(tracing(&trace, "tools"))
+
+			cm.Handle(tt.input)
+			if !slices.Equal(trace, tt.want) {
+				t.Errorf("trace = %v, want %v", trace, tt.want)
+			}
+		})
+	}
+}
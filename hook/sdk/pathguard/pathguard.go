@@ -0,0 +1,263 @@
+// Package pathguard classifies the paths targeted by Claude Code's file tools
+// (Read, Write, Edit, Glob, Grep, NotebookEdit) relative to the session's
+// workspace.
+//
+// Paths are resolved against the hook's working directory and symlinks are
+// followed, so a link inside the workspace pointing elsewhere is classified
+// by where it actually leads.
+package pathguard
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// Location classifies a resolved path relative to the workspace.
+type Location string
+
+const (
+	// LocationWorkspace is a path inside the workspace (the hook's cwd).
+	LocationWorkspace Location = "workspace"
+	// LocationAllowed is a path inside one of the allowlisted extra directories.
+	LocationAllowed Location = "allowed"
+	// LocationOutside is any other path.
+	LocationOutside Location = "outside"
+)
+
+// DefaultSensitive is the default list of sensitive path patterns.
+var DefaultSensitive = []string{".git", "~/.ssh", ".env*"}
+
+// Target is a classified file tool target.
+type Target struct {
+	// Field is the tool input field holding the path (e.g. "file_path").
+	Field string
+	// Path is the path as given in the tool input. Empty if the tool
+	// defaults to the working directory (Glob and Grep without "path").
+	Path string
+	// Resolved is the absolute path with symlinks followed.
+	Resolved string
+	// Location classifies Resolved.
+	Location Location
+	// Write is true if the tool modifies the target.
+	Write bool
+	// Sensitive is the sensitive pattern matched by Resolved, or "".
+	Sensitive string
+}
+
+// Guard classifies file tool targets.
+type Guard struct {
+	// AllowedDirs are extra directories treated as LocationAllowed.
+	AllowedDirs []string
+	// Sensitive lists sensitive path patterns. Patterns starting with "/"
+	// or "~/" match that directory and everything below it; other patterns
+	// are matched (with filepath.Match) against every path component.
+	Sensitive []string
+	// Home is used to expand "~/" patterns. Defaults to os.UserHomeDir.
+	Home string
+}
+
+// New creates a Guard with the given allowlisted directories and the
+// DefaultSensitive patterns.
+func New(allowedDirs ...string) *Guard {
+	home, _ := os.UserHomeDir()
+	return &Guard{
+		AllowedDirs: allowedDirs,
+		Sensitive:   append([]string(nil), DefaultSensitive...),
+		Home:        home,
+	}
+}
+
+// pathFields maps file tools to the tool input field holding their path.
+var pathFields = map[model.ToolName]string{
+	model.ToolNameRead:         "file_path",
+	model.ToolNameWrite:        "file_path",
+	model.ToolNameEdit:         "file_path",
+	model.ToolNameNotebookEdit: "notebook_path",
+	model.ToolNameGlob:         "path",
+	model.ToolNameGrep:         "path",
+}
+
+// IsWriteTool reports whether tool modifies the file it targets.
+func IsWriteTool(tool model.ToolName) bool {
+	switch tool {
+	case model.ToolNameWrite, model.ToolNameEdit, model.ToolNameNotebookEdit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Classify resolves and classifies the target of a file tool invocation.
+// It returns false if tool is not a file tool or toolInput does not contain
+// a usable path.
+func (g *Guard) Classify(tool model.ToolName, cwd string, toolInput json.RawMessage) (Target, bool) {
+	field, ok := pathFields[tool]
+	if !ok {
+		return Target{}, false
+	}
+
+	var fields map[string]any
+	if len(toolInput) > 0 {
+		if err := json.Unmarshal(toolInput, &fields); err != nil {
+			return Target{}, false
+		}
+	}
+	p, _ := fields[field].(string)
+	if p == "" && field != "path" {
+		return Target{}, false
+	}
+
+	target := Target{
+		Field: field,
+		Path:  p,
+		Write: IsWriteTool(tool),
+	}
+	target.Resolved = g.resolve(cwd, p)
+	target.Location = g.locate(cwd, target.Resolved)
+	target.Sensitive = g.sensitive(target.Resolved)
+	return target, true
+}
+
+// Escalate reports whether t should not be auto-approved: a write outside
+// the workspace and allowlisted directories, or any access to a sensitive path.
+func (t Target) Escalate() bool {
+	if t.Sensitive != "" {
+		return true
+	}
+	return t.Write && t.Location == LocationOutside
+}
+
+// Describe returns a short human-readable description of t,
+// e.g. "/repo/main.go (workspace)" or "/home/u/.ssh/id_rsa (outside, sensitive: ~/.ssh)".
+func (t Target) Describe() string {
+	desc := t.Resolved + " (" + string(t.Location)
+	if t.Sensitive != "" {
+		desc += ", sensitive: " + t.Sensitive
+	}
+	return desc + ")"
+}
+
+func (g *Guard) resolve(cwd, p string) string {
+	p = g.expandHome(p)
+	if !filepath.IsAbs(p) {
+		// Not filepath.Join: ".." must only be applied once symlinks
+		// before it are resolved.
+		p = cwd + string(filepath.Separator) + p
+	}
+	return evalSymlinks(p)
+}
+
+// maxSymlinks bounds the symlinks followed while resolving a path, like
+// the kernel's ELOOP limit.
+const maxSymlinks = 40
+
+// evalSymlinks resolves p one component at a time, the way the kernel
+// does: ".." is applied to the resolved path, and symlinks are followed even
+// if they dangle, since a Write through a dangling link creates its target.
+// Components that do not exist are kept as they are, so that paths of files
+// that do not exist yet (Write) are resolved too.
+func evalSymlinks(p string) string {
+	if !filepath.IsAbs(p) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return filepath.Clean(p)
+		}
+		p = wd + string(filepath.Separator) + p
+	}
+	vol := filepath.VolumeName(p)
+	root := vol + string(filepath.Separator)
+	resolved := root
+	rest := splitPath(p[len(vol):])
+	links := 0
+	for len(rest) > 0 {
+		name := rest[0]
+		rest = rest[1:]
+		switch name {
+		case ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, name)
+		info, err := os.Lstat(next)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 || links >= maxSymlinks {
+			resolved = next
+			continue
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			resolved = next
+			continue
+		}
+		links++
+		if filepath.IsAbs(target) {
+			vol = filepath.VolumeName(target)
+			resolved = vol + string(filepath.Separator)
+			target = target[len(vol):]
+		}
+		rest = append(splitPath(target), rest...)
+	}
+	return resolved
+}
+
+// splitPath returns the non-empty components of p.
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, func(r rune) bool { return os.IsPathSeparator(uint8(r)) })
+}
+
+func (g *Guard) locate(cwd, resolved string) Location {
+	if cwd != "" && within(evalSymlinks(cwd), resolved) {
+		return LocationWorkspace
+	}
+	for _, dir := range g.AllowedDirs {
+		if within(g.resolve(cwd, dir), resolved) {
+			return LocationAllowed
+		}
+	}
+	return LocationOutside
+}
+
+func (g *Guard) sensitive(resolved string) string {
+	for _, pattern := range g.Sensitive {
+		if strings.HasPrefix(pattern, "/") || strings.HasPrefix(pattern, "~/") {
+			dir := evalSymlinks(g.expandHome(pattern))
+			if within(dir, resolved) {
+				return pattern
+			}
+			continue
+		}
+		for _, elem := range strings.Split(resolved, string(filepath.Separator)) {
+			if ok, _ := filepath.Match(pattern, elem); ok {
+				return pattern
+			}
+		}
+	}
+	return ""
+}
+
+func (g *Guard) expandHome(p string) string {
+	if g.Home == "" {
+		return p
+	}
+	if p == "~" {
+		return g.Home
+	}
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(g.Home, p[2:])
+	}
+	return p
+}
+
+// within reports whether p is dir or inside dir.
+func within(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
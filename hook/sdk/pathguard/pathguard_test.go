@@ -0,0 +1,123 @@
+package pathguard
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// setup creates a workspace, an allowlisted directory, an outside directory
+// and a home directory, plus symlinks from the workspace to outside: "link"
+// to the outside directory and "dangling" to a file there that does not
+// exist yet.
+func setup(t *testing.T) (g *Guard, workspace, allowed, outside string) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	workspace = filepath.Join(root, "ws")
+	allowed = filepath.Join(root, "shared")
+	outside = filepath.Join(root, "outside")
+	home := filepath.Join(root, "home")
+	for _, dir := range []string{workspace, allowed, outside, filepath.Join(home, ".ssh")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "new.txt"), filepath.Join(workspace, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	g = New(allowed)
+	g.Home = home
+	return g, workspace, allowed, outside
+}
+
+func input(field, path string) json.RawMessage {
+	data, _ := json.Marshal(map[string]string{field: path})
+	return data
+}
+
+func TestGuard_Classify(t *testing.T) {
+	g, workspace, allowed, outside := setup(t)
+
+	tests := []struct {
+		name      string
+		tool      model.ToolName
+		input     json.RawMessage
+		resolved  string
+		location  Location
+		sensitive string
+		escalate  bool
+	}{
+		{"relative read", model.ToolNameRead, input("file_path", "main.go"), filepath.Join(workspace, "main.go"), LocationWorkspace, "", false},
+		{"absolute write", model.ToolNameWrite, input("file_path", filepath.Join(workspace, "a", "b.go")), filepath.Join(workspace, "a", "b.go"), LocationWorkspace, "", false},
+		{"dot-dot escape", model.ToolNameEdit, input("file_path", "../outside/x"), filepath.Join(outside, "x"), LocationOutside, "", true},
+		{"symlink escape", model.ToolNameWrite, input("file_path", "link/new.txt"), filepath.Join(outside, "new.txt"), LocationOutside, "", true},
+		{"dot-dot after symlink", model.ToolNameWrite, input("file_path", "link/../ws.txt"), filepath.Join(filepath.Dir(outside), "ws.txt"), LocationOutside, "", true},
+		{"dangling symlink", model.ToolNameWrite, input("file_path", "dangling"), filepath.Join(outside, "new.txt"), LocationOutside, "", true},
+		{"read outside", model.ToolNameRead, input("file_path", filepath.Join(outside, "x")), filepath.Join(outside, "x"), LocationOutside, "", false},
+		{"allowlisted", model.ToolNameWrite, input("file_path", filepath.Join(allowed, "x")), filepath.Join(allowed, "x"), LocationAllowed, "", false},
+		{"notebook", model.ToolNameNotebookEdit, input("notebook_path", "nb.ipynb"), filepath.Join(workspace, "nb.ipynb"), LocationWorkspace, "", false},
+		{"glob default path", model.ToolNameGlob, input("pattern", "*.go"), workspace, LocationWorkspace, "", false},
+		{"grep path", model.ToolNameGrep, input("path", "/"), "/", LocationOutside, "", false},
+		{"git dir", model.ToolNameWrite, input("file_path", ".git/hooks/pre-commit"), filepath.Join(workspace, ".git", "hooks", "pre-commit"), LocationWorkspace, ".git", true},
+		{"env file", model.ToolNameRead, input("file_path", ".env.local"), filepath.Join(workspace, ".env.local"), LocationWorkspace, ".env*", true},
+		{"ssh key", model.ToolNameRead, input("file_path", "~/.ssh/id_ed25519"), filepath.Join(g.Home, ".ssh", "id_ed25519"), LocationOutside, "~/.ssh", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, ok := g.Classify(tt.tool, workspace, tt.input)
+			if !ok {
+				t.Fatal("Classify returned false")
+			}
+			if target.Resolved != tt.resolved {
+				t.Errorf("Resolved = %q, want %q", target.Resolved, tt.resolved)
+			}
+			if target.Location != tt.location {
+				t.Errorf("Location = %q, want %q", target.Location, tt.location)
+			}
+			if target.Sensitive != tt.sensitive {
+				t.Errorf("Sensitive = %q, want %q", target.Sensitive, tt.sensitive)
+			}
+			if target.Escalate() != tt.escalate {
+				t.Errorf("Escalate = %v, want %v", target.Escalate(), tt.escalate)
+			}
+		})
+	}
+}
+
+func TestGuard_ClassifyNotApplicable(t *testing.T) {
+	g := New()
+	tests := []struct {
+		name  string
+		tool  model.ToolName
+		input string
+	}{
+		{"bash", model.ToolNameBash, `{"command":"ls"}`},
+		{"missing file_path", model.ToolNameWrite, `{"content":"x"}`},
+		{"invalid json", model.ToolNameRead, `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := g.Classify(tt.tool, "/tmp", json.RawMessage(tt.input)); ok {
+				t.Error("expected Classify to return false")
+			}
+		})
+	}
+}
+
+func TestTarget_Describe(t *testing.T) {
+	target := Target{Resolved: "/home/u/.ssh/id_rsa", Location: LocationOutside, Sensitive: "~/.ssh"}
+	if got, want := target.Describe(), "/home/u/.ssh/id_rsa (outside, sensitive: ~/.ssh)"; got != want {
+		t.Errorf("Describe = %q, want %q", got, want)
+	}
+}
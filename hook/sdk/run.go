@@ -0,0 +1,233 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// Exit codes of the Claude Code command hook contract.
+const (
+	// ExitOK means stdout holds the hook output.
+	ExitOK = 0
+	// ExitError is a non-blocking error. Stderr is shown to the user and
+	// execution continues.
+	ExitError = 1
+	// ExitBlock is a blocking error. Stderr is fed back to Claude and the
+	// action is blocked (a tool call for PreToolUse, stopping for Stop, ...).
+	ExitBlock = 2
+)
+
+// ErrTimeout is the cause of the handler context's cancellation when
+// Runner.Timeout elapses.
+var ErrTimeout = errors.New("hook timed out")
+
+// ContextHandler is like Handler, but receives a context and may fail.
+// Returning an error made by Block blocks the action with a reason fed to
+// Claude; any other error is reported to the user without blocking.
+type ContextHandler func(ctx context.Context, input *model.HookInput) (model.HookOutput, error)
+
+// BlockError is a blocking error: Runner writes Reason to stderr and exits
+// with ExitBlock.
+type BlockError struct {
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *BlockError) Error() string {
+	return e.Reason
+}
+
+// Block returns a BlockError with reason.
+func Block(reason string) error {
+	return &BlockError{Reason: reason}
+}
+
+// Runner runs a handler as a Claude Code command hook: it reads the hook
+// input JSON from Stdin, writes the output JSON to Stdout, and reports
+// errors on Stderr with the exit code Claude Code expects.
+type Runner struct {
+	// Stdin, Stdout and Stderr default to the process's.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Timeout bounds the handler; its context is canceled with ErrTimeout
+	// when it elapses. Zero means no limit. Keep it below the timeout of
+	// the hook in the settings, after which Claude Code kills the hook.
+	Timeout time.Duration
+
+	// OnTimeout decides the output if the handler has not returned when
+	// Timeout elapses. If nil, the hook fails with a non-blocking error.
+	OnTimeout Handler
+}
+
+// Run runs handler as a Claude Code command hook and exits the process
+// with the resulting exit code. Matchers can be passed by their Handle
+// method, e.g. sdk.Run(matcher.Handle).
+func Run(handler Handler) {
+	RunContext(func(_ context.Context, input *model.HookInput) (model.HookOutput, error) {
+		return handler(input), nil
+	})
+}
+
+// RunContext is like Run for a ContextHandler. The context is canceled when
+// the process receives SIGINT or SIGTERM.
+func RunContext(handler ContextHandler) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := (&Runner{}).Run(ctx, handler)
+	stop()
+	os.Exit(code)
+}
+
+// Run handles a single hook invocation and returns the exit code.
+func (r *Runner) Run(ctx context.Context, handler ContextHandler) int {
+	stdin, stdout, stderr := r.Stdin, r.Stdout, r.Stderr
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	var input model.HookInput
+	if err := json.NewDecoder(stdin).Decode(&input); err != nil {
+		fmt.Fprintf(stderr, "failed to decode hook input: %v\n", err)
+		return ExitError
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	inv := &invocation{stdout: stdout, cancel: cancel}
+	inv.setTimeout(r.Timeout)
+	defer inv.setTimeout(0)
+	ctx = context.WithValue(ctx, invocationKey{}, inv)
+
+	type result struct {
+		output model.HookOutput
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		// An unrecovered panic exits with status 2, which Claude Code would
+		// take for a blocking error.
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- result{err: fmt.Errorf("hook panicked: %v", rec)}
+			}
+		}()
+		output, err := handler(ctx, &input)
+		done <- result{output, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		// The handler may have returned just as well; its result wins.
+		select {
+		case res = <-done:
+		default:
+			cause := context.Cause(ctx)
+			if errors.Is(cause, ErrTimeout) && r.OnTimeout != nil && !inv.isAsync() {
+				res = result{output: r.OnTimeout(&input)}
+			} else {
+				res = result{err: cause}
+			}
+		}
+	}
+
+	var blockErr *BlockError
+	if errors.As(res.err, &blockErr) {
+		fmt.Fprintln(stderr, blockErr.Reason)
+		return ExitBlock
+	}
+	if res.err != nil {
+		fmt.Fprintln(stderr, res.err)
+		return ExitError
+	}
+	if err := inv.write(res.output); err != nil {
+		fmt.Fprintf(stderr, "failed to encode hook output: %v\n", err)
+		return ExitError
+	}
+	return ExitOK
+}
+
+// GoAsync tells Claude Code not to wait for the hook: it writes a
+// model.AsyncOutput to stdout right away, and the handler keeps running in
+// the background for up to timeout (zero means no limit), which replaces
+// Runner.Timeout. The output the handler returns is still written.
+//
+// GoAsync must be called with the context of a handler run by a Runner, at
+// most once.
+func GoAsync(ctx context.Context, timeout time.Duration) error {
+	inv, ok := ctx.Value(invocationKey{}).(*invocation)
+	if !ok {
+		return errors.New("GoAsync called outside of a Runner")
+	}
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if inv.async {
+		return errors.New("GoAsync called twice")
+	}
+	inv.async = true
+	inv.setTimeoutLocked(timeout)
+	return json.NewEncoder(inv.stdout).Encode(model.AsyncOutput{
+		Async:        true,
+		AsyncTimeout: int(timeout.Milliseconds()),
+	})
+}
+
+type invocationKey struct{}
+
+// invocation is the state of a Runner.Run call shared with GoAsync.
+type invocation struct {
+	mu     sync.Mutex
+	stdout io.Writer
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+	async  bool
+}
+
+// setTimeout cancels the handler context with ErrTimeout after d, replacing
+// any earlier timeout. Zero clears it.
+func (inv *invocation) setTimeout(d time.Duration) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.setTimeoutLocked(d)
+}
+
+func (inv *invocation) setTimeoutLocked(d time.Duration) {
+	if inv.timer != nil {
+		inv.timer.Stop()
+		inv.timer = nil
+	}
+	if d > 0 {
+		inv.timer = time.AfterFunc(d, func() { inv.cancel(ErrTimeout) })
+	}
+}
+
+func (inv *invocation) isAsync() bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return inv.async
+}
+
+// write writes the hook output to stdout.
+func (inv *invocation) write(output model.HookOutput) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return json.NewEncoder(inv.stdout).Encode(output)
+}
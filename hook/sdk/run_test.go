@@ -0,0 +1,199 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+const preToolUseInput = `{"session_id":"sess-1","hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"rm -rf /"}}`
+
+func runHook(t *testing.T, r *Runner, stdin string, handler ContextHandler) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	r.Stdin = strings.NewReader(stdin)
+	r.Stdout = &out
+	r.Stderr = &errOut
+	code = r.Run(context.Background(), handler)
+	return code, out.String(), errOut.String()
+}
+
+func TestRunner_Output(t *testing.T) {
+	matcher := NewMatcher().WithCommand(func(input *model.HookInput, toolInput any) model.HookOutput {
+		return model.Deny(input.HookEventName, "dangerous: "+toolInput.(*model.BashInput).Command)
+	})
+	handler := func(_ context.Context, input *model.HookInput) (model.HookOutput, error) {
+		return matcher.Handle(input), nil
+	}
+
+	code, stdout, stderr := runHook(t, &Runner{}, preToolUseInput, handler)
+	if code != ExitOK || stderr != "" {
+		t.Fatalf("code = %d, stderr = %q, want %d and no stderr", code, stderr, ExitOK)
+	}
+	var out model.HookOutput
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("stdout %q is not a hook output: %v", stdout, err)
+	}
+	if out.HookSpecificOutput.PermissionDecision != model.PermissionDeny ||
+		out.HookSpecificOutput.PermissionDecisionReason != "dangerous: rm -rf /" {
+		t.Errorf("output = %+v", out.HookSpecificOutput)
+	}
+}
+
+func TestRunner_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		handler ContextHandler
+		code    int
+		stderr  string
+	}{
+		{
+			name:  "block",
+			stdin: preToolUseInput,
+			handler: func(context.Context, *model.HookInput) (model.HookOutput, error) {
+				return model.HookOutput{}, Block("not on my watch")
+			},
+			code:   ExitBlock,
+			stderr: "not on my watch\n",
+		},
+		{
+			name:  "wrapped block",
+			stdin: preToolUseInput,
+			handler: func(context.Context, *model.HookInput) (model.HookOutput, error) {
+				return model.HookOutput{}, errors.Join(errors.New("policy"), Block("denied by policy"))
+			},
+			code:   ExitBlock,
+			stderr: "denied by policy\n",
+		},
+		{
+			name:  "error",
+			stdin: preToolUseInput,
+			handler: func(context.Context, *model.HookInput) (model.HookOutput, error) {
+				return model.HookOutput{}, errors.New("database unavailable")
+			},
+			code:   ExitError,
+			stderr: "database unavailable\n",
+		},
+		{
+			name:  "panic",
+			stdin: preToolUseInput,
+			handler: func(context.Context, *model.HookInput) (model.HookOutput, error) {
+				panic("boom")
+			},
+			code:   ExitError,
+			stderr: "hook panicked: boom\n",
+		},
+		{
+			name:  "invalid input",
+			stdin: `{"session_id":`,
+			handler: func(context.Context, *model.HookInput) (model.HookOutput, error) {
+				t.Error("handler called for invalid input")
+				return model.HookOutput{}, nil
+			},
+			code:   ExitError,
+			stderr: "failed to decode hook input: unexpected EOF\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runHook(t, &Runner{}, tt.stdin, tt.handler)
+			if code != tt.code {
+				t.Errorf("code = %d, want %d", code, tt.code)
+			}
+			if stdout != "" {
+				t.Errorf("stdout = %q, want none", stdout)
+			}
+			if stderr != tt.stderr {
+				t.Errorf("stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	blocking := func(ctx context.Context, _ *model.HookInput) (model.HookOutput, error) {
+		<-ctx.Done()
+		// Ignore the context to let the runner give up on the handler.
+		time.Sleep(time.Second)
+		return model.Allow(), nil
+	}
+
+	t.Run("no OnTimeout", func(t *testing.T) {
+		code, stdout, stderr := runHook(t, &Runner{Timeout: 10 * time.Millisecond}, preToolUseInput, blocking)
+		if code != ExitError || stdout != "" || stderr != ErrTimeout.Error()+"\n" {
+			t.Errorf("code = %d, stdout = %q, stderr = %q", code, stdout, stderr)
+		}
+	})
+
+	t.Run("OnTimeout", func(t *testing.T) {
+		r := &Runner{
+			Timeout: 10 * time.Millisecond,
+			OnTimeout: func(input *model.HookInput) model.HookOutput {
+				return model.Ask(input.HookEventName)
+			},
+		}
+		code, stdout, _ := runHook(t, r, preToolUseInput, blocking)
+		if code != ExitOK || !strings.Contains(stdout, `"permissionDecision":"ask"`) {
+			t.Errorf("code = %d, stdout = %q", code, stdout)
+		}
+	})
+
+	t.Run("handler observes cause", func(t *testing.T) {
+		causes := make(chan error, 1)
+		code, _, _ := runHook(t, &Runner{Timeout: 10 * time.Millisecond}, preToolUseInput,
+			func(ctx context.Context, _ *model.HookInput) (model.HookOutput, error) {
+				<-ctx.Done()
+				causes <- context.Cause(ctx)
+				return model.HookOutput{}, context.Cause(ctx)
+			})
+		if cause := <-causes; code != ExitError || !errors.Is(cause, ErrTimeout) {
+			t.Errorf("code = %d, cause = %v", code, cause)
+		}
+	})
+}
+
+func TestRunner_Async(t *testing.T) {
+	r := &Runner{Timeout: 10 * time.Millisecond}
+	code, stdout, stderr := runHook(t, r, `{"hook_event_name":"PostToolUse","tool_name":"Bash"}`,
+		func(ctx context.Context, _ *model.HookInput) (model.HookOutput, error) {
+			if err := GoAsync(ctx, time.Minute); err != nil {
+				return model.HookOutput{}, err
+			}
+			if err := GoAsync(ctx, time.Minute); err == nil {
+				t.Error("second GoAsync() error = nil, want error")
+			}
+			// Outlives Runner.Timeout, which GoAsync replaced.
+			time.Sleep(50 * time.Millisecond)
+			return model.AllowWithSystemMessage("done"), ctx.Err()
+		})
+	if code != ExitOK {
+		t.Fatalf("code = %d, stderr = %q, want %d", code, stderr, ExitOK)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stdout = %q, want 2 lines", stdout)
+	}
+	var async model.AsyncOutput
+	if err := json.Unmarshal([]byte(lines[0]), &async); err != nil || !async.Async || async.AsyncTimeout != 60000 {
+		t.Errorf("first line = %s, want an async output with a 60000ms timeout", lines[0])
+	}
+	var out model.HookOutput
+	if err := json.Unmarshal([]byte(lines[1]), &out); err != nil || out.SystemMessage != "done" {
+		t.Errorf("second line = %s, want the handler output", lines[1])
+	}
+}
+
+func TestGoAsync_OutsideRunner(t *testing.T) {
+	if err := GoAsync(context.Background(), 0); err == nil {
+		t.Error("GoAsync() error = nil, want error")
+	}
+}
@@ -0,0 +1,76 @@
+package sdk
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+// plainMatcher matches matcher strings that Claude Code compares literally:
+// a tool name or a '|' separated list of them.
+var plainMatcher = regexp.MustCompile(`^[a-zA-Z0-9_|]+$`)
+
+// ToolMatcher matches tool names using the syntax of the "matcher" field of
+// Claude Code hook settings:
+//   - "" and "*" match every tool.
+//   - A tool name, or a '|' separated list of names (e.g. "Edit|Write"),
+//     matches those names exactly.
+//   - Anything else is a regular expression searched for in the tool name
+//     (e.g. "mcp__github__.*", "Notebook.*").
+type ToolMatcher struct {
+	pattern string
+	all     bool
+	names   []model.ToolName
+	re      *regexp.Regexp
+}
+
+// ParseToolMatcher parses a Claude Code hook matcher.
+func ParseToolMatcher(pattern string) (*ToolMatcher, error) {
+	tm := &ToolMatcher{pattern: pattern}
+	switch {
+	case pattern == "" || pattern == "*":
+		tm.all = true
+	case plainMatcher.MatchString(pattern):
+		for name := range strings.SplitSeq(pattern, "|") {
+			if name != "" {
+				tm.names = append(tm.names, model.ToolName(name))
+			}
+		}
+	default:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tool matcher %q: %w", pattern, err)
+		}
+		tm.re = re
+	}
+	return tm, nil
+}
+
+// MustParseToolMatcher is like ParseToolMatcher but panics if pattern is invalid.
+func MustParseToolMatcher(pattern string) *ToolMatcher {
+	tm, err := ParseToolMatcher(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return tm
+}
+
+// Match reports whether name is matched.
+func (tm *ToolMatcher) Match(name model.ToolName) bool {
+	switch {
+	case tm.all:
+		return true
+	case tm.re != nil:
+		return tm.re.MatchString(string(name))
+	default:
+		return slices.Contains(tm.names, name)
+	}
+}
+
+// String returns the pattern the matcher was parsed from.
+func (tm *ToolMatcher) String() string {
+	return tm.pattern
+}
@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/ngicks/crabswarm/hook/model"
+)
+
+func TestToolMatcher(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []model.ToolName
+		noMatch []model.ToolName
+	}{
+		{pattern: "", match: []model.ToolName{"Bash", "mcp__github__create_issue"}},
+		{pattern: "*", match: []model.ToolName{"Bash", "Write"}},
+		{pattern: "Write", match: []model.ToolName{"Write"}, noMatch: []model.ToolName{"WriteFile", "NotebookWrite", "Edit"}},
+		{pattern: "Edit|Write", match: []model.ToolName{"Edit", "Write"}, noMatch: []model.ToolName{"MultiEdit", "Read"}},
+		{pattern: "Notebook.*", match: []model.ToolName{"NotebookEdit"}, noMatch: []model.ToolName{"Edit"}},
+		{
+			pattern: "mcp__github__.*",
+			match:   []model.ToolName{"mcp__github__create_issue"},
+			noMatch: []model.ToolName{"mcp__gitlab__create_issue", "Bash"},
+		},
+		{pattern: "^(Read|Glob)$", match: []model.ToolName{"Read", "Glob"}, noMatch: []model.ToolName{"Grep", "ReadMe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			tm, err := ParseToolMatcher(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.match {
+				if !tm.Match(name) {
+					t.Errorf("Match(%q) = false, want true", name)
+				}
+			}
+			for _, name := range tt.noMatch {
+				if tm.Match(name) {
+					t.Errorf("Match(%q) = true, want false", name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseToolMatcher_Invalid(t *testing.T) {
+	if _, err := ParseToolMatcher("mcp__(github"); err == nil {
+		t.Error("ParseToolMatcher() error = nil, want error")
+	}
+}
@@ -0,0 +1,173 @@
+// Package tmux is a thin wrapper around the tmux command line, covering what
+// crabswarm needs to run Claude Code instances in tmux windows and to find
+// them again from hook requests.
+package tmux
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Environment variables and user options used to tag swarm members.
+const (
+	// MemberEnv is set in the environment of a swarm member's pane to its
+	// member name. Hook clients forward it with their requests.
+	MemberEnv = "CRABSWARM_MEMBER"
+	// MemberOption is the tmux window option holding the member name.
+	MemberOption = "@crabswarm-member"
+	// WorktreeOption is the tmux window option holding the member's worktree.
+	WorktreeOption = "@crabswarm-worktree"
+)
+
+// ErrNoServer is returned when no tmux server is running on the socket.
+var ErrNoServer = errors.New("no tmux server running")
+
+// Client runs tmux commands against one tmux server.
+type Client struct {
+	// Bin is the tmux executable. Defaults to "tmux" looked up in PATH.
+	Bin string
+	// SocketName selects a named server socket (tmux -L).
+	SocketName string
+	// SocketPath selects a server socket by path (tmux -S). It takes
+	// precedence over SocketName.
+	SocketPath string
+}
+
+// Run runs tmux with args and returns its trimmed standard output.
+func (c *Client) Run(ctx context.Context, args ...string) (string, error) {
+	bin := c.Bin
+	if bin == "" {
+		bin = "tmux"
+	}
+	// -u keeps tmux from replacing the tabs separating -F format fields
+	// with "_" when the locale is not UTF-8.
+	global := []string{"-u"}
+	switch {
+	case c.SocketPath != "":
+		global = append(global, "-S", c.SocketPath)
+	case c.SocketName != "":
+		global = append(global, "-L", c.SocketName)
+	}
+
+	cmd := exec.CommandContext(ctx, bin, append(global, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if isNoServer(msg) {
+			return "", ErrNoServer
+		}
+		if msg == "" {
+			return "", fmt.Errorf("tmux %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("tmux %s: %s", args[0], msg)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+func isNoServer(msg string) bool {
+	return strings.HasPrefix(msg, "no server running") ||
+		strings.HasPrefix(msg, "error connecting to")
+}
+
+// HasSession reports whether the session exists.
+func (c *Client) HasSession(ctx context.Context, session string) (bool, error) {
+	_, err := c.Run(ctx, "has-session", "-t", "="+session)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrNoServer), strings.Contains(err.Error(), "can't find session"):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Pane describes a tmux pane as returned by ListPanes.
+type Pane struct {
+	ID          string
+	Session     string
+	WindowIndex string
+	WindowName  string
+	Dead        bool
+	// Options holds the values of the user options requested from ListPanes.
+	Options map[string]string
+}
+
+// ListPanes lists the panes of all sessions along with the values of the
+// given user options (e.g. MemberOption). It returns no panes if no server
+// is running.
+func (c *Client) ListPanes(ctx context.Context, options ...string) ([]Pane, error) {
+	fields := []string{"#{pane_id}", "#{session_name}", "#{window_index}", "#{window_name}", "#{pane_dead}"}
+	for _, o := range options {
+		fields = append(fields, "#{"+o+"}")
+	}
+	out, err := c.Run(ctx, "list-panes", "-a", "-F", strings.Join(fields, "\t"))
+	if errors.Is(err, ErrNoServer) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var panes []Pane
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		f := strings.Split(line, "\t")
+		if len(f) != 5+len(options) {
+			return nil, fmt.Errorf("unexpected list-panes output %q", line)
+		}
+		p := Pane{
+			ID:          f[0],
+			Session:     f[1],
+			WindowIndex: f[2],
+			WindowName:  f[3],
+			Dead:        f[4] == "1",
+			Options:     make(map[string]string, len(options)),
+		}
+		for i, o := range options {
+			p.Options[o] = f[5+i]
+		}
+		panes = append(panes, p)
+	}
+	return panes, nil
+}
+
+// ServerSocketPath returns the path of the socket the tmux server listens
+// on, as hook clients report it in $TMUX. It returns ErrNoServer if no
+// server is running.
+func (c *Client) ServerSocketPath(ctx context.Context) (string, error) {
+	return c.Run(ctx, "display-message", "-p", "#{socket_path}")
+}
+
+// PaneFromEnv returns the socket path and pane ID of the tmux pane the
+// current process runs in, read from $TMUX and $TMUX_PANE.
+func PaneFromEnv() (socketPath, paneID string, ok bool) {
+	paneID = os.Getenv("TMUX_PANE")
+	if paneID == "" {
+		return "", "", false
+	}
+	// $TMUX is "socket_path,server_pid,session_index".
+	socketPath, _, _ = strings.Cut(os.Getenv("TMUX"), ",")
+	return socketPath, paneID, true
+}
+
+// SendText types text into the pane target and presses Enter, as if the
+// user had typed it. Newlines in text are sent as spaces so that the text
+// is submitted once.
+func (c *Client) SendText(ctx context.Context, target, text string) error {
+	text = strings.ReplaceAll(text, "\n", " ")
+	if _, err := c.Run(ctx, "send-keys", "-t", target, "-l", text); err != nil {
+		return err
+	}
+	_, err := c.Run(ctx, "send-keys", "-t", target, "Enter")
+	return err
+}
@@ -0,0 +1,87 @@
+package tmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a Client on a private tmux server, skipping the
+// test if tmux is not installed.
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	c := &Client{SocketName: fmt.Sprintf("crabswarm-test-%d", time.Now().UnixNano())}
+	t.Cleanup(func() {
+		c.Run(context.Background(), "kill-server")
+	})
+	return c
+}
+
+func TestClient_NoServer(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	panes, err := c.ListPanes(ctx)
+	if err != nil || len(panes) != 0 {
+		t.Errorf("ListPanes = %v, %v, want no panes", panes, err)
+	}
+	if ok, err := c.HasSession(ctx, "x"); ok || err != nil {
+		t.Errorf("HasSession = %v, %v, want false", ok, err)
+	}
+	if _, err := c.ServerSocketPath(ctx); !errors.Is(err, ErrNoServer) {
+		t.Errorf("ServerSocketPath error = %v, want ErrNoServer", err)
+	}
+}
+
+func TestClient_ListPanesAndSendText(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+	out := filepath.Join(t.TempDir(), "out")
+
+	paneID, err := c.Run(ctx, "new-session", "-d", "-s", "test", "-P", "-F", "#{pane_id}", "head -n 1 > "+out+"; sleep 60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Run(ctx, "set-option", "-w", "-t", paneID, MemberOption, "m1"); err != nil {
+		t.Fatal(err)
+	}
+
+	socketPath, err := c.ServerSocketPath(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(socketPath) != c.SocketName {
+		t.Errorf("ServerSocketPath = %q, want a socket named %q", socketPath, c.SocketName)
+	}
+
+	panes, err := c.ListPanes(ctx, MemberOption)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(panes) != 1 || panes[0].ID != paneID || panes[0].Session != "test" || panes[0].Options[MemberOption] != "m1" {
+		t.Fatalf("panes = %+v", panes)
+	}
+
+	if err := c.SendText(ctx, paneID, "hello\nworld"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(out)
+		if string(data) == "hello world\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pane received %q, want %q", data, "hello world\n")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
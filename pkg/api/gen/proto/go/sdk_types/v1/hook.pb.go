@@ -495,15 +495,16 @@ func (x *PostToolUseFailureHookInput) GetIsInterrupt() bool {
 // NotificationHookInput represents input for the notification hook.
 // Copied from https://platform.claude.com/docs/en/agent-sdk/typescript#notification-hook-input
 type NotificationHookInput struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	TranscriptPath string                 `protobuf:"bytes,2,opt,name=transcript_path,json=transcriptPath,proto3" json:"transcript_path,omitempty"`
-	Cwd            string                 `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
-	PermissionMode *string                `protobuf:"bytes,4,opt,name=permission_mode,json=permissionMode,proto3,oneof" json:"permission_mode,omitempty"`
-	Message        string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
-	Title          *string                `protobuf:"bytes,6,opt,name=title,proto3,oneof" json:"title,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SessionId        string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TranscriptPath   string                 `protobuf:"bytes,2,opt,name=transcript_path,json=transcriptPath,proto3" json:"transcript_path,omitempty"`
+	Cwd              string                 `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	PermissionMode   *string                `protobuf:"bytes,4,opt,name=permission_mode,json=permissionMode,proto3,oneof" json:"permission_mode,omitempty"`
+	Message          string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Title            *string                `protobuf:"bytes,6,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	NotificationType string                 `protobuf:"bytes,7,opt,name=notification_type,json=notificationType,proto3" json:"notification_type,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *NotificationHookInput) Reset() {
@@ -578,6 +579,13 @@ func (x *NotificationHookInput) GetTitle() string {
 	return ""
 }
 
+func (x *NotificationHookInput) GetNotificationType() string {
+	if x != nil {
+		return x.NotificationType
+	}
+	return ""
+}
+
 // UserPromptSubmitHookInput represents input for the user-prompt-submit hook.
 // Copied from https://platform.claude.com/docs/en/agent-sdk/typescript#user-prompt-submit-hook-input
 type UserPromptSubmitHookInput struct {
@@ -979,14 +987,17 @@ func (x *SubagentStartHookInput) GetAgentType() string {
 // SubagentStopHookInput represents input for the subagent-stop hook.
 // Copied from https://platform.claude.com/docs/en/agent-sdk/typescript#subagent-stop-hook-input
 type SubagentStopHookInput struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	TranscriptPath string                 `protobuf:"bytes,2,opt,name=transcript_path,json=transcriptPath,proto3" json:"transcript_path,omitempty"`
-	Cwd            string                 `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
-	PermissionMode *string                `protobuf:"bytes,4,opt,name=permission_mode,json=permissionMode,proto3,oneof" json:"permission_mode,omitempty"`
-	StopHookActive bool                   `protobuf:"varint,5,opt,name=stop_hook_active,json=stopHookActive,proto3" json:"stop_hook_active,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SessionId           string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TranscriptPath      string                 `protobuf:"bytes,2,opt,name=transcript_path,json=transcriptPath,proto3" json:"transcript_path,omitempty"`
+	Cwd                 string                 `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	PermissionMode      *string                `protobuf:"bytes,4,opt,name=permission_mode,json=permissionMode,proto3,oneof" json:"permission_mode,omitempty"`
+	StopHookActive      bool                   `protobuf:"varint,5,opt,name=stop_hook_active,json=stopHookActive,proto3" json:"stop_hook_active,omitempty"`
+	AgentId             string                 `protobuf:"bytes,6,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	AgentType           string                 `protobuf:"bytes,7,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	AgentTranscriptPath string                 `protobuf:"bytes,8,opt,name=agent_transcript_path,json=agentTranscriptPath,proto3" json:"agent_transcript_path,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *SubagentStopHookInput) Reset() {
@@ -1054,6 +1065,27 @@ func (x *SubagentStopHookInput) GetStopHookActive() bool {
 	return false
 }
 
+func (x *SubagentStopHookInput) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SubagentStopHookInput) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *SubagentStopHookInput) GetAgentTranscriptPath() string {
+	if x != nil {
+		return x.AgentTranscriptPath
+	}
+	return ""
+}
+
 // PreCompactHookInput represents input for the pre-compact hook.
 // Copied from https://platform.claude.com/docs/en/agent-sdk/typescript#pre-compact-hook-input
 type PreCompactHookInput struct {
@@ -1531,6 +1563,7 @@ type PreToolUseHookSpecificOutput struct {
 	PermissionDecision       *string                `protobuf:"bytes,1,opt,name=permission_decision,json=permissionDecision,proto3,oneof" json:"permission_decision,omitempty"`
 	PermissionDecisionReason *string                `protobuf:"bytes,2,opt,name=permission_decision_reason,json=permissionDecisionReason,proto3,oneof" json:"permission_decision_reason,omitempty"`
 	UpdatedInput             *ToolInput             `protobuf:"bytes,3,opt,name=updated_input,json=updatedInput,proto3,oneof" json:"updated_input,omitempty"`
+	AdditionalContext        *string                `protobuf:"bytes,4,opt,name=additional_context,json=additionalContext,proto3,oneof" json:"additional_context,omitempty"`
 	unknownFields            protoimpl.UnknownFields
 	sizeCache                protoimpl.SizeCache
 }
@@ -1586,6 +1619,13 @@ func (x *PreToolUseHookSpecificOutput) GetUpdatedInput() *ToolInput {
 	return nil
 }
 
+func (x *PreToolUseHookSpecificOutput) GetAdditionalContext() string {
+	if x != nil && x.AdditionalContext != nil {
+		return *x.AdditionalContext
+	}
+	return ""
+}
+
 // UserPromptSubmitHookSpecificOutput represents hook-specific output for user-prompt-submit hooks.
 // Copied from https://platform.claude.com/docs/en/agent-sdk/typescript#user-prompt-submit-hook-specific-output
 type UserPromptSubmitHookSpecificOutput struct {
@@ -2056,7 +2096,7 @@ const file_sdk_types_v1_hook_proto_rawDesc = "" +
 	"\x05error\x18\a \x01(\tR\x05error\x12&\n" +
 	"\fis_interrupt\x18\b \x01(\bH\x01R\visInterrupt\x88\x01\x01B\x12\n" +
 	"\x10_permission_modeB\x0f\n" +
-	"\r_is_interrupt\"\xf2\x01\n" +
+	"\r_is_interrupt\"\x9f\x02\n" +
 	"\x15NotificationHookInput\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12'\n" +
@@ -2064,7 +2104,8 @@ const file_sdk_types_v1_hook_proto_rawDesc = "" +
 	"\x03cwd\x18\x03 \x01(\tR\x03cwd\x12,\n" +
 	"\x0fpermission_mode\x18\x04 \x01(\tH\x00R\x0epermissionMode\x88\x01\x01\x12\x18\n" +
 	"\amessage\x18\x05 \x01(\tR\amessage\x12\x19\n" +
-	"\x05title\x18\x06 \x01(\tH\x01R\x05title\x88\x01\x01B\x12\n" +
+	"\x05title\x18\x06 \x01(\tH\x01R\x05title\x88\x01\x01\x12+\n" +
+	"\x11notification_type\x18\a \x01(\tR\x10notificationTypeB\x12\n" +
 	"\x10_permission_modeB\b\n" +
 	"\x06_title\"\xcf\x01\n" +
 	"\x19UserPromptSubmitHookInput\x12\x1d\n" +
@@ -2108,14 +2149,18 @@ const file_sdk_types_v1_hook_proto_rawDesc = "" +
 	"\bagent_id\x18\x05 \x01(\tR\aagentId\x12\x1d\n" +
 	"\n" +
 	"agent_type\x18\x06 \x01(\tR\tagentTypeB\x12\n" +
-	"\x10_permission_mode\"\xdd\x01\n" +
+	"\x10_permission_mode\"\xcb\x02\n" +
 	"\x15SubagentStopHookInput\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12'\n" +
 	"\x0ftranscript_path\x18\x02 \x01(\tR\x0etranscriptPath\x12\x10\n" +
 	"\x03cwd\x18\x03 \x01(\tR\x03cwd\x12,\n" +
 	"\x0fpermission_mode\x18\x04 \x01(\tH\x00R\x0epermissionMode\x88\x01\x01\x12(\n" +
-	"\x10stop_hook_active\x18\x05 \x01(\bR\x0estopHookActiveB\x12\n" +
+	"\x10stop_hook_active\x18\x05 \x01(\bR\x0estopHookActive\x12\x19\n" +
+	"\bagent_id\x18\x06 \x01(\tR\aagentId\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\a \x01(\tR\tagentType\x122\n" +
+	"\x15agent_transcript_path\x18\b \x01(\tR\x13agentTranscriptPathB\x12\n" +
 	"\x10_permission_mode\"\xba\x02\n" +
 	"\x13PreCompactHookInput\x12\x1d\n" +
 	"\n" +
@@ -2158,14 +2203,16 @@ const file_sdk_types_v1_hook_proto_rawDesc = "" +
 	"\x05input\"Q\n" +
 	"\x13AsyncHookJSONOutput\x12(\n" +
 	"\rasync_timeout\x18\x01 \x01(\x05H\x00R\fasyncTimeout\x88\x01\x01B\x10\n" +
-	"\x0e_async_timeout\"\xa3\x02\n" +
+	"\x0e_async_timeout\"\xee\x02\n" +
 	"\x1cPreToolUseHookSpecificOutput\x124\n" +
 	"\x13permission_decision\x18\x01 \x01(\tH\x00R\x12permissionDecision\x88\x01\x01\x12A\n" +
 	"\x1apermission_decision_reason\x18\x02 \x01(\tH\x01R\x18permissionDecisionReason\x88\x01\x01\x12A\n" +
-	"\rupdated_input\x18\x03 \x01(\v2\x17.sdk_types.v1.ToolInputH\x02R\fupdatedInput\x88\x01\x01B\x16\n" +
+	"\rupdated_input\x18\x03 \x01(\v2\x17.sdk_types.v1.ToolInputH\x02R\fupdatedInput\x88\x01\x01\x122\n" +
+	"\x12additional_context\x18\x04 \x01(\tH\x03R\x11additionalContext\x88\x01\x01B\x16\n" +
 	"\x14_permission_decisionB\x1d\n" +
 	"\x1b_permission_decision_reasonB\x10\n" +
-	"\x0e_updated_input\"o\n" +
+	"\x0e_updated_inputB\x15\n" +
+	"\x13_additional_context\"o\n" +
 	"\"UserPromptSubmitHookSpecificOutput\x122\n" +
 	"\x12additional_context\x18\x01 \x01(\tH\x00R\x11additionalContext\x88\x01\x01B\x15\n" +
 	"\x13_additional_context\"k\n" +
@@ -255,6 +255,7 @@ type ToolInput struct {
 	//	*ToolInput_ListMcpResources
 	//	*ToolInput_ReadMcpResource
 	//	*ToolInput_McpTool
+	//	*ToolInput_Other
 	Input         isToolInput_Input `protobuf_oneof:"input"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -459,6 +460,15 @@ func (x *ToolInput) GetMcpTool() *structpb.Struct {
 	return nil
 }
 
+func (x *ToolInput) GetOther() *structpb.Struct {
+	if x != nil {
+		if x, ok := x.Input.(*ToolInput_Other); ok {
+			return x.Other
+		}
+	}
+	return nil
+}
+
 type isToolInput_Input interface {
 	isToolInput_Input()
 }
@@ -535,6 +545,12 @@ type ToolInput_McpTool struct {
 	McpTool *structpb.Struct `protobuf:"bytes,18,opt,name=mcp_tool,json=mcpTool,proto3,oneof"`
 }
 
+type ToolInput_Other struct {
+	// The input of any other tool, or of a tool above whose input has fields
+	// its message cannot hold.
+	Other *structpb.Struct `protobuf:"bytes,19,opt,name=other,proto3,oneof"`
+}
+
 func (*ToolInput_Agent) isToolInput_Input() {}
 
 func (*ToolInput_AskUserQuestion) isToolInput_Input() {}
@@ -571,6 +587,8 @@ func (*ToolInput_ReadMcpResource) isToolInput_Input() {}
 
 func (*ToolInput_McpTool) isToolInput_Input() {}
 
+func (*ToolInput_Other) isToolInput_Input() {}
+
 // AgentInput is the input for the Task tool.
 // Launches a new agent to handle complex, multi-step tasks autonomously.
 type AgentInput struct {
@@ -580,9 +598,17 @@ type AgentInput struct {
 	// The task for the agent to perform.
 	Prompt string `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
 	// The type of specialized agent to use for this task.
-	SubagentType  string `protobuf:"bytes,3,opt,name=subagent_type,json=subagentType,proto3" json:"subagent_type,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	SubagentType string `protobuf:"bytes,3,opt,name=subagent_type,json=subagentType,proto3" json:"subagent_type,omitempty"`
+	// Optional model override for this agent.
+	Model *string `protobuf:"bytes,4,opt,name=model,proto3,oneof" json:"model,omitempty"`
+	// Maximum number of agentic turns.
+	MaxTurns *int32 `protobuf:"varint,5,opt,name=max_turns,json=maxTurns,proto3,oneof" json:"max_turns,omitempty"`
+	// Optional agent ID to resume from.
+	Resume *string `protobuf:"bytes,6,opt,name=resume,proto3,oneof" json:"resume,omitempty"`
+	// Set to true to run this agent in the background.
+	RunInBackground *bool `protobuf:"varint,7,opt,name=run_in_background,json=runInBackground,proto3,oneof" json:"run_in_background,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *AgentInput) Reset() {
@@ -636,6 +662,34 @@ func (x *AgentInput) GetSubagentType() string {
 	return ""
 }
 
+func (x *AgentInput) GetModel() string {
+	if x != nil && x.Model != nil {
+		return *x.Model
+	}
+	return ""
+}
+
+func (x *AgentInput) GetMaxTurns() int32 {
+	if x != nil && x.MaxTurns != nil {
+		return *x.MaxTurns
+	}
+	return 0
+}
+
+func (x *AgentInput) GetResume() string {
+	if x != nil && x.Resume != nil {
+		return *x.Resume
+	}
+	return ""
+}
+
+func (x *AgentInput) GetRunInBackground() bool {
+	if x != nil && x.RunInBackground != nil {
+		return *x.RunInBackground
+	}
+	return false
+}
+
 // QuestionOption represents a single choice option within a question.
 type QuestionOption struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -831,8 +885,10 @@ type BashInput struct {
 	Description *string `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
 	// Set to true to run this command in the background.
 	RunInBackground *bool `protobuf:"varint,4,opt,name=run_in_background,json=runInBackground,proto3,oneof" json:"run_in_background,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Set to true to run this command without the sandbox.
+	DangerouslyDisableSandbox *bool `protobuf:"varint,5,opt,name=dangerously_disable_sandbox,json=dangerouslyDisableSandbox,proto3,oneof" json:"dangerously_disable_sandbox,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
 }
 
 func (x *BashInput) Reset() {
@@ -893,6 +949,13 @@ func (x *BashInput) GetRunInBackground() bool {
 	return false
 }
 
+func (x *BashInput) GetDangerouslyDisableSandbox() bool {
+	if x != nil && x.DangerouslyDisableSandbox != nil {
+		return *x.DangerouslyDisableSandbox
+	}
+	return false
+}
+
 // BashOutputInput retrieves output from a running or completed background bash shell.
 type BashOutputInput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1221,7 +1284,9 @@ type GrepInput struct {
 	// Limit output to first N lines/entries.
 	HeadLimit *int32 `protobuf:"varint,11,opt,name=head_limit,json=headLimit,proto3,oneof" json:"head_limit,omitempty"`
 	// Enable multiline mode.
-	Multiline     *bool `protobuf:"varint,12,opt,name=multiline,proto3,oneof" json:"multiline,omitempty"`
+	Multiline *bool `protobuf:"varint,12,opt,name=multiline,proto3,oneof" json:"multiline,omitempty"`
+	// Skip first N lines/entries.
+	Offset        *int32 `protobuf:"varint,13,opt,name=offset,proto3,oneof" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1340,6 +1405,13 @@ func (x *GrepInput) GetMultiline() bool {
 	return false
 }
 
+func (x *GrepInput) GetOffset() int32 {
+	if x != nil && x.Offset != nil {
+		return *x.Offset
+	}
+	return 0
+}
+
 // KillShellInput kills a running background bash shell by its ID.
 type KillShellInput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1697,18 +1769,75 @@ func (x *TodoWriteInput) GetTodos() []*TodoItem {
 	return nil
 }
 
+// AllowedPrompt is a prompt-based permission requested with a plan.
+type AllowedPrompt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The tool this prompt applies to.
+	Tool string `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	// Semantic description of the action.
+	Prompt        string `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AllowedPrompt) Reset() {
+	*x = AllowedPrompt{}
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AllowedPrompt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AllowedPrompt) ProtoMessage() {}
+
+func (x *AllowedPrompt) ProtoReflect() protoreflect.Message {
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AllowedPrompt.ProtoReflect.Descriptor instead.
+func (*AllowedPrompt) Descriptor() ([]byte, []int) {
+	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AllowedPrompt) GetTool() string {
+	if x != nil {
+		return x.Tool
+	}
+	return ""
+}
+
+func (x *AllowedPrompt) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
 // ExitPlanModeInput exits planning mode and prompts the user to approve the plan.
 type ExitPlanModeInput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The plan to run by the user for approval.
-	Plan          string `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Plan string `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	// Prompt-based permissions needed to implement the plan.
+	AllowedPrompts []*AllowedPrompt `protobuf:"bytes,2,rep,name=allowed_prompts,json=allowedPrompts,proto3" json:"allowed_prompts,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ExitPlanModeInput) Reset() {
 	*x = ExitPlanModeInput{}
-	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[18]
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1720,7 +1849,7 @@ func (x *ExitPlanModeInput) String() string {
 func (*ExitPlanModeInput) ProtoMessage() {}
 
 func (x *ExitPlanModeInput) ProtoReflect() protoreflect.Message {
-	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[18]
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1733,7 +1862,7 @@ func (x *ExitPlanModeInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExitPlanModeInput.ProtoReflect.Descriptor instead.
 func (*ExitPlanModeInput) Descriptor() ([]byte, []int) {
-	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{18}
+	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *ExitPlanModeInput) GetPlan() string {
@@ -1743,6 +1872,13 @@ func (x *ExitPlanModeInput) GetPlan() string {
 	return ""
 }
 
+func (x *ExitPlanModeInput) GetAllowedPrompts() []*AllowedPrompt {
+	if x != nil {
+		return x.AllowedPrompts
+	}
+	return nil
+}
+
 // ListMcpResourcesInput lists available MCP resources from connected servers.
 type ListMcpResourcesInput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1754,7 +1890,7 @@ type ListMcpResourcesInput struct {
 
 func (x *ListMcpResourcesInput) Reset() {
 	*x = ListMcpResourcesInput{}
-	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[19]
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1766,7 +1902,7 @@ func (x *ListMcpResourcesInput) String() string {
 func (*ListMcpResourcesInput) ProtoMessage() {}
 
 func (x *ListMcpResourcesInput) ProtoReflect() protoreflect.Message {
-	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[19]
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1779,7 +1915,7 @@ func (x *ListMcpResourcesInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListMcpResourcesInput.ProtoReflect.Descriptor instead.
 func (*ListMcpResourcesInput) Descriptor() ([]byte, []int) {
-	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{19}
+	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ListMcpResourcesInput) GetServer() string {
@@ -1802,7 +1938,7 @@ type ReadMcpResourceInput struct {
 
 func (x *ReadMcpResourceInput) Reset() {
 	*x = ReadMcpResourceInput{}
-	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[20]
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1814,7 +1950,7 @@ func (x *ReadMcpResourceInput) String() string {
 func (*ReadMcpResourceInput) ProtoMessage() {}
 
 func (x *ReadMcpResourceInput) ProtoReflect() protoreflect.Message {
-	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[20]
+	mi := &file_sdk_types_v1_tool_input_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1827,7 +1963,7 @@ func (x *ReadMcpResourceInput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReadMcpResourceInput.ProtoReflect.Descriptor instead.
 func (*ReadMcpResourceInput) Descriptor() ([]byte, []int) {
-	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{20}
+	return file_sdk_types_v1_tool_input_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *ReadMcpResourceInput) GetServer() string {
@@ -1848,7 +1984,7 @@ var File_sdk_types_v1_tool_input_proto protoreflect.FileDescriptor
 
 const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	"\n" +
-	"\x1dsdk_types/v1/tool_input.proto\x12\fsdk_types.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x85\t\n" +
+	"\x1dsdk_types/v1/tool_input.proto\x12\fsdk_types.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xb6\t\n" +
 	"\tToolInput\x120\n" +
 	"\x05agent\x18\x01 \x01(\v2\x18.sdk_types.v1.AgentInputH\x00R\x05agent\x12P\n" +
 	"\x11ask_user_question\x18\x02 \x01(\v2\".sdk_types.v1.AskUserQuestionInputH\x00R\x0faskUserQuestion\x12-\n" +
@@ -1873,13 +2009,23 @@ const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	"\x0eexit_plan_mode\x18\x0f \x01(\v2\x1f.sdk_types.v1.ExitPlanModeInputH\x00R\fexitPlanMode\x12S\n" +
 	"\x12list_mcp_resources\x18\x10 \x01(\v2#.sdk_types.v1.ListMcpResourcesInputH\x00R\x10listMcpResources\x12P\n" +
 	"\x11read_mcp_resource\x18\x11 \x01(\v2\".sdk_types.v1.ReadMcpResourceInputH\x00R\x0freadMcpResource\x124\n" +
-	"\bmcp_tool\x18\x12 \x01(\v2\x17.google.protobuf.StructH\x00R\amcpToolB\a\n" +
-	"\x05input\"k\n" +
+	"\bmcp_tool\x18\x12 \x01(\v2\x17.google.protobuf.StructH\x00R\amcpTool\x12/\n" +
+	"\x05other\x18\x13 \x01(\v2\x17.google.protobuf.StructH\x00R\x05otherB\a\n" +
+	"\x05input\"\xaf\x02\n" +
 	"\n" +
 	"AgentInput\x12 \n" +
 	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x16\n" +
 	"\x06prompt\x18\x02 \x01(\tR\x06prompt\x12#\n" +
-	"\rsubagent_type\x18\x03 \x01(\tR\fsubagentType\"H\n" +
+	"\rsubagent_type\x18\x03 \x01(\tR\fsubagentType\x12\x19\n" +
+	"\x05model\x18\x04 \x01(\tH\x00R\x05model\x88\x01\x01\x12 \n" +
+	"\tmax_turns\x18\x05 \x01(\x05H\x01R\bmaxTurns\x88\x01\x01\x12\x1b\n" +
+	"\x06resume\x18\x06 \x01(\tH\x02R\x06resume\x88\x01\x01\x12/\n" +
+	"\x11run_in_background\x18\a \x01(\bH\x03R\x0frunInBackground\x88\x01\x01B\b\n" +
+	"\x06_modelB\f\n" +
+	"\n" +
+	"_max_turnsB\t\n" +
+	"\a_resumeB\x14\n" +
+	"\x12_run_in_background\"H\n" +
 	"\x0eQuestionOption\x12\x14\n" +
 	"\x05label\x18\x01 \x01(\tR\x05label\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\"\x99\x01\n" +
@@ -1893,16 +2039,18 @@ const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	"\aanswers\x18\x02 \x03(\v2/.sdk_types.v1.AskUserQuestionInput.AnswersEntryR\aanswers\x1a:\n" +
 	"\fAnswersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xce\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb3\x02\n" +
 	"\tBashInput\x12\x18\n" +
 	"\acommand\x18\x01 \x01(\tR\acommand\x12\x1d\n" +
 	"\atimeout\x18\x02 \x01(\x05H\x00R\atimeout\x88\x01\x01\x12%\n" +
 	"\vdescription\x18\x03 \x01(\tH\x01R\vdescription\x88\x01\x01\x12/\n" +
-	"\x11run_in_background\x18\x04 \x01(\bH\x02R\x0frunInBackground\x88\x01\x01B\n" +
+	"\x11run_in_background\x18\x04 \x01(\bH\x02R\x0frunInBackground\x88\x01\x01\x12C\n" +
+	"\x1bdangerously_disable_sandbox\x18\x05 \x01(\bH\x03R\x19dangerouslyDisableSandbox\x88\x01\x01B\n" +
 	"\n" +
 	"\b_timeoutB\x0e\n" +
 	"\f_descriptionB\x14\n" +
-	"\x12_run_in_background\"R\n" +
+	"\x12_run_in_backgroundB\x1e\n" +
+	"\x1c_dangerously_disable_sandbox\"R\n" +
 	"\x0fBashOutputInput\x12\x17\n" +
 	"\abash_id\x18\x01 \x01(\tR\x06bashId\x12\x1b\n" +
 	"\x06filter\x18\x02 \x01(\tH\x00R\x06filter\x88\x01\x01B\t\n" +
@@ -1928,7 +2076,7 @@ const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	"\tGlobInput\x12\x18\n" +
 	"\apattern\x18\x01 \x01(\tR\apattern\x12\x17\n" +
 	"\x04path\x18\x02 \x01(\tH\x00R\x04path\x88\x01\x01B\a\n" +
-	"\x05_path\"\xe0\x04\n" +
+	"\x05_path\"\x88\x05\n" +
 	"\tGrepInput\x12\x18\n" +
 	"\apattern\x18\x01 \x01(\tR\apattern\x12\x17\n" +
 	"\x04path\x18\x02 \x01(\tH\x00R\x04path\x88\x01\x01\x12\x17\n" +
@@ -1944,7 +2092,9 @@ const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	" \x01(\x05H\aR\acontext\x88\x01\x01\x12\"\n" +
 	"\n" +
 	"head_limit\x18\v \x01(\x05H\bR\theadLimit\x88\x01\x01\x12!\n" +
-	"\tmultiline\x18\f \x01(\bH\tR\tmultiline\x88\x01\x01B\a\n" +
+	"\tmultiline\x18\f \x01(\bH\tR\tmultiline\x88\x01\x01\x12\x1b\n" +
+	"\x06offset\x18\r \x01(\x05H\n" +
+	"R\x06offset\x88\x01\x01B\a\n" +
 	"\x05_pathB\a\n" +
 	"\x05_globB\a\n" +
 	"\x05_typeB\x13\n" +
@@ -1956,7 +2106,8 @@ const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	"\b_contextB\r\n" +
 	"\v_head_limitB\f\n" +
 	"\n" +
-	"_multiline\"+\n" +
+	"_multilineB\t\n" +
+	"\a_offset\"+\n" +
 	"\x0eKillShellInput\x12\x19\n" +
 	"\bshell_id\x18\x01 \x01(\tR\ashellId\"\xfb\x01\n" +
 	"\x11NotebookEditInput\x12#\n" +
@@ -1981,9 +2132,13 @@ const file_sdk_types_v1_tool_input_proto_rawDesc = "" +
 	"\vactive_form\x18\x03 \x01(\tR\n" +
 	"activeForm\">\n" +
 	"\x0eTodoWriteInput\x12,\n" +
-	"\x05todos\x18\x01 \x03(\v2\x16.sdk_types.v1.TodoItemR\x05todos\"'\n" +
+	"\x05todos\x18\x01 \x03(\v2\x16.sdk_types.v1.TodoItemR\x05todos\";\n" +
+	"\rAllowedPrompt\x12\x12\n" +
+	"\x04tool\x18\x01 \x01(\tR\x04tool\x12\x16\n" +
+	"\x06prompt\x18\x02 \x01(\tR\x06prompt\"m\n" +
 	"\x11ExitPlanModeInput\x12\x12\n" +
-	"\x04plan\x18\x01 \x01(\tR\x04plan\"?\n" +
+	"\x04plan\x18\x01 \x01(\tR\x04plan\x12D\n" +
+	"\x0fallowed_prompts\x18\x02 \x03(\v2\x1b.sdk_types.v1.AllowedPromptR\x0eallowedPrompts\"?\n" +
 	"\x15ListMcpResourcesInput\x12\x1b\n" +
 	"\x06server\x18\x01 \x01(\tH\x00R\x06server\x88\x01\x01B\t\n" +
 	"\a_server\"@\n" +
@@ -2025,7 +2180,7 @@ func file_sdk_types_v1_tool_input_proto_rawDescGZIP() []byte {
 }
 
 var file_sdk_types_v1_tool_input_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_sdk_types_v1_tool_input_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_sdk_types_v1_tool_input_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_sdk_types_v1_tool_input_proto_goTypes = []any{
 	(GrepOutputMode)(0),           // 0: sdk_types.v1.GrepOutputMode
 	(NotebookCellType)(0),         // 1: sdk_types.v1.NotebookCellType
@@ -2049,11 +2204,12 @@ var file_sdk_types_v1_tool_input_proto_goTypes = []any{
 	(*WebSearchInput)(nil),        // 19: sdk_types.v1.WebSearchInput
 	(*TodoItem)(nil),              // 20: sdk_types.v1.TodoItem
 	(*TodoWriteInput)(nil),        // 21: sdk_types.v1.TodoWriteInput
-	(*ExitPlanModeInput)(nil),     // 22: sdk_types.v1.ExitPlanModeInput
-	(*ListMcpResourcesInput)(nil), // 23: sdk_types.v1.ListMcpResourcesInput
-	(*ReadMcpResourceInput)(nil),  // 24: sdk_types.v1.ReadMcpResourceInput
-	nil,                           // 25: sdk_types.v1.AskUserQuestionInput.AnswersEntry
-	(*structpb.Struct)(nil),       // 26: google.protobuf.Struct
+	(*AllowedPrompt)(nil),         // 22: sdk_types.v1.AllowedPrompt
+	(*ExitPlanModeInput)(nil),     // 23: sdk_types.v1.ExitPlanModeInput
+	(*ListMcpResourcesInput)(nil), // 24: sdk_types.v1.ListMcpResourcesInput
+	(*ReadMcpResourceInput)(nil),  // 25: sdk_types.v1.ReadMcpResourceInput
+	nil,                           // 26: sdk_types.v1.AskUserQuestionInput.AnswersEntry
+	(*structpb.Struct)(nil),       // 27: google.protobuf.Struct
 }
 var file_sdk_types_v1_tool_input_proto_depIdxs = []int32{
 	5,  // 0: sdk_types.v1.ToolInput.agent:type_name -> sdk_types.v1.AgentInput
@@ -2070,23 +2226,25 @@ var file_sdk_types_v1_tool_input_proto_depIdxs = []int32{
 	18, // 11: sdk_types.v1.ToolInput.web_fetch:type_name -> sdk_types.v1.WebFetchInput
 	19, // 12: sdk_types.v1.ToolInput.web_search:type_name -> sdk_types.v1.WebSearchInput
 	21, // 13: sdk_types.v1.ToolInput.todo_write:type_name -> sdk_types.v1.TodoWriteInput
-	22, // 14: sdk_types.v1.ToolInput.exit_plan_mode:type_name -> sdk_types.v1.ExitPlanModeInput
-	23, // 15: sdk_types.v1.ToolInput.list_mcp_resources:type_name -> sdk_types.v1.ListMcpResourcesInput
-	24, // 16: sdk_types.v1.ToolInput.read_mcp_resource:type_name -> sdk_types.v1.ReadMcpResourceInput
-	26, // 17: sdk_types.v1.ToolInput.mcp_tool:type_name -> google.protobuf.Struct
-	6,  // 18: sdk_types.v1.Question.options:type_name -> sdk_types.v1.QuestionOption
-	7,  // 19: sdk_types.v1.AskUserQuestionInput.questions:type_name -> sdk_types.v1.Question
-	25, // 20: sdk_types.v1.AskUserQuestionInput.answers:type_name -> sdk_types.v1.AskUserQuestionInput.AnswersEntry
-	0,  // 21: sdk_types.v1.GrepInput.output_mode:type_name -> sdk_types.v1.GrepOutputMode
-	1,  // 22: sdk_types.v1.NotebookEditInput.cell_type:type_name -> sdk_types.v1.NotebookCellType
-	2,  // 23: sdk_types.v1.NotebookEditInput.edit_mode:type_name -> sdk_types.v1.NotebookEditMode
-	3,  // 24: sdk_types.v1.TodoItem.status:type_name -> sdk_types.v1.TodoStatus
-	20, // 25: sdk_types.v1.TodoWriteInput.todos:type_name -> sdk_types.v1.TodoItem
-	26, // [26:26] is the sub-list for method output_type
-	26, // [26:26] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
+	23, // 14: sdk_types.v1.ToolInput.exit_plan_mode:type_name -> sdk_types.v1.ExitPlanModeInput
+	24, // 15: sdk_types.v1.ToolInput.list_mcp_resources:type_name -> sdk_types.v1.ListMcpResourcesInput
+	25, // 16: sdk_types.v1.ToolInput.read_mcp_resource:type_name -> sdk_types.v1.ReadMcpResourceInput
+	27, // 17: sdk_types.v1.ToolInput.mcp_tool:type_name -> google.protobuf.Struct
+	27, // 18: sdk_types.v1.ToolInput.other:type_name -> google.protobuf.Struct
+	6,  // 19: sdk_types.v1.Question.options:type_name -> sdk_types.v1.QuestionOption
+	7,  // 20: sdk_types.v1.AskUserQuestionInput.questions:type_name -> sdk_types.v1.Question
+	26, // 21: sdk_types.v1.AskUserQuestionInput.answers:type_name -> sdk_types.v1.AskUserQuestionInput.AnswersEntry
+	0,  // 22: sdk_types.v1.GrepInput.output_mode:type_name -> sdk_types.v1.GrepOutputMode
+	1,  // 23: sdk_types.v1.NotebookEditInput.cell_type:type_name -> sdk_types.v1.NotebookCellType
+	2,  // 24: sdk_types.v1.NotebookEditInput.edit_mode:type_name -> sdk_types.v1.NotebookEditMode
+	3,  // 25: sdk_types.v1.TodoItem.status:type_name -> sdk_types.v1.TodoStatus
+	20, // 26: sdk_types.v1.TodoWriteInput.todos:type_name -> sdk_types.v1.TodoItem
+	22, // 27: sdk_types.v1.ExitPlanModeInput.allowed_prompts:type_name -> sdk_types.v1.AllowedPrompt
+	28, // [28:28] is the sub-list for method output_type
+	28, // [28:28] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
 }
 
 func init() { file_sdk_types_v1_tool_input_proto_init() }
@@ -2113,7 +2271,9 @@ func file_sdk_types_v1_tool_input_proto_init() {
 		(*ToolInput_ListMcpResources)(nil),
 		(*ToolInput_ReadMcpResource)(nil),
 		(*ToolInput_McpTool)(nil),
+		(*ToolInput_Other)(nil),
 	}
+	file_sdk_types_v1_tool_input_proto_msgTypes[1].OneofWrappers = []any{}
 	file_sdk_types_v1_tool_input_proto_msgTypes[5].OneofWrappers = []any{}
 	file_sdk_types_v1_tool_input_proto_msgTypes[6].OneofWrappers = []any{}
 	file_sdk_types_v1_tool_input_proto_msgTypes[7].OneofWrappers = []any{}
@@ -2121,14 +2281,14 @@ func file_sdk_types_v1_tool_input_proto_init() {
 	file_sdk_types_v1_tool_input_proto_msgTypes[10].OneofWrappers = []any{}
 	file_sdk_types_v1_tool_input_proto_msgTypes[11].OneofWrappers = []any{}
 	file_sdk_types_v1_tool_input_proto_msgTypes[13].OneofWrappers = []any{}
-	file_sdk_types_v1_tool_input_proto_msgTypes[19].OneofWrappers = []any{}
+	file_sdk_types_v1_tool_input_proto_msgTypes[20].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sdk_types_v1_tool_input_proto_rawDesc), len(file_sdk_types_v1_tool_input_proto_rawDesc)),
 			NumEnums:      4,
-			NumMessages:   22,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -151,6 +151,7 @@ type ToolOutput struct {
 	//	*ToolOutput_ListMcpResources
 	//	*ToolOutput_ReadMcpResource
 	//	*ToolOutput_McpTool
+	//	*ToolOutput_Other
 	Output        isToolOutput_Output `protobuf_oneof:"output"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -355,6 +356,15 @@ func (x *ToolOutput) GetMcpTool() *structpb.Struct {
 	return nil
 }
 
+func (x *ToolOutput) GetOther() *structpb.Struct {
+	if x != nil {
+		if x, ok := x.Output.(*ToolOutput_Other); ok {
+			return x.Other
+		}
+	}
+	return nil
+}
+
 type isToolOutput_Output interface {
 	isToolOutput_Output()
 }
@@ -431,6 +441,12 @@ type ToolOutput_McpTool struct {
 	McpTool *structpb.Struct `protobuf:"bytes,18,opt,name=mcp_tool,json=mcpTool,proto3,oneof"`
 }
 
+type ToolOutput_Other struct {
+	// The output of any other tool, or of a tool above whose output has
+	// fields its message cannot hold.
+	Other *structpb.Struct `protobuf:"bytes,19,opt,name=other,proto3,oneof"`
+}
+
 func (*ToolOutput_Task) isToolOutput_Output() {}
 
 func (*ToolOutput_AskUserQuestion) isToolOutput_Output() {}
@@ -467,6 +483,8 @@ func (*ToolOutput_ReadMcpResource) isToolOutput_Output() {}
 
 func (*ToolOutput_McpTool) isToolOutput_Output() {}
 
+func (*ToolOutput_Other) isToolOutput_Output() {}
+
 // UsageInfo contains token usage statistics.
 type UsageInfo struct {
 	state                    protoimpl.MessageState `protogen:"open.v1"`
@@ -2725,7 +2743,7 @@ var File_sdk_types_v1_tool_output_proto protoreflect.FileDescriptor
 
 const file_sdk_types_v1_tool_output_proto_rawDesc = "" +
 	"\n" +
-	"\x1esdk_types/v1/tool_output.proto\x12\fsdk_types.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1dsdk_types/v1/tool_input.proto\"\xef\b\n" +
+	"\x1esdk_types/v1/tool_output.proto\x12\fsdk_types.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1dsdk_types/v1/tool_input.proto\"\xa0\t\n" +
 	"\n" +
 	"ToolOutput\x12.\n" +
 	"\x04task\x18\x01 \x01(\v2\x18.sdk_types.v1.TaskOutputH\x00R\x04task\x12Q\n" +
@@ -2749,7 +2767,8 @@ const file_sdk_types_v1_tool_output_proto_rawDesc = "" +
 	"\x0eexit_plan_mode\x18\x0f \x01(\v2 .sdk_types.v1.ExitPlanModeOutputH\x00R\fexitPlanMode\x12T\n" +
 	"\x12list_mcp_resources\x18\x10 \x01(\v2$.sdk_types.v1.ListMcpResourcesOutputH\x00R\x10listMcpResources\x12Q\n" +
 	"\x11read_mcp_resource\x18\x11 \x01(\v2#.sdk_types.v1.ReadMcpResourceOutputH\x00R\x0freadMcpResource\x124\n" +
-	"\bmcp_tool\x18\x12 \x01(\v2\x17.google.protobuf.StructH\x00R\amcpToolB\b\n" +
+	"\bmcp_tool\x18\x12 \x01(\v2\x17.google.protobuf.StructH\x00R\amcpTool\x12/\n" +
+	"\x05other\x18\x13 \x01(\v2\x17.google.protobuf.StructH\x00R\x05otherB\b\n" +
 	"\x06output\"\x8f\x02\n" +
 	"\tUsageInfo\x12!\n" +
 	"\finput_tokens\x18\x01 \x01(\x05R\vinputTokens\x12#\n" +
@@ -3021,35 +3040,36 @@ var file_sdk_types_v1_tool_output_proto_depIdxs = []int32{
 	34, // 15: sdk_types.v1.ToolOutput.list_mcp_resources:type_name -> sdk_types.v1.ListMcpResourcesOutput
 	36, // 16: sdk_types.v1.ToolOutput.read_mcp_resource:type_name -> sdk_types.v1.ReadMcpResourceOutput
 	38, // 17: sdk_types.v1.ToolOutput.mcp_tool:type_name -> google.protobuf.Struct
-	3,  // 18: sdk_types.v1.TaskOutput.usage:type_name -> sdk_types.v1.UsageInfo
-	39, // 19: sdk_types.v1.AskUserQuestionOutput.questions:type_name -> sdk_types.v1.Question
-	37, // 20: sdk_types.v1.AskUserQuestionOutput.answers:type_name -> sdk_types.v1.AskUserQuestionOutput.AnswersEntry
-	0,  // 21: sdk_types.v1.BashOutputToolOutput.status:type_name -> sdk_types.v1.BashOutputStatus
-	11, // 22: sdk_types.v1.PDFPage.images:type_name -> sdk_types.v1.PDFPageImage
-	12, // 23: sdk_types.v1.PDFFileOutput.pages:type_name -> sdk_types.v1.PDFPage
-	40, // 24: sdk_types.v1.NotebookCell.outputs:type_name -> google.protobuf.Value
-	14, // 25: sdk_types.v1.NotebookFileOutput.cells:type_name -> sdk_types.v1.NotebookCell
-	38, // 26: sdk_types.v1.NotebookFileOutput.metadata:type_name -> google.protobuf.Struct
-	9,  // 27: sdk_types.v1.ReadOutput.text_file:type_name -> sdk_types.v1.TextFileOutput
-	10, // 28: sdk_types.v1.ReadOutput.image_file:type_name -> sdk_types.v1.ImageFileOutput
-	13, // 29: sdk_types.v1.ReadOutput.pdf_file:type_name -> sdk_types.v1.PDFFileOutput
-	15, // 30: sdk_types.v1.ReadOutput.notebook_file:type_name -> sdk_types.v1.NotebookFileOutput
-	19, // 31: sdk_types.v1.GrepContentOutput.matches:type_name -> sdk_types.v1.GrepMatch
-	22, // 32: sdk_types.v1.GrepCountOutput.counts:type_name -> sdk_types.v1.GrepFileCount
-	20, // 33: sdk_types.v1.GrepOutput.content:type_name -> sdk_types.v1.GrepContentOutput
-	21, // 34: sdk_types.v1.GrepOutput.files:type_name -> sdk_types.v1.GrepFilesOutput
-	23, // 35: sdk_types.v1.GrepOutput.count:type_name -> sdk_types.v1.GrepCountOutput
-	1,  // 36: sdk_types.v1.NotebookEditOutput.edit_type:type_name -> sdk_types.v1.NotebookEditType
-	38, // 37: sdk_types.v1.WebSearchResult.metadata:type_name -> google.protobuf.Struct
-	28, // 38: sdk_types.v1.WebSearchOutput.results:type_name -> sdk_types.v1.WebSearchResult
-	30, // 39: sdk_types.v1.TodoWriteOutput.stats:type_name -> sdk_types.v1.TodoStats
-	33, // 40: sdk_types.v1.ListMcpResourcesOutput.resources:type_name -> sdk_types.v1.McpResource
-	35, // 41: sdk_types.v1.ReadMcpResourceOutput.contents:type_name -> sdk_types.v1.McpResourceContent
-	42, // [42:42] is the sub-list for method output_type
-	42, // [42:42] is the sub-list for method input_type
-	42, // [42:42] is the sub-list for extension type_name
-	42, // [42:42] is the sub-list for extension extendee
-	0,  // [0:42] is the sub-list for field type_name
+	38, // 18: sdk_types.v1.ToolOutput.other:type_name -> google.protobuf.Struct
+	3,  // 19: sdk_types.v1.TaskOutput.usage:type_name -> sdk_types.v1.UsageInfo
+	39, // 20: sdk_types.v1.AskUserQuestionOutput.questions:type_name -> sdk_types.v1.Question
+	37, // 21: sdk_types.v1.AskUserQuestionOutput.answers:type_name -> sdk_types.v1.AskUserQuestionOutput.AnswersEntry
+	0,  // 22: sdk_types.v1.BashOutputToolOutput.status:type_name -> sdk_types.v1.BashOutputStatus
+	11, // 23: sdk_types.v1.PDFPage.images:type_name -> sdk_types.v1.PDFPageImage
+	12, // 24: sdk_types.v1.PDFFileOutput.pages:type_name -> sdk_types.v1.PDFPage
+	40, // 25: sdk_types.v1.NotebookCell.outputs:type_name -> google.protobuf.Value
+	14, // 26: sdk_types.v1.NotebookFileOutput.cells:type_name -> sdk_types.v1.NotebookCell
+	38, // 27: sdk_types.v1.NotebookFileOutput.metadata:type_name -> google.protobuf.Struct
+	9,  // 28: sdk_types.v1.ReadOutput.text_file:type_name -> sdk_types.v1.TextFileOutput
+	10, // 29: sdk_types.v1.ReadOutput.image_file:type_name -> sdk_types.v1.ImageFileOutput
+	13, // 30: sdk_types.v1.ReadOutput.pdf_file:type_name -> sdk_types.v1.PDFFileOutput
+	15, // 31: sdk_types.v1.ReadOutput.notebook_file:type_name -> sdk_types.v1.NotebookFileOutput
+	19, // 32: sdk_types.v1.GrepContentOutput.matches:type_name -> sdk_types.v1.GrepMatch
+	22, // 33: sdk_types.v1.GrepCountOutput.counts:type_name -> sdk_types.v1.GrepFileCount
+	20, // 34: sdk_types.v1.GrepOutput.content:type_name -> sdk_types.v1.GrepContentOutput
+	21, // 35: sdk_types.v1.GrepOutput.files:type_name -> sdk_types.v1.GrepFilesOutput
+	23, // 36: sdk_types.v1.GrepOutput.count:type_name -> sdk_types.v1.GrepCountOutput
+	1,  // 37: sdk_types.v1.NotebookEditOutput.edit_type:type_name -> sdk_types.v1.NotebookEditType
+	38, // 38: sdk_types.v1.WebSearchResult.metadata:type_name -> google.protobuf.Struct
+	28, // 39: sdk_types.v1.WebSearchOutput.results:type_name -> sdk_types.v1.WebSearchResult
+	30, // 40: sdk_types.v1.TodoWriteOutput.stats:type_name -> sdk_types.v1.TodoStats
+	33, // 41: sdk_types.v1.ListMcpResourcesOutput.resources:type_name -> sdk_types.v1.McpResource
+	35, // 42: sdk_types.v1.ReadMcpResourceOutput.contents:type_name -> sdk_types.v1.McpResourceContent
+	43, // [43:43] is the sub-list for method output_type
+	43, // [43:43] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
 }
 
 func init() { file_sdk_types_v1_tool_output_proto_init() }
@@ -3077,6 +3097,7 @@ func file_sdk_types_v1_tool_output_proto_init() {
 		(*ToolOutput_ListMcpResources)(nil),
 		(*ToolOutput_ReadMcpResource)(nil),
 		(*ToolOutput_McpTool)(nil),
+		(*ToolOutput_Other)(nil),
 	}
 	file_sdk_types_v1_tool_output_proto_msgTypes[1].OneofWrappers = []any{}
 	file_sdk_types_v1_tool_output_proto_msgTypes[2].OneofWrappers = []any{}
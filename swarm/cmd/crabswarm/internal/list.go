@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List swarm members",
+	Args:    cobra.NoArgs,
+	RunE:    runList,
+}
+
+var killCmd = &cobra.Command{
+	Use:   "kill NAME...",
+	Short: "Stop swarm members and close their windows",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runKill,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd, killCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	members, err := newSwarm().List(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tWINDOW\tPANE\tSTATUS\tWORKTREE")
+	for _, m := range members {
+		status := "running"
+		if m.Dead {
+			status = "exited"
+		}
+		fmt.Fprintf(w, "%s\t%s:%s\t%s\t%s\t%s\n", m.Name, m.Session, m.Window, m.PaneID, status, m.Worktree)
+	}
+	return w.Flush()
+}
+
+func runKill(cmd *cobra.Command, args []string) error {
+	s := newSwarm()
+	for _, name := range args {
+		if err := s.Kill(cmd.Context(), name); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "killed %s\n", name)
+	}
+	return nil
+}
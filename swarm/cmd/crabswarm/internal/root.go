@@ -0,0 +1,35 @@
+// Package internal contains the cobra commands for crabswarm.
+package internal
+
+import (
+	"github.com/ngicks/crabswarm/internal/tmux"
+	"github.com/ngicks/crabswarm/swarm"
+	"github.com/spf13/cobra"
+)
+
+var tmuxSocket string
+
+// rootCmd is the root command.
+var rootCmd = &cobra.Command{
+	Use:   "crabswarm",
+	Short: "Run a swarm of Claude Code instances in tmux",
+	Long: `crabswarm runs Claude Code instances ("members") in tmux windows, each in
+its own worktree with crabhook configured as its permission hook, so their
+permission requests can be answered from 'crabhook serve'.
+
+Members are windows of the tmux session "crabswarm" by default. Attach to
+it with 'tmux attach -t crabswarm'.`,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&tmuxSocket, "tmux-socket", "L", "", "tmux server socket name (tmux -L)")
+}
+
+// Execute runs the root command.
+func Execute() error {
+	return rootCmd.Execute()
+}
+
+func newSwarm() *swarm.Swarm {
+	return swarm.New(&tmux.Client{SocketName: tmuxSocket})
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/ngicks/crabswarm/swarm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	spawnWorktree string
+	spawnSession  string
+	spawnClaude   string
+	spawnCrabhook string
+	spawnServer   string
+)
+
+var spawnCmd = &cobra.Command{
+	Use:   "spawn NAME [PROMPT] [-- CLAUDE_ARGS...]",
+	Short: "Start Claude Code for a task in a new tmux window",
+	Long: `Start Claude Code in a new tmux window named NAME, running in --worktree
+with crabhook registered as its PreToolUse hook. PROMPT, if given, is sent as
+the initial prompt; arguments after "--" are passed to Claude Code.
+
+The window is tagged with the member name, and CRABSWARM_MEMBER is set in
+its environment, so crabhook reports which member and pane a permission
+request comes from.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		n := len(args)
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			n = dash
+		}
+		if n < 1 || n > 2 {
+			return fmt.Errorf("expected NAME and an optional PROMPT, got %d argument(s)", n)
+		}
+		return nil
+	},
+	RunE: runSpawn,
+}
+
+func init() {
+	spawnCmd.Flags().StringVarP(&spawnWorktree, "worktree", "C", ".", "Directory Claude Code runs in")
+	spawnCmd.Flags().StringVar(&spawnSession, "session", swarm.DefaultSession, "tmux session to create the window in")
+	spawnCmd.Flags().StringVar(&spawnClaude, "claude", "claude", "Claude Code executable")
+	spawnCmd.Flags().StringVar(&spawnCrabhook, "crabhook", "crabhook", "crabhook executable run as the hook")
+	spawnCmd.Flags().StringVarP(&spawnServer, "server", "s", "", "Permission server address passed to crabhook (default: crabhook's default)")
+	rootCmd.AddCommand(spawnCmd)
+}
+
+func runSpawn(cmd *cobra.Command, args []string) error {
+	positional, claudeArgs := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		positional, claudeArgs = args[:dash], args[dash:]
+	}
+
+	hook := []string{spawnCrabhook}
+	if spawnServer != "" {
+		hook = append(hook, "--server", spawnServer)
+	}
+	hookCommand, err := swarm.QuoteCommand(hook)
+	if err != nil {
+		return err
+	}
+
+	opts := swarm.SpawnOptions{
+		Name:        positional[0],
+		Worktree:    spawnWorktree,
+		Session:     spawnSession,
+		Claude:      spawnClaude,
+		ClaudeArgs:  claudeArgs,
+		HookCommand: hookCommand,
+	}
+	if len(positional) > 1 {
+		opts.Prompt = positional[1]
+	}
+
+	m, err := newSwarm().Spawn(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "spawned %s in %s:%s (pane %s, %s)\n", m.Name, m.Session, m.Window, m.PaneID, m.Worktree)
+	return nil
+}
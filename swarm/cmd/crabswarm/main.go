@@ -0,0 +1,14 @@
+// Package main is the entry point for the crabswarm CLI.
+package main
+
+import (
+	"os"
+
+	"github.com/ngicks/crabswarm/swarm/cmd/crabswarm/internal"
+)
+
+func main() {
+	if err := internal.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,273 @@
+// Package swarm runs Claude Code instances ("members") in tmux windows, each
+// in its own worktree and with crabhook configured as its permission hook.
+//
+// Members live as windows of a tmux session (DefaultSession unless told
+// otherwise) and are tagged with the tmux.MemberOption window option and
+// the tmux.MemberEnv environment variable, so that hook requests coming
+// from a member can be mapped back to its pane.
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ngicks/crabswarm/internal/tmux"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// DefaultSession is the tmux session members are created in by default.
+const DefaultSession = "crabswarm"
+
+// hookTimeout is the hook timeout configured in Claude Code, in seconds.
+// Requests wait for a human, so it is generous.
+const hookTimeout = 300
+
+// eventHookTimeout is the hook timeout for events only reported to the
+// server, in seconds.
+const eventHookTimeout = 10
+
+// Member is a Claude Code instance running in a tmux window.
+type Member struct {
+	Name     string
+	Session  string
+	Window   string
+	PaneID   string
+	Worktree string
+	// Dead reports whether Claude Code has exited while the pane is kept.
+	Dead bool
+}
+
+// Swarm manages the members on one tmux server.
+type Swarm struct {
+	Tmux *tmux.Client
+}
+
+// New returns a Swarm using client.
+func New(client *tmux.Client) *Swarm {
+	return &Swarm{Tmux: client}
+}
+
+// SpawnOptions configures Spawn.
+type SpawnOptions struct {
+	// Name identifies the member. It is used as the window name and must be
+	// unique within the swarm.
+	Name string
+	// Worktree is the directory Claude Code runs in. Defaults to the current
+	// directory.
+	Worktree string
+	// Session is the tmux session to create the window in; it is created if
+	// it does not exist. Defaults to DefaultSession.
+	Session string
+	// Prompt is the optional initial prompt passed to Claude Code.
+	Prompt string
+	// Claude is the Claude Code executable. Defaults to "claude".
+	Claude string
+	// ClaudeArgs are extra arguments passed to Claude Code.
+	ClaudeArgs []string
+	// HookCommand is the command line Claude Code runs as its PreToolUse
+	// hook, e.g. "crabhook --server unix:///run/user/1000/crabhook.sock".
+	// Defaults to "crabhook".
+	HookCommand string
+}
+
+// Spawn starts Claude Code for a new member in its own tmux window.
+func (s *Swarm) Spawn(ctx context.Context, opts SpawnOptions) (Member, error) {
+	if opts.Name == "" {
+		return Member{}, fmt.Errorf("member name is required")
+	}
+	if opts.Session == "" {
+		opts.Session = DefaultSession
+	}
+	if opts.Claude == "" {
+		opts.Claude = "claude"
+	}
+	if opts.HookCommand == "" {
+		opts.HookCommand = "crabhook"
+	}
+	worktree, err := resolveWorktree(opts.Worktree)
+	if err != nil {
+		return Member{}, err
+	}
+
+	if _, ok, err := s.Find(ctx, opts.Name); err != nil {
+		return Member{}, err
+	} else if ok {
+		return Member{}, fmt.Errorf("member %q already exists", opts.Name)
+	}
+
+	command, err := claudeCommand(opts)
+	if err != nil {
+		return Member{}, err
+	}
+
+	exists, err := s.Tmux.HasSession(ctx, opts.Session)
+	if err != nil {
+		return Member{}, err
+	}
+	args := []string{"new-window", "-d", "-t", opts.Session + ":"}
+	if !exists {
+		args = []string{"new-session", "-d", "-s", opts.Session}
+	}
+	args = append(args,
+		"-n", opts.Name,
+		"-c", worktree,
+		"-e", tmux.MemberEnv+"="+opts.Name,
+		"-P", "-F", "#{pane_id}\t#{window_index}",
+		command,
+	)
+	out, err := s.Tmux.Run(ctx, args...)
+	if err != nil {
+		return Member{}, fmt.Errorf("failed to create window for %q: %w", opts.Name, err)
+	}
+	paneID, window, _ := strings.Cut(out, "\t")
+
+	for option, value := range map[string]string{
+		tmux.MemberOption:   opts.Name,
+		tmux.WorktreeOption: worktree,
+	} {
+		if _, err := s.Tmux.Run(ctx, "set-option", "-w", "-t", paneID, option, value); err != nil {
+			return Member{}, fmt.Errorf("failed to tag window of %q: %w", opts.Name, err)
+		}
+	}
+
+	return Member{
+		Name:     opts.Name,
+		Session:  opts.Session,
+		Window:   window,
+		PaneID:   paneID,
+		Worktree: worktree,
+	}, nil
+}
+
+func resolveWorktree(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve worktree: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("invalid worktree: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("worktree %q is not a directory", abs)
+	}
+	return abs, nil
+}
+
+// claudeCommand builds the shell command line starting Claude Code with
+// the hook configured through --settings.
+func claudeCommand(opts SpawnOptions) (string, error) {
+	settings, err := HookSettings(opts.HookCommand)
+	if err != nil {
+		return "", err
+	}
+	words := []string{opts.Claude, "--settings", settings}
+	words = append(words, opts.ClaudeArgs...)
+	if opts.Prompt != "" {
+		words = append(words, opts.Prompt)
+	}
+	return QuoteCommand(words)
+}
+
+// QuoteCommand joins words into a POSIX shell command line.
+func QuoteCommand(words []string) (string, error) {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		q, err := syntax.Quote(w, syntax.LangPOSIX)
+		if err != nil {
+			return "", fmt.Errorf("failed to quote %q: %w", w, err)
+		}
+		quoted[i] = q
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// HookSettings returns the Claude Code settings JSON registering command
+// as the hook for every tool, and for the Stop and Notification events so
+// that the permission server can see when a member waits for input.
+func HookSettings(command string) (string, error) {
+	type hook struct {
+		Type    string `json:"type"`
+		Command string `json:"command"`
+		Timeout int    `json:"timeout"`
+	}
+	type matcher struct {
+		Matcher string `json:"matcher,omitempty"`
+		Hooks   []hook `json:"hooks"`
+	}
+	settings := map[string]any{
+		"hooks": map[string][]matcher{
+			"PreToolUse": {{
+				Matcher: "*",
+				Hooks:   []hook{{Type: "command", Command: command, Timeout: hookTimeout}},
+			}},
+			"Stop":         {{Hooks: []hook{{Type: "command", Command: command, Timeout: eventHookTimeout}}}},
+			"Notification": {{Hooks: []hook{{Type: "command", Command: command, Timeout: eventHookTimeout}}}},
+		},
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode hook settings: %w", err)
+	}
+	return string(data), nil
+}
+
+// List returns all members, in tmux order.
+func (s *Swarm) List(ctx context.Context) ([]Member, error) {
+	panes, err := s.Tmux.ListPanes(ctx, tmux.MemberOption, tmux.WorktreeOption)
+	if err != nil {
+		return nil, err
+	}
+	var members []Member
+	for _, p := range panes {
+		name := p.Options[tmux.MemberOption]
+		if name == "" {
+			continue
+		}
+		members = append(members, Member{
+			Name:     name,
+			Session:  p.Session,
+			Window:   p.WindowIndex,
+			PaneID:   p.ID,
+			Worktree: p.Options[tmux.WorktreeOption],
+			Dead:     p.Dead,
+		})
+	}
+	return members, nil
+}
+
+// Find returns the member called name.
+func (s *Swarm) Find(ctx context.Context, name string) (Member, bool, error) {
+	members, err := s.List(ctx)
+	if err != nil {
+		return Member{}, false, err
+	}
+	for _, m := range members {
+		if m.Name == name {
+			return m, true, nil
+		}
+	}
+	return Member{}, false, nil
+}
+
+// Kill closes the window of the member called name, terminating Claude Code.
+func (s *Swarm) Kill(ctx context.Context, name string) error {
+	m, ok, err := s.Find(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("member %q not found", name)
+	}
+	if _, err := s.Tmux.Run(ctx, "kill-window", "-t", m.PaneID); err != nil {
+		return fmt.Errorf("failed to kill %q: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ngicks/crabswarm/internal/tmux"
+)
+
+// newTestSwarm returns a Swarm on a private tmux server, skipping the test
+// if tmux is not installed.
+func newTestSwarm(t *testing.T) *Swarm {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	client := &tmux.Client{SocketName: fmt.Sprintf("crabswarm-test-%d", time.Now().UnixNano())}
+	t.Cleanup(func() {
+		client.Run(context.Background(), "kill-server")
+	})
+	return New(client)
+}
+
+// fakeClaude writes a script standing in for Claude Code. It records its
+// arguments and member environment variable in dir and keeps running.
+func fakeClaude(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "claude")
+	script := fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' \"$@\" > %q\necho \"$%s\" > %q\nexec sleep 60\n",
+		filepath.Join(dir, "args"), tmux.MemberEnv, filepath.Join(dir, "member"))
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// waitFile waits for the file at path to be written.
+func waitFile(t *testing.T, path string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(path)
+		if err == nil && len(data) > 0 {
+			return string(data)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not written", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestSwarm_SpawnListKill(t *testing.T) {
+	s := newTestSwarm(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	claude := fakeClaude(t, dir)
+
+	members, err := s.List(ctx)
+	if err != nil || len(members) != 0 {
+		t.Fatalf("List without server = %v, %v", members, err)
+	}
+
+	m, err := s.Spawn(ctx, SpawnOptions{
+		Name:        "fix-bug",
+		Worktree:    dir,
+		Prompt:      "fix the 'bug'",
+		Claude:      claude,
+		HookCommand: "crabhook --server unix:///tmp/x.sock",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Session != DefaultSession || m.PaneID == "" || m.Worktree != dir {
+		t.Errorf("member = %+v", m)
+	}
+
+	args := strings.Split(strings.TrimSpace(waitFile(t, filepath.Join(dir, "args"))), "\n")
+	if len(args) != 3 || args[0] != "--settings" || args[2] != "fix the 'bug'" {
+		t.Fatalf("claude args = %q", args)
+	}
+	var settings struct {
+		Hooks map[string][]struct {
+			Hooks []struct{ Command string }
+		}
+	}
+	if err := json.Unmarshal([]byte(args[1]), &settings); err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range []string{"PreToolUse", "Stop", "Notification"} {
+		if got := settings.Hooks[event][0].Hooks[0].Command; got != "crabhook --server unix:///tmp/x.sock" {
+			t.Errorf("%s hook command = %q", event, got)
+		}
+	}
+	if got := strings.TrimSpace(waitFile(t, filepath.Join(dir, "member"))); got != "fix-bug" {
+		t.Errorf("%s = %q, want fix-bug", tmux.MemberEnv, got)
+	}
+
+	// A second member goes into the existing session.
+	if _, err := s.Spawn(ctx, SpawnOptions{Name: "docs", Worktree: dir, Claude: claude}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Spawn(ctx, SpawnOptions{Name: "docs", Worktree: dir, Claude: claude}); err == nil {
+		t.Error("expected error for duplicate member")
+	}
+
+	members, err = s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[0].Name != "fix-bug" || members[1].Name != "docs" {
+		t.Fatalf("members = %+v", members)
+	}
+	if members[0].PaneID != m.PaneID || members[0].Worktree != dir {
+		t.Errorf("listed member = %+v, want %+v", members[0], m)
+	}
+
+	if err := s.Kill(ctx, "fix-bug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Kill(ctx, "fix-bug"); err == nil {
+		t.Error("expected error killing a missing member")
+	}
+	if _, ok, _ := s.Find(ctx, "docs"); !ok {
+		t.Error("docs should still be running")
+	}
+}
+
+func TestSpawn_InvalidWorktree(t *testing.T) {
+	s := New(&tmux.Client{Bin: "/nonexistent/tmux"})
+	_, err := s.Spawn(context.Background(), SpawnOptions{Name: "x", Worktree: filepath.Join(t.TempDir(), "missing")})
+	if err == nil || !strings.Contains(err.Error(), "worktree") {
+		t.Errorf("err = %v, want invalid worktree", err)
+	}
+}